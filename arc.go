@@ -0,0 +1,143 @@
+package geom
+
+import (
+	"math"
+)
+
+// maxArcSegments limits the segments an arc is linearized into, however
+// small the tolerance.
+const maxArcSegments = 1 << 16
+
+// An arc is the part of a circle that runs from a through b to c. Angles
+// are in radians, counter-clockwise from the positive X axis.
+type arc struct {
+	a, b, c  Point
+	x, y, r  float64 // center and radius
+	start    float64 // angle of a
+	mid      float64 // signed angle from a to b, positive if counter-clockwise
+	sweep    float64 // signed angle from a to c
+	straight bool    // a, b and c are collinear, so the arc is a line
+}
+
+// angleFrom returns the counter-clockwise angle from start to angle, in
+// [0, 2π).
+func angleFrom(start, angle float64) float64 {
+	d := math.Mod(angle-start, 2*math.Pi)
+	if d < 0 {
+		d += 2 * math.Pi
+	}
+	return d
+}
+
+func newArc(a, b, c Point) arc {
+	ar := arc{a: a, b: b, c: c}
+	// Work relative to a, to keep precision with large coordinates.
+	bx, by := b[X]-a[X], b[Y]-a[Y]
+	cx, cy := c[X]-a[X], c[Y]-a[Y]
+
+	if cx == 0 && cy == 0 {
+		// A full circle, on which b is opposite a.
+		ar.x, ar.y = a[X]+bx/2, a[Y]+by/2
+		ar.r = math.Hypot(bx, by) / 2
+		ar.straight = ar.r == 0
+		ar.start = math.Atan2(-by, -bx)
+		ar.mid, ar.sweep = math.Pi, 2*math.Pi
+		return ar
+	}
+
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		ar.straight = true
+		return ar
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux, uy := (cy*b2-by*c2)/d, (bx*c2-cx*b2)/d
+	ar.x, ar.y = a[X]+ux, a[Y]+uy
+	ar.r = math.Hypot(ux, uy)
+	ar.start = math.Atan2(-uy, -ux)
+	ar.mid = angleFrom(ar.start, math.Atan2(by-uy, bx-ux))
+	ar.sweep = angleFrom(ar.start, math.Atan2(cy-uy, cx-ux))
+	if d < 0 {
+		// clockwise
+		ar.mid -= 2 * math.Pi
+		ar.sweep -= 2 * math.Pi
+	}
+	return ar
+}
+
+// contains returns whether the point of the circle at angle lies on the
+// arc.
+func (ar arc) contains(angle float64) bool {
+	d := angleFrom(ar.start, angle)
+	if ar.sweep >= 0 {
+		return d <= ar.sweep
+	}
+	return d == 0 || d-2*math.Pi >= ar.sweep
+}
+
+// bounds extends b by the arc's end points and by the points where it
+// reaches furthest along each axis.
+func (ar arc) bounds(b Bounds) Bounds {
+	b = b.ExtendPoint(ar.a).ExtendPoint(ar.c)
+	if ar.straight {
+		return b.ExtendPoint(ar.b)
+	}
+	extremes := [4]Point{
+		{ar.x + ar.r, ar.y},
+		{ar.x, ar.y + ar.r},
+		{ar.x - ar.r, ar.y},
+		{ar.x, ar.y - ar.r},
+	}
+	for i, p := range extremes {
+		if ar.contains(float64(i) * math.Pi / 2) {
+			b = b.ExtendPoint(p)
+		}
+	}
+	return b
+}
+
+// appendPoints appends points along the arc after a, ending with c, such
+// that no chord strays more than tolerance from the arc. Further
+// components are interpolated between a and b, then between b and c.
+func (ar arc) appendPoints(points []Point, tolerance float64) []Point {
+	if ar.straight {
+		return append(points, ar.b, ar.c)
+	}
+
+	v := 1 - tolerance/ar.r
+	if v < -1 {
+		v = -1
+	}
+	n := maxArcSegments
+	if step := 2 * math.Acos(v); step > 0 {
+		if s := math.Ceil(math.Abs(ar.sweep) / step); s < maxArcSegments {
+			n = int(s)
+		}
+	}
+	if n < 2 {
+		n = 2
+	}
+
+	dimension := len(ar.a)
+	if len(ar.b) < dimension {
+		dimension = len(ar.b)
+	}
+	if len(ar.c) < dimension {
+		dimension = len(ar.c)
+	}
+	for i := 1; i < n; i++ {
+		t := ar.sweep * float64(i) / float64(n)
+		p := make(Point, dimension)
+		p[X] = ar.x + ar.r*math.Cos(ar.start+t)
+		p[Y] = ar.y + ar.r*math.Sin(ar.start+t)
+		for j := 2; j < dimension; j++ {
+			if math.Abs(t) <= math.Abs(ar.mid) {
+				p[j] = ar.a[j] + t/ar.mid*(ar.b[j]-ar.a[j])
+			} else {
+				p[j] = ar.b[j] + (t-ar.mid)/(ar.sweep-ar.mid)*(ar.c[j]-ar.b[j])
+			}
+		}
+		points = append(points, p)
+	}
+	return append(points, ar.c)
+}
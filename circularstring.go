@@ -0,0 +1,34 @@
+package geom
+
+// A CircularString is a sequence of circular arcs, each given by its
+// start, a point along it and its end, with each arc starting where the
+// last one ended. It has an odd number of points, at least three unless it
+// is empty.
+type CircularString []Point
+
+func (circularString CircularString) Bounds(b Bounds) Bounds {
+	if b.IsZero() {
+		b = NewBounds()
+	}
+	i := 0
+	for ; i+2 < len(circularString); i += 2 {
+		b = newArc(circularString[i], circularString[i+1], circularString[i+2]).bounds(b)
+	}
+	return b.ExtendPoints(circularString[i:])
+}
+
+// Linearize returns a LineString that follows circularString with no
+// chord further than tolerance from the arc it replaces. The tolerance
+// should be positive: with zero, a negative tolerance or NaN, each arc is
+// divided into the maximum of 65536 segments.
+func (circularString CircularString) Linearize(tolerance float64) LineString {
+	if len(circularString) == 0 {
+		return LineString{}
+	}
+	lineString := LineString{circularString[0]}
+	i := 0
+	for ; i+2 < len(circularString); i += 2 {
+		lineString = newArc(circularString[i], circularString[i+1], circularString[i+2]).appendPoints(lineString, tolerance)
+	}
+	return append(lineString, circularString[i+1:]...)
+}
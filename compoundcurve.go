@@ -0,0 +1,48 @@
+package geom
+
+// A CompoundCurve is a sequence of LineStrings and CircularStrings, each
+// starting where the last one ended.
+type CompoundCurve []T
+
+func (compoundCurve CompoundCurve) Bounds(b Bounds) Bounds {
+	for _, t := range compoundCurve {
+		b = t.Bounds(b)
+	}
+
+	return b
+}
+
+// Linearize returns a LineString that follows compoundCurve, replacing
+// arcs as CircularString.Linearize does. Members other than LineStrings
+// and CircularStrings are skipped.
+func (compoundCurve CompoundCurve) Linearize(tolerance float64) LineString {
+	lineString := LineString{}
+	for _, t := range compoundCurve {
+		var points LineString
+		switch g := t.(type) {
+		case LineString:
+			points = g
+		case CircularString:
+			points = g.Linearize(tolerance)
+		}
+		if len(lineString) != 0 && len(points) != 0 && lineString[len(lineString)-1].Equal(points[0]) {
+			points = points[1:]
+		}
+		lineString = append(lineString, points...)
+	}
+	return lineString
+}
+
+// linearizeCurve returns the points of a LineString, CircularString or
+// CompoundCurve, linearized. It returns false for any other geometry.
+func linearizeCurve(t T, tolerance float64) (LineString, bool) {
+	switch g := t.(type) {
+	case LineString:
+		return g, true
+	case CircularString:
+		return g.Linearize(tolerance), true
+	case CompoundCurve:
+		return g.Linearize(tolerance), true
+	}
+	return nil, false
+}
@@ -0,0 +1,26 @@
+package geom
+
+// A CurvePolygon is a Polygon whose rings may be LineStrings,
+// CircularStrings or CompoundCurves. The first ring is the exterior.
+type CurvePolygon []T
+
+func (curvePolygon CurvePolygon) Bounds(b Bounds) Bounds {
+	for _, t := range curvePolygon {
+		b = t.Bounds(b)
+	}
+
+	return b
+}
+
+// Linearize returns a Polygon with each ring of curvePolygon linearized.
+// Rings other than LineStrings, CircularStrings and CompoundCurves are
+// skipped.
+func (curvePolygon CurvePolygon) Linearize(tolerance float64) Polygon {
+	polygon := make(Polygon, 0, len(curvePolygon))
+	for _, t := range curvePolygon {
+		if ring, ok := linearizeCurve(t, tolerance); ok {
+			polygon = append(polygon, Ring(ring))
+		}
+	}
+	return polygon
+}
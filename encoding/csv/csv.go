@@ -0,0 +1,147 @@
+// Package csv reads and writes features as comma-separated values, with
+// geometries held either as WKT in one column or as a pair of X and Y (or
+// longitude and latitude) columns.
+//
+// When reading, every other column becomes a property. A column whose
+// non-empty values are all plain decimal numbers decodes to float64, one
+// whose values are all true or false decodes to bool, and any other column
+// decodes to string. Values such as NaN, Inf, hexadecimal numbers and
+// integers with a leading zero, like ZIP codes, are not numbers. Empty
+// numbers and booleans decode to nil.
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Options names the geometry columns. If WKT is set, geometries are read
+// from and written to that column as WKT. Otherwise, if X and Y are set,
+// points are read from and written to those columns. If none is set,
+// reading looks for a column named wkt, geometry, geom, the_geom or
+// wkt_geom, then for a pair of columns named x and y, lon and lat, lng and
+// lat, long and lat or longitude and latitude, ignoring case; writing uses
+// a WKT column named wkt.
+type Options struct {
+	WKT string
+	X   string
+	Y   string
+	// Axes are the axes of WKT written, as for wkt.Encode.
+	Axes int
+	// Comma is the field delimiter. Zero means ','.
+	Comma rune
+}
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "csv: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedPropertiesError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedPropertiesError) Error() string {
+	return "csv: unsupported properties type: " + e.Type.String()
+}
+
+// MissingColumnError reports a geometry column named in Options that is
+// not in the header.
+type MissingColumnError struct {
+	Name string
+}
+
+func (e MissingColumnError) Error() string {
+	return fmt.Sprintf("csv: missing column %q", e.Name)
+}
+
+// DuplicateColumnError reports a property with the name of a geometry
+// column, ignoring case, which would make the output ambiguous.
+type DuplicateColumnError struct {
+	Name string
+}
+
+func (e DuplicateColumnError) Error() string {
+	return fmt.Sprintf("csv: property %q has the name of a geometry column", e.Name)
+}
+
+// FieldError reports a geometry value that cannot be decoded. Line is the
+// line of the input the value starts on, counting from 1.
+type FieldError struct {
+	Line   int
+	Column string
+	Value  string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("csv: invalid geometry %q in column %s on line %d", e.Value, e.Column, e.Line)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "csv: " + e.Msg
+}
+
+var (
+	wktNames = []string{"wkt", "geometry", "geom", "the_geom", "wkt_geom"}
+	xyNames  = [][2]string{
+		{"x", "y"},
+		{"lon", "lat"},
+		{"lng", "lat"},
+		{"long", "lat"},
+		{"longitude", "latitude"},
+	}
+)
+
+// index returns the index of the column with the given name, or -1. Names
+// from Options must match exactly; the names looked for otherwise match
+// regardless of case.
+func index(header []string, name string, fold bool) int {
+	for i, h := range header {
+		if h == name || fold && strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// columns returns the indexes of the WKT column, or else of the X and Y
+// columns, with -1 for those not used.
+func (o Options) columns(header []string) (int, int, int, error) {
+	switch {
+	case o.WKT != "":
+		if i := index(header, o.WKT, false); i >= 0 {
+			return i, -1, -1, nil
+		}
+		return -1, -1, -1, MissingColumnError{o.WKT}
+	case o.X != "" || o.Y != "":
+		x, y := index(header, o.X, false), index(header, o.Y, false)
+		if x < 0 {
+			return -1, -1, -1, MissingColumnError{o.X}
+		} else if y < 0 {
+			return -1, -1, -1, MissingColumnError{o.Y}
+		}
+		return -1, x, y, nil
+	}
+
+	for _, name := range wktNames {
+		if i := index(header, name, true); i >= 0 {
+			return i, -1, -1, nil
+		}
+	}
+	for _, names := range xyNames {
+		x, y := index(header, names[0], true), index(header, names[1], true)
+		if x >= 0 && y >= 0 {
+			return -1, x, y, nil
+		}
+	}
+	return -1, -1, -1, FormatError{"no WKT or X and Y columns"}
+}
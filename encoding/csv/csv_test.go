@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestCSV(t *testing.T) {
+	var testCases = []struct {
+		options Options
+		data    string
+		fc      geom.FeatureCollection
+	}{
+		{
+			Options{},
+			"WKT,name,pop,capital\n" +
+				"POINT(2.35 48.85),Paris,2.1e6,true\n" +
+				"\"LINESTRING(0 0,1 1)\",,,\n",
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{2.35, 48.85}, map[string]interface{}{"name": "Paris", "pop": 2.1e6, "capital": true}),
+				geom.NewFeature(geom.LineString{{0, 0}, {1, 1}}, map[string]interface{}{"name": "", "pop": nil, "capital": nil}),
+			}},
+		},
+		{
+			Options{Comma: ';'},
+			"\ufeffid;Longitude;Latitude;code\n" +
+				"1;-0.12;51.5;01\n" +
+				"2;;;x\n",
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{-0.12, 51.5}, map[string]interface{}{"id": 1.0, "code": "01"}),
+				geom.NewFeature(nil, map[string]interface{}{"id": 2.0, "code": "x"}),
+			}},
+		},
+		{
+			Options{X: "e", Y: "n"},
+			"e,n,x\n" +
+				"500000,4000000,1\n",
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{500000, 4000000}, map[string]interface{}{"x": 1.0}),
+			}},
+		},
+		{
+			Options{},
+			"wkt,zip,odd,n\n" +
+				"POINT(1 2),02134,NaN,-0.5\n" +
+				"POINT(3 4),10001,0x1p4,.25e+1\n" +
+				"POINT(5 6),0,Inf,0\n",
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"zip": "02134", "odd": "NaN", "n": -0.5}),
+				geom.NewFeature(geom.Point{3, 4}, map[string]interface{}{"zip": "10001", "odd": "0x1p4", "n": 2.5}),
+				geom.NewFeature(geom.Point{5, 6}, map[string]interface{}{"zip": "0", "odd": "Inf", "n": 0.0}),
+			}},
+		},
+		{
+			Options{WKT: "shape"},
+			"geometry,shape\n" +
+				"a,POINT Z (1 2 3)\n",
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYZ), map[string]interface{}{"geometry": "a"}),
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := Decode([]byte(tc.data), tc.options); err != nil || !reflect.DeepEqual(got, tc.fc) {
+			t.Errorf("Decode(%q) == %#v, %v, want %#v, nil", tc.data, got, err, tc.fc)
+		}
+	}
+}
+
+func TestCSVEncode(t *testing.T) {
+	fc := geom.FeatureCollection{Features: []geom.T{
+		geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"name": "a, b", "n": 3, "ok": false}),
+		geom.NewSRIDGeometry(geom.Point{0.5, 1e6}, 4326),
+		geom.NewFeature(nil, map[string]interface{}{"n": 1.5}),
+	}}
+
+	var testCases = []struct {
+		options Options
+		data    string
+	}{
+		{
+			Options{},
+			"wkt,n,name,ok\n" +
+				"POINT(1 2),3,\"a, b\",false\n" +
+				"POINT(0.5 1e+06),,,\n" +
+				",1.5,,\n",
+		},
+		{
+			Options{X: "lon", Y: "lat", Comma: '\t'},
+			"lon\tlat\tn\tname\tok\n" +
+				"1\t2\t3\ta, b\tfalse\n" +
+				"0.5\t1000000\t\t\t\n" +
+				"\t\t1.5\t\t\n",
+		},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(fc, tc.options)
+		if err != nil || string(data) != tc.data {
+			t.Errorf("Encode(fc, %#v) == %q, %v, want %q, nil", tc.options, data, err, tc.data)
+		}
+	}
+
+	data, err := Encode(fc, Options{WKT: "geom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.FeatureCollection{Features: []geom.T{
+		geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"name": "a, b", "n": 3.0, "ok": false}),
+		geom.NewFeature(geom.Point{0.5, 1e6}, map[string]interface{}{"name": "", "n": nil, "ok": nil}),
+		geom.NewFeature(nil, map[string]interface{}{"name": "", "n": 1.5, "ok": nil}),
+	}}
+	if got, err := Decode(data, Options{}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(%q) == %#v, %v, want %#v, nil", data, got, err, want)
+	}
+}
+
+func TestCSVError(t *testing.T) {
+	var testCases = []struct {
+		options Options
+		data    string
+		err     error
+	}{
+		{Options{}, "", FormatError{"missing header"}},
+		{Options{}, "a,b\n1,2\n", FormatError{"no WKT or X and Y columns"}},
+		{Options{WKT: "shape"}, "wkt\n", MissingColumnError{"shape"}},
+		{Options{X: "x", Y: "y"}, "x,Y\n", MissingColumnError{"y"}},
+		{Options{}, "wkt\nPOINT(1 2)\n\"POINT(\n1\"\n", FieldError{3, "wkt", "POINT(\n1"}},
+		{Options{}, "x,y\n1,2\n3,north\n", FieldError{3, "y", "north"}},
+	}
+	for _, tc := range testCases {
+		if _, err := Decode([]byte(tc.data), tc.options); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%q) == %#v, want %#v", tc.data, err, tc.err)
+		}
+	}
+
+	fc := geom.FeatureCollection{Features: []geom.T{geom.LineString{{1, 2}}}}
+	if _, err := Encode(fc, Options{X: "x", Y: "y"}); !reflect.DeepEqual(err, UnsupportedGeometryError{reflect.TypeOf(geom.LineString{})}) {
+		t.Errorf("Encode(LineString) to X and Y == %#v", err)
+	}
+	fc = geom.FeatureCollection{Features: []geom.T{geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"WKT": "a"})}}
+	if _, err := Encode(fc, Options{}); !reflect.DeepEqual(err, DuplicateColumnError{"WKT"}) {
+		t.Errorf("Encode(property WKT) == %#v", err)
+	}
+	if _, err := Encode(fc, Options{X: "x", Y: "y"}); err != nil {
+		t.Errorf("Encode(property WKT) to X and Y == %#v", err)
+	}
+	fc = geom.FeatureCollection{Features: []geom.T{geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"y": 1})}}
+	if _, err := Encode(fc, Options{X: "x", Y: "y"}); !reflect.DeepEqual(err, DuplicateColumnError{"y"}) {
+		t.Errorf("Encode(property y) to X and Y == %#v", err)
+	}
+	fc = geom.FeatureCollection{Features: []geom.T{geom.NewFeature(geom.Point{1, 2}, []string{})}}
+	if _, err := Encode(fc, Options{}); !reflect.DeepEqual(err, UnsupportedPropertiesError{reflect.TypeOf([]string{})}) {
+		t.Errorf("Encode with []string properties == %#v", err)
+	}
+}
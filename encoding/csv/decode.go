@@ -0,0 +1,193 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/encoding/wkt"
+)
+
+// Decode parses CSV data into a FeatureCollection of geom.Features, one per
+// row. The first row is the header. Rows with an empty geometry decode to
+// features with a nil geometry. A leading byte order mark is ignored.
+func Decode(data []byte, options Options) (geom.FeatureCollection, error) {
+	return Read(bytes.NewReader(data), options)
+}
+
+// Read parses CSV from r. See Decode.
+func Read(r io.Reader, options Options) (geom.FeatureCollection, error) {
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	cr := csv.NewReader(r)
+	if options.Comma != 0 {
+		cr.Comma = options.Comma
+	}
+	header, err := cr.Read()
+	if err == io.EOF {
+		return fc, FormatError{"missing header"}
+	} else if err != nil {
+		return fc, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	w, x, y, err := options.columns(header)
+	if err != nil {
+		return fc, err
+	}
+
+	geometries := []geom.T{}
+	rows := [][]string{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fc, err
+		}
+
+		var g geom.T
+		if w >= 0 {
+			g, err = decodeWKT(cr, header, row, w)
+		} else {
+			g, err = decodeXY(cr, header, row, x, y)
+		}
+		if err != nil {
+			return fc, err
+		}
+		geometries = append(geometries, g)
+		rows = append(rows, row)
+	}
+
+	decoders := make([]func(string) interface{}, len(header))
+	for i := range header {
+		if i != w && i != x && i != y {
+			decoders[i] = inferColumn(rows, i)
+		}
+	}
+	for i, row := range rows {
+		properties := make(map[string]interface{}, len(header))
+		for j, decode := range decoders {
+			if decode != nil {
+				properties[header[j]] = decode(row[j])
+			}
+		}
+		fc.Features = append(fc.Features, geom.NewFeature(geometries[i], properties))
+	}
+	return fc, nil
+}
+
+func decodeWKT(cr *csv.Reader, header, row []string, i int) (geom.T, error) {
+	if strings.TrimSpace(row[i]) == "" {
+		return nil, nil
+	}
+	g, err := wkt.Decode([]byte(row[i]))
+	if err != nil {
+		line, _ := cr.FieldPos(i)
+		return nil, FieldError{line, header[i], row[i]}
+	}
+	return g, nil
+}
+
+func decodeXY(cr *csv.Reader, header, row []string, x, y int) (geom.T, error) {
+	xs, ys := strings.TrimSpace(row[x]), strings.TrimSpace(row[y])
+	if xs == "" && ys == "" {
+		return nil, nil
+	}
+	point := make(geom.Point, 2)
+	for j, i := range []int{x, y} {
+		var err error
+		if point[j], err = strconv.ParseFloat(strings.TrimSpace(row[i]), 64); err != nil {
+			line, _ := cr.FieldPos(i)
+			return nil, FieldError{line, header[i], row[i]}
+		}
+	}
+	return point, nil
+}
+
+func isBool(s string) bool {
+	return strings.EqualFold(s, "true") || strings.EqualFold(s, "false")
+}
+
+// isNumber reports whether s is a plain decimal number, with an optional
+// sign, fraction and exponent. Unlike strconv.ParseFloat, it rejects NaN,
+// infinities, hexadecimal and underscores, and integer parts with a leading
+// zero such as ZIP codes, whose zero would be lost.
+func isNumber(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	start := i
+	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	digits := i - start
+	if digits > 1 && s[start] == '0' {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		fraction := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		digits += i - fraction
+	}
+	if digits == 0 {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		exponent := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == exponent {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// inferColumn returns the decoder for column i, chosen from its values.
+func inferColumn(rows [][]string, i int) func(string) interface{} {
+	numbers, bools, empty := true, true, true
+	for _, row := range rows {
+		s := strings.TrimSpace(row[i])
+		if s == "" {
+			continue
+		}
+		empty = false
+		numbers = numbers && isNumber(s)
+		bools = bools && isBool(s)
+	}
+
+	switch {
+	case empty:
+	case numbers:
+		return func(s string) interface{} {
+			v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil
+			}
+			return v
+		}
+	case bools:
+		return func(s string) interface{} {
+			if !isBool(strings.TrimSpace(s)) {
+				return nil
+			}
+			return strings.EqualFold(strings.TrimSpace(s), "true")
+		}
+	}
+	return func(s string) interface{} {
+		return s
+	}
+}
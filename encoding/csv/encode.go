@@ -0,0 +1,129 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/encoding/wkt"
+)
+
+// Encode writes fc as CSV, with a header of the geometry columns followed
+// by the property names in order. Features may be geom.Features, whose
+// properties must be a map[string]interface{}, or bare geometries. Only
+// points can be written to X and Y columns. A property named like a
+// geometry column is a DuplicateColumnError.
+func Encode(fc geom.FeatureCollection, options Options) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Write(&b, fc, options); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func Write(w io.Writer, fc geom.FeatureCollection, options Options) error {
+	geometries := make([]geom.T, len(fc.Features))
+	records := make([]map[string]interface{}, len(fc.Features))
+	names := map[string]bool{}
+	for i, t := range fc.Features {
+		f, ok := t.(geom.Feature)
+		if !ok {
+			geometries[i] = t
+			continue
+		}
+		geometries[i] = f.T
+		if f.Properties == nil {
+			continue
+		}
+		properties, ok := f.Properties.(map[string]interface{})
+		if !ok {
+			return UnsupportedPropertiesError{reflect.TypeOf(f.Properties)}
+		}
+		for name := range properties {
+			names[name] = true
+		}
+		records[i] = properties
+	}
+
+	header := []string{options.WKT}
+	xy := options.WKT == "" && (options.X != "" || options.Y != "")
+	if xy {
+		header = []string{options.X, options.Y}
+	} else if options.WKT == "" {
+		header = []string{wktNames[0]}
+	}
+	columns := len(header)
+	properties := make([]string, 0, len(names))
+	for name := range names {
+		for _, column := range header {
+			if strings.EqualFold(name, column) {
+				return DuplicateColumnError{name}
+			}
+		}
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+	header = append(header, properties...)
+
+	cw := csv.NewWriter(w)
+	if options.Comma != 0 {
+		cw.Comma = options.Comma
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, g := range geometries {
+		row := make([]string, len(header))
+		if s, ok := g.(geom.SRIDGeometry); ok {
+			g = s.T
+		}
+		if xy {
+			if g != nil {
+				point, ok := g.(geom.Point)
+				if !ok || len(point) < 2 {
+					return UnsupportedGeometryError{reflect.TypeOf(g)}
+				}
+				row[0] = strconv.FormatFloat(point[0], 'f', -1, 64)
+				row[1] = strconv.FormatFloat(point[1], 'f', -1, 64)
+			}
+		} else if g != nil {
+			data, err := wkt.Encode(g, options.Axes)
+			if err != nil {
+				return err
+			}
+			row[0] = string(data)
+		}
+		for j, name := range properties {
+			row[columns+j] = formatValue(records[i][name])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,498 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/foobaz/geom"
+)
+
+// Reader reads features from FlatGeobuf data, using the index, if there is
+// one, to read only the features in a bounding box.
+type Reader struct {
+	r              io.ReaderAt
+	name           string
+	envelope       []float64
+	geometryType   int
+	axes           uint32
+	columns        []column
+	count          int
+	nodeSize       int
+	srid           int
+	featuresOffset int64
+}
+
+// Decode reads every feature in data.
+func Decode(data []byte) (geom.FeatureCollection, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return geom.FeatureCollection{}, err
+	}
+	return r.ReadAll()
+}
+
+// readAt reads n bytes at off, allocating only as much as is actually
+// there, so that a corrupt size cannot cause a huge allocation. It returns
+// io.EOF if there is nothing at off.
+func readAt(r io.ReaderAt, off int64, n int64) ([]byte, error) {
+	var b bytes.Buffer
+	if m, err := b.ReadFrom(io.NewSectionReader(r, off, n)); err != nil {
+		return nil, err
+	} else if m == 0 && n != 0 {
+		return nil, io.EOF
+	} else if m != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b.Bytes(), nil
+}
+
+// NewReader reads the header of the FlatGeobuf data in r.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	prefix, err := readAt(r, 0, int64(len(magic))+4)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:3], magic[:3]) || !bytes.Equal(prefix[4:7], magic[4:7]) {
+		return nil, FormatError{"not a FlatGeobuf file"}
+	}
+	if prefix[3] != magic[3] {
+		return nil, FormatError{"unsupported version"}
+	}
+
+	size := int64(binary.LittleEndian.Uint32(prefix[len(magic):]))
+	data, err := readAt(r, int64(len(prefix)), size)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{buf: data}
+	h := d.root()
+	fr := &Reader{
+		r:            r,
+		name:         d.string(h, headerName),
+		envelope:     d.float64s(h, headerEnvelope),
+		geometryType: int(d.uint8Field(h, headerGeometryType, fgbUnknown)),
+		count:        int(d.uint64Field(h, headerFeaturesCount, 0)),
+		nodeSize:     int(d.uint16Field(h, headerIndexNodeSize, defaultIndexNodeSize)),
+	}
+	if d.uint8Field(h, headerHasZ, 0) != 0 {
+		fr.axes |= geom.Z
+	}
+	if d.uint8Field(h, headerHasM, 0) != 0 {
+		fr.axes |= geom.M
+	}
+	fr.columns = decodeColumns(d, h, headerColumns)
+	if crs := d.table(h, headerCRS); crs != 0 {
+		// a missing organization means EPSG
+		if org := d.string(crs, crsOrg); org == "" || strings.EqualFold(org, "EPSG") {
+			if pos := d.field(crs, crsCode); pos != 0 {
+				fr.srid = int(int32(d.uint32(pos)))
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if fr.count < 0 {
+		return nil, FormatError{"invalid feature count"}
+	}
+
+	fr.featuresOffset = int64(len(prefix)) + size
+	if fr.hasIndex() {
+		fr.featuresOffset += int64(indexSize(fr.count, fr.nodeSize))
+	}
+	return fr, nil
+}
+
+func decodeColumns(d *decoder, table, slot int) []column {
+	var columns []column
+	for _, c := range d.tables(table, slot) {
+		columns = append(columns, column{d.string(c, columnName), int(d.uint8Field(c, columnType, 0))})
+	}
+	return columns
+}
+
+func (fr *Reader) hasIndex() bool {
+	return fr.count != 0 && fr.nodeSize >= 2
+}
+
+// Name returns the dataset name.
+func (fr *Reader) Name() string {
+	return fr.name
+}
+
+// SRID returns the EPSG code of the coordinate reference system, or 0.
+func (fr *Reader) SRID() int {
+	return fr.srid
+}
+
+// Count returns the number of features, or 0 if the writer did not record
+// it.
+func (fr *Reader) Count() int {
+	return fr.count
+}
+
+// Axes returns the axes of the points.
+func (fr *Reader) Axes() uint32 {
+	return fr.axes
+}
+
+// Bounds returns the extent of the features recorded in the header. It is
+// zero if there is none.
+func (fr *Reader) Bounds() geom.Bounds {
+	if len(fr.envelope) < 4 {
+		return geom.Bounds{}
+	}
+	e := fr.envelope
+	return geom.Bounds{Min: geom.Point{e[0], e[1]}, Max: geom.Point{e[2], e[3]}}
+}
+
+// readFeature reads the feature at off, returning it and its size.
+func (fr *Reader) readFeature(off int64) (geom.Feature, int64, error) {
+	prefix, err := readAt(fr.r, off, 4)
+	if err != nil {
+		return geom.Feature{}, 0, err
+	}
+	size := int64(binary.LittleEndian.Uint32(prefix))
+	data, err := readAt(fr.r, off+4, size)
+	if err != nil {
+		return geom.Feature{}, 0, err
+	}
+
+	feature, err := fr.decodeFeature(data)
+	return feature, 4 + size, err
+}
+
+// ReadAll reads every feature, in file order.
+func (fr *Reader) ReadAll() (geom.FeatureCollection, error) {
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	off := fr.featuresOffset
+	for i := 0; fr.count == 0 || i < fr.count; i++ {
+		feature, size, err := fr.readFeature(off)
+		if fr.count == 0 && err == io.EOF {
+			break
+		} else if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		fc.Features = append(fc.Features, feature)
+		off += size
+	}
+	return fc, nil
+}
+
+// Search reads the features whose bounding boxes intersect b, in file
+// order. Without an index, it reads every feature and keeps those that
+// intersect.
+func (fr *Reader) Search(b geom.Bounds) (geom.FeatureCollection, error) {
+	query := nodeItem{b.Min[geom.X], b.Min[geom.Y], b.Max[geom.X], b.Max[geom.Y], 0}
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	if !fr.hasIndex() {
+		all, err := fr.ReadAll()
+		if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		for _, t := range all.Features {
+			if g := t.(geom.Feature).T; g != nil {
+				if gb := g.Bounds(geom.NewBounds()); !gb.IsZero() && !gb.Empty() && gb.Overlaps(b) {
+					fc.Features = append(fc.Features, t)
+				}
+			}
+		}
+		return fc, nil
+	}
+
+	indexOffset := fr.featuresOffset - int64(indexSize(fr.count, fr.nodeSize))
+	results, err := searchIndex(fr.r, indexOffset, fr.count, fr.nodeSize, query)
+	if err != nil {
+		return geom.FeatureCollection{}, err
+	}
+	for _, result := range results {
+		if result.offset > math.MaxInt64/2 {
+			return geom.FeatureCollection{}, FormatError{"invalid feature offset"}
+		}
+		feature, _, err := fr.readFeature(fr.featuresOffset + int64(result.offset))
+		if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		fc.Features = append(fc.Features, feature)
+	}
+	return fc, nil
+}
+
+func (fr *Reader) decodeFeature(data []byte) (geom.Feature, error) {
+	d := &decoder{buf: data}
+	f := d.root()
+
+	var g geom.T
+	if pos := d.table(f, featureGeometry); pos != 0 {
+		var err error
+		if g, err = fr.decodeGeometry(d, pos, fr.geometryType, 0); err != nil {
+			return geom.Feature{}, err
+		}
+	}
+
+	columns := fr.columns
+	if c := decodeColumns(d, f, featureColumns); c != nil {
+		columns = c
+	}
+	properties, err := decodeProperties(columns, d.bytes(f, featureProperties))
+	if err != nil {
+		return geom.Feature{}, err
+	}
+	if d.err != nil {
+		return geom.Feature{}, d.err
+	}
+
+	feature := geom.NewFeature(g, nil)
+	if properties != nil {
+		feature.Properties = properties
+	}
+	return feature, nil
+}
+
+func (fr *Reader) points(d *decoder, pos int) ([]geom.Point, error) {
+	xy := d.float64s(pos, geometryXY)
+	if len(xy)%2 != 0 {
+		return nil, FormatError{"odd number of xy values"}
+	}
+	n := len(xy) / 2
+
+	var z, m []float64
+	if fr.axes&geom.Z != 0 {
+		if z = d.float64s(pos, geometryZ); z != nil && len(z) != n {
+			return nil, FormatError{"z values do not match xy"}
+		}
+	}
+	if fr.axes&geom.M != 0 {
+		if m = d.float64s(pos, geometryM); m != nil && len(m) != n {
+			return nil, FormatError{"m values do not match xy"}
+		}
+	}
+
+	points := make([]geom.Point, n)
+	dimension := geom.Layout(fr.axes).Stride()
+	for i := range points {
+		point := make(geom.Point, 2, dimension)
+		point[geom.X], point[geom.Y] = xy[2*i], xy[2*i+1]
+		if fr.axes&geom.Z != 0 {
+			if z != nil {
+				point = append(point, z[i])
+			} else {
+				point = append(point, math.NaN())
+			}
+		}
+		if fr.axes&geom.M != 0 {
+			if m != nil {
+				point = append(point, m[i])
+			} else {
+				point = append(point, math.NaN())
+			}
+		}
+		points[i] = point
+	}
+	return points, nil
+}
+
+// parts splits points at ends, which are point counts. Without ends, all
+// the points form one part.
+func parts(points []geom.Point, ends []uint32) ([][]geom.Point, error) {
+	if ends == nil {
+		if len(points) == 0 {
+			return nil, nil
+		}
+		return [][]geom.Point{points}, nil
+	}
+
+	split := make([][]geom.Point, len(ends))
+	start := 0
+	for i, end := range ends {
+		if int(end) < start || int(end) > len(points) {
+			return nil, FormatError{"invalid ends"}
+		}
+		split[i] = points[start:end:end]
+		start = int(end)
+	}
+	if start != len(points) {
+		return nil, FormatError{"invalid ends"}
+	}
+	return split, nil
+}
+
+// maxDepth limits the nesting of geometry collections.
+const maxDepth = 64
+
+func (fr *Reader) decodeGeometry(d *decoder, pos int, kind int, depth int) (geom.T, error) {
+	if depth > maxDepth {
+		return nil, FormatError{"geometry nested too deeply"}
+	}
+	if kind == fgbUnknown {
+		kind = int(d.uint8Field(pos, geometryType, fgbUnknown))
+	}
+
+	switch kind {
+	case fgbMultiPolygon, fgbGeometryCollection:
+		collection := geom.GeometryCollection{}
+		multiPolygon := geom.MultiPolygon{}
+		for _, part := range d.tables(pos, geometryParts) {
+			if kind == fgbMultiPolygon {
+				g, err := fr.decodeGeometry(d, part, fgbPolygon, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				multiPolygon = append(multiPolygon, g.(geom.Polygon))
+			} else {
+				g, err := fr.decodeGeometry(d, part, fgbUnknown, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				collection = append(collection, g)
+			}
+		}
+		if kind == fgbMultiPolygon {
+			return multiPolygon, d.err
+		}
+		return collection, d.err
+	}
+
+	points, err := fr.points(d, pos)
+	if err != nil {
+		return nil, err
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	switch kind {
+	case fgbPoint:
+		switch len(points) {
+		case 0:
+			return geom.Point{}, nil
+		case 1:
+			return points[0], nil
+		default:
+			return nil, FormatError{"point with more than one position"}
+		}
+	case fgbLineString:
+		return geom.LineString(points), nil
+	case fgbMultiPoint:
+		return geom.MultiPoint(points), nil
+	case fgbPolygon, fgbMultiLineString:
+		split, err := parts(points, d.uint32s(pos, geometryEnds))
+		if err != nil {
+			return nil, err
+		}
+		if kind == fgbPolygon {
+			polygon := make(geom.Polygon, len(split))
+			for i, ring := range split {
+				polygon[i] = ring
+			}
+			return polygon, d.err
+		}
+		multiLineString := make(geom.MultiLineString, len(split))
+		for i, lineString := range split {
+			multiLineString[i] = lineString
+		}
+		return multiLineString, d.err
+	default:
+		return nil, UnsupportedGeometryTypeError{kind}
+	}
+}
+
+// columnSize returns the size of a value of a column type, or 0 if the
+// value is preceded by its length.
+func columnSize(kind int) int {
+	switch kind {
+	case columnByte, columnUByte, columnBool:
+		return 1
+	case columnShort, columnUShort:
+		return 2
+	case columnInt, columnUInt, columnFloat:
+		return 4
+	case columnLong, columnULong, columnDouble:
+		return 8
+	}
+	return 0
+}
+
+func decodeProperties(columns []column, data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	properties := make(map[string]interface{})
+	for len(data) != 0 {
+		if len(data) < 2 {
+			return nil, FormatError{"truncated properties"}
+		}
+		i := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if i >= len(columns) {
+			return nil, FormatError{"invalid column index"}
+		}
+		c := columns[i]
+
+		size := columnSize(c.kind)
+		if size == 0 {
+			if len(data) < 4 {
+				return nil, FormatError{"truncated properties"}
+			}
+			n := binary.LittleEndian.Uint32(data)
+			data = data[4:]
+			if uint64(n) > uint64(len(data)) {
+				return nil, FormatError{"truncated properties"}
+			}
+			size = int(n)
+		} else if len(data) < size {
+			return nil, FormatError{"truncated properties"}
+		}
+		b := data[:size]
+		data = data[size:]
+
+		var v interface{}
+		switch c.kind {
+		case columnByte:
+			v = int8(b[0])
+		case columnUByte:
+			v = b[0]
+		case columnBool:
+			v = b[0] != 0
+		case columnShort:
+			v = int16(binary.LittleEndian.Uint16(b))
+		case columnUShort:
+			v = binary.LittleEndian.Uint16(b)
+		case columnInt:
+			v = int32(binary.LittleEndian.Uint32(b))
+		case columnUInt:
+			v = binary.LittleEndian.Uint32(b)
+		case columnLong:
+			v = int64(binary.LittleEndian.Uint64(b))
+		case columnULong:
+			v = binary.LittleEndian.Uint64(b)
+		case columnFloat:
+			v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case columnDouble:
+			v = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case columnString:
+			v = string(b)
+		case columnJSON:
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, FormatError{"invalid JSON property " + c.name}
+			}
+		case columnDateTime:
+			if t, err := time.Parse(time.RFC3339Nano, string(b)); err == nil {
+				v = t
+			} else {
+				v = string(b)
+			}
+		default:
+			v = append([]byte(nil), b...)
+		}
+		properties[c.name] = v
+	}
+	return properties, nil
+}
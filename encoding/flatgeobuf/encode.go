@@ -0,0 +1,448 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/foobaz/geom"
+)
+
+// column is a property of the features, stored in the header.
+type column struct {
+	name string
+	kind int
+}
+
+func valueKind(v interface{}) int {
+	switch v.(type) {
+	case bool:
+		return columnBool
+	case int, int8, int16, int32, int64:
+		return columnLong
+	case uint, uint8, uint16, uint32, uint64:
+		return columnULong
+	case float32, float64:
+		return columnDouble
+	case string:
+		return columnString
+	case time.Time:
+		return columnDateTime
+	case []byte:
+		return columnBinary
+	default:
+		return columnJSON
+	}
+}
+
+func isNumberKind(kind int) bool {
+	return kind == columnLong || kind == columnULong || kind == columnDouble
+}
+
+// newColumns infers a column for every property name, in sorted order.
+// Numbers of different types share a Double column; other mixtures of
+// types are stored as JSON.
+func newColumns(records []map[string]interface{}) []column {
+	kinds := make(map[string]int)
+	for _, properties := range records {
+		for name, v := range properties {
+			if v == nil {
+				if _, ok := kinds[name]; !ok {
+					kinds[name] = -1
+				}
+				continue
+			}
+			kind := valueKind(v)
+			if k, ok := kinds[name]; ok && k != -1 && k != kind {
+				if isNumberKind(k) && isNumberKind(kind) {
+					kind = columnDouble
+				} else {
+					kind = columnJSON
+				}
+			}
+			kinds[name] = kind
+		}
+	}
+
+	columns := make([]column, 0, len(kinds))
+	for name, kind := range kinds {
+		if kind == -1 {
+			kind = columnString
+		}
+		columns = append(columns, column{name, kind})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].name < columns[j].name
+	})
+	return columns
+}
+
+func appendValue(dst []byte, kind int, v interface{}) ([]byte, error) {
+	switch kind {
+	case columnBool:
+		if v.(bool) {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case columnLong:
+		return binary.LittleEndian.AppendUint64(dst, uint64(reflect.ValueOf(v).Int())), nil
+	case columnULong:
+		return binary.LittleEndian.AppendUint64(dst, reflect.ValueOf(v).Uint()), nil
+	case columnDouble:
+		f := reflect.ValueOf(v)
+		var d float64
+		switch f.Kind() {
+		case reflect.Float32, reflect.Float64:
+			d = f.Float()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			d = float64(f.Uint())
+		default:
+			d = float64(f.Int())
+		}
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(d)), nil
+	case columnString:
+		return appendString(dst, v.(string)), nil
+	case columnDateTime:
+		return appendString(dst, v.(time.Time).Format(time.RFC3339Nano)), nil
+	case columnBinary:
+		return appendString(dst, string(v.([]byte))), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return appendString(dst, string(data)), nil
+	}
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(s)))
+	return append(dst, s...)
+}
+
+func encodeProperties(columns []column, properties map[string]interface{}) ([]byte, error) {
+	var dst []byte
+	for i, c := range columns {
+		v := properties[c.name]
+		if v == nil {
+			continue
+		}
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(i))
+		var err error
+		if dst, err = appendValue(dst, c.kind, v); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+type encoder struct {
+	axes uint32
+}
+
+// geometryData holds the vectors of a Geometry table.
+type geometryData struct {
+	xy, z, m []float64
+	ends     []uint32
+}
+
+func (e *encoder) addPoints(data *geometryData, points []geom.Point) error {
+	dimension := geom.Layout(e.axes).Stride()
+	for _, point := range points {
+		if len(point) < dimension {
+			return DimensionError{Dimension: dimension, ElementCount: len(point)}
+		}
+		data.xy = append(data.xy, point[geom.X], point[geom.Y])
+		next := 2
+		if e.axes&geom.Z != 0 {
+			data.z = append(data.z, point[next])
+			next++
+		}
+		if e.axes&geom.M != 0 {
+			data.m = append(data.m, point[next])
+		}
+	}
+	return nil
+}
+
+func (e *encoder) addParts(data *geometryData, parts [][]geom.Point) error {
+	for _, part := range parts {
+		if err := e.addPoints(data, part); err != nil {
+			return err
+		}
+		data.ends = append(data.ends, uint32(len(data.xy)/2))
+	}
+	if len(parts) < 2 {
+		data.ends = nil
+	}
+	return nil
+}
+
+func (e *encoder) geometry(b *builder, t geom.T) (int, error) {
+	kind, err := geometryTypeOf(t)
+	if err != nil {
+		return 0, err
+	}
+
+	var data geometryData
+	var parts []int
+	switch g := t.(type) {
+	case geom.Point:
+		if len(g) != 0 {
+			err = e.addPoints(&data, []geom.Point{g})
+		}
+	case geom.LineString:
+		err = e.addPoints(&data, g)
+	case geom.MultiPoint:
+		err = e.addPoints(&data, g)
+	case geom.Polygon:
+		rings := make([][]geom.Point, len(g))
+		for i, ring := range g {
+			rings[i] = ring
+		}
+		err = e.addParts(&data, rings)
+	case geom.MultiLineString:
+		lineStrings := make([][]geom.Point, len(g))
+		for i, lineString := range g {
+			lineStrings[i] = lineString
+		}
+		err = e.addParts(&data, lineStrings)
+	case geom.MultiPolygon:
+		for _, polygon := range g {
+			part, err := e.geometry(b, polygon)
+			if err != nil {
+				return 0, err
+			}
+			parts = append(parts, part)
+		}
+	case geom.GeometryCollection:
+		for _, t := range g {
+			part, err := e.geometry(b, t)
+			if err != nil {
+				return 0, err
+			}
+			parts = append(parts, part)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var ends, xy, z, m, partsVector int
+	if data.ends != nil {
+		ends = b.createUint32s(data.ends)
+	}
+	if data.xy != nil {
+		xy = b.createFloat64s(data.xy)
+	}
+	if data.z != nil {
+		z = b.createFloat64s(data.z)
+	}
+	if data.m != nil {
+		m = b.createFloat64s(data.m)
+	}
+	if parts != nil {
+		partsVector = b.createOffsets(parts)
+	}
+
+	b.startTable(geometryFields)
+	b.addOffsets([][2]int{{geometryEnds, ends}, {geometryXY, xy}, {geometryZ, z}, {geometryM, m}, {geometryParts, partsVector}})
+	b.addUint8(geometryType, uint8(kind))
+	return b.endTable(), nil
+}
+
+// feature returns a size-prefixed Feature buffer.
+func (e *encoder) feature(t geom.T, properties []byte) ([]byte, error) {
+	b := newBuilder(1024)
+	var geometry, props int
+	if t != nil {
+		var err error
+		if geometry, err = e.geometry(b, t); err != nil {
+			return nil, err
+		}
+	}
+	if len(properties) != 0 {
+		props = b.createBytes(properties)
+	}
+
+	b.startTable(featureFields)
+	if geometry != 0 {
+		b.addOffset(featureGeometry, geometry)
+	}
+	if props != 0 {
+		b.addOffset(featureProperties, props)
+	}
+	return b.finish(b.endTable()), nil
+}
+
+func header(options Options, geometryType int, extent nodeItem, columns []column, count, nodeSize int) []byte {
+	b := newBuilder(1024)
+	var name, envelope, columnsVector, crs int
+	if options.Name != "" {
+		name = b.createString(options.Name)
+	}
+	if !extent.empty() {
+		envelope = b.createFloat64s([]float64{extent.minX, extent.minY, extent.maxX, extent.maxY})
+	}
+	if len(columns) != 0 {
+		tables := make([]int, len(columns))
+		for i, c := range columns {
+			n := b.createString(c.name)
+			b.startTable(columnFields)
+			b.addOffset(columnName, n)
+			b.addUint8(columnType, uint8(c.kind))
+			tables[i] = b.endTable()
+		}
+		columnsVector = b.createOffsets(tables)
+	}
+	if options.SRID != 0 {
+		org := b.createString("EPSG")
+		b.startTable(crsFields)
+		b.addOffset(crsOrg, org)
+		b.addInt32(crsCode, int32(options.SRID))
+		crs = b.endTable()
+	}
+
+	b.startTable(headerFields)
+	b.addUint64(headerFeaturesCount, uint64(count))
+	b.addOffsets([][2]int{{headerName, name}, {headerEnvelope, envelope}, {headerColumns, columnsVector}, {headerCRS, crs}})
+	if nodeSize != defaultIndexNodeSize {
+		b.addUint16(headerIndexNodeSize, uint16(nodeSize))
+	}
+	if geometryType != fgbUnknown {
+		b.addUint8(headerGeometryType, uint8(geometryType))
+	}
+	if options.Axes&geom.Z != 0 {
+		b.addUint8(headerHasZ, 1)
+	}
+	if options.Axes&geom.M != 0 {
+		b.addUint8(headerHasM, 1)
+	}
+	return b.finish(b.endTable())
+}
+
+// Encode returns fc as FlatGeobuf. Each element of fc.Features may be a
+// geom.Feature, whose Properties must be nil or a map[string]interface{},
+// or a bare geometry. Property columns are inferred from the values:
+// booleans, signed and unsigned integers, floats, strings, time.Time and
+// []byte have their own column types, and anything else is stored as JSON.
+// Nil properties are left out.
+func Encode(fc geom.FeatureCollection, options Options) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Write(&b, fc, options); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func Write(w io.Writer, fc geom.FeatureCollection, options Options) error {
+	if geom.Layout(options.Axes).Stride() == 0 {
+		return UnsupportedAxesError{options.Axes}
+	}
+	nodeSize := options.IndexNodeSize
+	if nodeSize == 0 {
+		nodeSize = defaultIndexNodeSize
+	}
+	if nodeSize < 2 || nodeSize > math.MaxUint16 {
+		return InvalidIndexNodeSizeError{options.IndexNodeSize}
+	}
+	if options.NoIndex {
+		nodeSize = 0
+	}
+
+	geometries := make([]geom.T, len(fc.Features))
+	records := make([]map[string]interface{}, len(fc.Features))
+	geometryType := -1
+	for i, t := range fc.Features {
+		if f, ok := t.(geom.Feature); ok {
+			t = f.T
+			if f.Properties != nil {
+				properties, ok := f.Properties.(map[string]interface{})
+				if !ok {
+					return UnsupportedPropertiesError{reflect.TypeOf(f.Properties)}
+				}
+				records[i] = properties
+			}
+		}
+		if s, ok := t.(geom.SRIDGeometry); ok {
+			t = s.T
+		}
+		geometries[i] = t
+		if t == nil {
+			continue
+		}
+
+		thisType, err := geometryTypeOf(t)
+		if err != nil {
+			return err
+		}
+		if geometryType == -1 {
+			geometryType = thisType
+		} else if geometryType != thisType {
+			geometryType = fgbUnknown
+		}
+	}
+	if geometryType == -1 {
+		geometryType = fgbUnknown
+	}
+
+	columns := newColumns(records)
+	e := &encoder{axes: options.Axes}
+	features := make([][]byte, len(geometries))
+	items := make([]nodeItem, len(geometries))
+	extent := emptyNode()
+	for i, g := range geometries {
+		properties, err := encodeProperties(columns, records[i])
+		if err != nil {
+			return err
+		}
+		if features[i], err = e.feature(g, properties); err != nil {
+			return err
+		}
+
+		items[i] = emptyNode()
+		if g != nil {
+			if b := g.Bounds(geom.NewBounds()); !b.IsZero() && !b.Empty() {
+				items[i] = nodeItem{b.Min[geom.X], b.Min[geom.Y], b.Max[geom.X], b.Max[geom.Y], 0}
+				extent.expand(items[i])
+			}
+		}
+	}
+
+	var index []byte
+	if nodeSize != 0 && len(features) != 0 {
+		order := hilbertOrder(items, extent)
+		sortedFeatures := make([][]byte, len(features))
+		sortedItems := make([]nodeItem, len(items))
+		offset := uint64(0)
+		for i, j := range order {
+			sortedFeatures[i] = features[j]
+			sortedItems[i] = items[j]
+			sortedItems[i].offset = offset
+			offset += uint64(len(features[j]))
+		}
+		features = sortedFeatures
+		index = buildIndex(sortedItems, nodeSize)
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+	if _, err := w.Write(header(options, geometryType, extent, columns, len(features), nodeSize)); err != nil {
+		return err
+	}
+	if _, err := w.Write(index); err != nil {
+		return err
+	}
+	for _, feature := range features {
+		if _, err := w.Write(feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// builder writes a FlatBuffer back to front, as the reference
+// implementations do, so that every table refers forward to its strings,
+// vectors and subtables. Offsets are measured from the end of the buffer.
+type builder struct {
+	buf       []byte
+	head      int
+	minAlign  int
+	vtable    []int
+	objectEnd int
+}
+
+func newBuilder(size int) *builder {
+	return &builder{buf: make([]byte, size), head: size, minAlign: 1}
+}
+
+func (b *builder) offset() int {
+	return len(b.buf) - b.head
+}
+
+func (b *builder) grow(n int) {
+	for b.head < n {
+		size := 2 * len(b.buf)
+		if size == 0 {
+			size = 64
+		}
+		buf := make([]byte, size)
+		copy(buf[size-len(b.buf):], b.buf)
+		b.head += size - len(b.buf)
+		b.buf = buf
+	}
+}
+
+// prep pads the buffer so that, after additional bytes are written, a
+// value of the given size is aligned.
+func (b *builder) prep(size, additional int) {
+	if size > b.minAlign {
+		b.minAlign = size
+	}
+	pad := (-(b.offset() + additional)) & (size - 1)
+	b.grow(pad + additional + size)
+	for i := 0; i < pad; i++ {
+		b.head--
+		b.buf[b.head] = 0
+	}
+}
+
+func (b *builder) placeUint8(v uint8) {
+	b.head--
+	b.buf[b.head] = v
+}
+
+func (b *builder) placeUint16(v uint16) {
+	b.head -= 2
+	binary.LittleEndian.PutUint16(b.buf[b.head:], v)
+}
+
+func (b *builder) placeUint32(v uint32) {
+	b.head -= 4
+	binary.LittleEndian.PutUint32(b.buf[b.head:], v)
+}
+
+func (b *builder) placeUint64(v uint64) {
+	b.head -= 8
+	binary.LittleEndian.PutUint64(b.buf[b.head:], v)
+}
+
+func (b *builder) prependUOffset(off int) {
+	b.prep(4, 0)
+	b.placeUint32(uint32(b.offset() - off + 4))
+}
+
+func (b *builder) startVector(elemSize, n, alignment int) {
+	b.prep(4, elemSize*n)
+	b.prep(alignment, elemSize*n)
+}
+
+func (b *builder) endVector(n int) int {
+	b.prep(4, 0)
+	b.placeUint32(uint32(n))
+	return b.offset()
+}
+
+func (b *builder) createString(s string) int {
+	b.prep(4, len(s)+1)
+	b.placeUint8(0)
+	b.head -= len(s)
+	copy(b.buf[b.head:], s)
+	return b.endVector(len(s))
+}
+
+func (b *builder) createBytes(v []byte) int {
+	b.startVector(1, len(v), 1)
+	b.head -= len(v)
+	copy(b.buf[b.head:], v)
+	return b.endVector(len(v))
+}
+
+func (b *builder) createFloat64s(v []float64) int {
+	b.startVector(8, len(v), 8)
+	for i := len(v) - 1; i >= 0; i-- {
+		b.placeUint64(math.Float64bits(v[i]))
+	}
+	return b.endVector(len(v))
+}
+
+func (b *builder) createUint32s(v []uint32) int {
+	b.startVector(4, len(v), 4)
+	for i := len(v) - 1; i >= 0; i-- {
+		b.placeUint32(v[i])
+	}
+	return b.endVector(len(v))
+}
+
+func (b *builder) createOffsets(v []int) int {
+	b.startVector(4, len(v), 4)
+	for i := len(v) - 1; i >= 0; i-- {
+		b.prependUOffset(v[i])
+	}
+	return b.endVector(len(v))
+}
+
+func (b *builder) startTable(numFields int) {
+	b.vtable = make([]int, numFields)
+	b.objectEnd = b.offset()
+}
+
+func (b *builder) addUint8(slot int, v uint8) {
+	b.prep(1, 0)
+	b.placeUint8(v)
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addUint16(slot int, v uint16) {
+	b.prep(2, 0)
+	b.placeUint16(v)
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addInt32(slot int, v int32) {
+	b.prep(4, 0)
+	b.placeUint32(uint32(v))
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addUint64(slot int, v uint64) {
+	b.prep(8, 0)
+	b.placeUint64(v)
+	b.vtable[slot] = b.offset()
+}
+
+func (b *builder) addOffset(slot int, off int) {
+	b.prependUOffset(off)
+	b.vtable[slot] = b.offset()
+}
+
+// addOffsets adds the nonzero offsets of a list of slot and offset pairs.
+func (b *builder) addOffsets(fields [][2]int) {
+	for _, f := range fields {
+		if f[1] != 0 {
+			b.addOffset(f[0], f[1])
+		}
+	}
+}
+
+func (b *builder) endTable() int {
+	b.prep(4, 0)
+	b.placeUint32(0)
+	object := b.offset()
+
+	n := len(b.vtable)
+	for n > 0 && b.vtable[n-1] == 0 {
+		n--
+	}
+	b.grow(2 * (n + 2))
+	for i := n - 1; i >= 0; i-- {
+		off := 0
+		if b.vtable[i] != 0 {
+			off = object - b.vtable[i]
+		}
+		b.placeUint16(uint16(off))
+	}
+	b.placeUint16(uint16(object - b.objectEnd))
+	b.placeUint16(uint16(2 * (n + 2)))
+
+	vtable := b.offset()
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-object:], uint32(vtable-object))
+	b.vtable = nil
+	return object
+}
+
+// finish writes the root offset preceded by the size of the rest of the
+// buffer, as FlatGeobuf requires, and returns the finished buffer.
+func (b *builder) finish(root int) []byte {
+	b.prep(b.minAlign, 8)
+	b.prependUOffset(root)
+	b.placeUint32(uint32(b.offset()))
+	return b.buf[b.head:]
+}
+
+// decoder reads a FlatBuffer without trusting it: every access is bounds
+// checked, and the first failure is kept in err.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) check(pos, n int) bool {
+	if d.err != nil {
+		return false
+	}
+	if pos < 0 || n < 0 || pos > len(d.buf) || n > len(d.buf)-pos {
+		d.err = FormatError{"offset out of range"}
+		return false
+	}
+	return true
+}
+
+func (d *decoder) uint8(pos int) uint8 {
+	if !d.check(pos, 1) {
+		return 0
+	}
+	return d.buf[pos]
+}
+
+func (d *decoder) uint16(pos int) uint16 {
+	if !d.check(pos, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(d.buf[pos:])
+}
+
+func (d *decoder) uint32(pos int) uint32 {
+	if !d.check(pos, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(d.buf[pos:])
+}
+
+func (d *decoder) uint64(pos int) uint64 {
+	if !d.check(pos, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(d.buf[pos:])
+}
+
+func (d *decoder) float64(pos int) float64 {
+	return math.Float64frombits(d.uint64(pos))
+}
+
+// indirect follows the unsigned offset stored at pos.
+func (d *decoder) indirect(pos int) int {
+	return pos + int(d.uint32(pos))
+}
+
+// root returns the position of the root table.
+func (d *decoder) root() int {
+	return d.indirect(0)
+}
+
+// field returns the position of a field of the table at pos, or 0 if the
+// field is absent.
+func (d *decoder) field(table, slot int) int {
+	vtable := table - int(int32(d.uint32(table)))
+	vtableSize := int(d.uint16(vtable))
+	o := 4 + 2*slot
+	if d.err != nil || o+2 > vtableSize {
+		return 0
+	}
+	if off := int(d.uint16(vtable + o)); off != 0 {
+		return table + off
+	}
+	return 0
+}
+
+func (d *decoder) uint8Field(table, slot int, def uint8) uint8 {
+	if pos := d.field(table, slot); pos != 0 {
+		return d.uint8(pos)
+	}
+	return def
+}
+
+func (d *decoder) uint16Field(table, slot int, def uint16) uint16 {
+	if pos := d.field(table, slot); pos != 0 {
+		return d.uint16(pos)
+	}
+	return def
+}
+
+func (d *decoder) uint64Field(table, slot int, def uint64) uint64 {
+	if pos := d.field(table, slot); pos != 0 {
+		return d.uint64(pos)
+	}
+	return def
+}
+
+// table returns the position of a subtable, or 0 if it is absent.
+func (d *decoder) table(table, slot int) int {
+	if pos := d.field(table, slot); pos != 0 {
+		return d.indirect(pos)
+	}
+	return 0
+}
+
+// vector returns the position of the first element of a vector and its
+// length, checking that elements of the given size fit in the buffer.
+func (d *decoder) vector(table, slot, elemSize int) (int, int) {
+	pos := d.field(table, slot)
+	if pos == 0 {
+		return 0, 0
+	}
+	pos = d.indirect(pos)
+	n := int(d.uint32(pos))
+	if n > len(d.buf)/elemSize {
+		d.check(-1, 0)
+		return 0, 0
+	}
+	if !d.check(pos+4, n*elemSize) {
+		return 0, 0
+	}
+	return pos + 4, n
+}
+
+func (d *decoder) bytes(table, slot int) []byte {
+	pos, n := d.vector(table, slot, 1)
+	if n == 0 {
+		return nil
+	}
+	return d.buf[pos : pos+n]
+}
+
+func (d *decoder) string(table, slot int) string {
+	return string(d.bytes(table, slot))
+}
+
+func (d *decoder) float64s(table, slot int) []float64 {
+	pos, n := d.vector(table, slot, 8)
+	if n == 0 {
+		return nil
+	}
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = d.float64(pos + 8*i)
+	}
+	return v
+}
+
+func (d *decoder) uint32s(table, slot int) []uint32 {
+	pos, n := d.vector(table, slot, 4)
+	if n == 0 {
+		return nil
+	}
+	v := make([]uint32, n)
+	for i := range v {
+		v[i] = d.uint32(pos + 4*i)
+	}
+	return v
+}
+
+// tables returns the positions of the tables in a vector of tables.
+func (d *decoder) tables(table, slot int) []int {
+	pos, n := d.vector(table, slot, 4)
+	if n == 0 {
+		return nil
+	}
+	v := make([]int, n)
+	for i := range v {
+		v[i] = d.indirect(pos + 4*i)
+	}
+	return v
+}
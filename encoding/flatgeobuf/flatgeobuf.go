@@ -0,0 +1,172 @@
+// Package flatgeobuf reads and writes FlatGeobuf, a binary format of
+// FlatBuffers-encoded features preceded by an optional packed Hilbert
+// R-tree, which lets a reader fetch only the features in a bounding box.
+package flatgeobuf
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+// magic starts every file: "fgb", the major version, "fgb" and the patch
+// version.
+var magic = []byte{0x66, 0x67, 0x62, 0x03, 0x66, 0x67, 0x62, 0x00}
+
+const (
+	fgbUnknown            = 0
+	fgbPoint              = 1
+	fgbLineString         = 2
+	fgbPolygon            = 3
+	fgbMultiPoint         = 4
+	fgbMultiLineString    = 5
+	fgbMultiPolygon       = 6
+	fgbGeometryCollection = 7
+)
+
+// Column types.
+const (
+	columnByte     = 0
+	columnUByte    = 1
+	columnBool     = 2
+	columnShort    = 3
+	columnUShort   = 4
+	columnInt      = 5
+	columnUInt     = 6
+	columnLong     = 7
+	columnULong    = 8
+	columnFloat    = 9
+	columnDouble   = 10
+	columnString   = 11
+	columnJSON     = 12
+	columnDateTime = 13
+	columnBinary   = 14
+)
+
+// Field slots of the Header, Crs, Column, Feature and Geometry tables.
+const (
+	headerName          = 0
+	headerEnvelope      = 1
+	headerGeometryType  = 2
+	headerHasZ          = 3
+	headerHasM          = 4
+	headerColumns       = 7
+	headerFeaturesCount = 8
+	headerIndexNodeSize = 9
+	headerCRS           = 10
+	headerFields        = 14
+
+	crsOrg    = 0
+	crsCode   = 1
+	crsFields = 6
+
+	columnName   = 0
+	columnType   = 1
+	columnFields = 11
+
+	featureGeometry   = 0
+	featureProperties = 1
+	featureColumns    = 2
+	featureFields     = 3
+
+	geometryEnds   = 0
+	geometryXY     = 1
+	geometryZ      = 2
+	geometryM      = 3
+	geometryType   = 6
+	geometryParts  = 7
+	geometryFields = 8
+)
+
+const defaultIndexNodeSize = 16
+
+// Options configures encoding. The zero value writes two-dimensional
+// geometries with an index of node size 16.
+type Options struct {
+	// Axes must be geom.TwoD, geom.Z, geom.M, or geom.ZM.
+	Axes uint32
+	// IndexNodeSize is the number of children of each R-tree node, at
+	// least 2. Zero means 16.
+	IndexNodeSize int
+	// NoIndex leaves out the R-tree, keeping features in their original
+	// order. With an index, features are written in Hilbert order.
+	NoIndex bool
+	// Name is the dataset name stored in the header.
+	Name string
+	// SRID, if nonzero, is stored as an EPSG code.
+	SRID int
+}
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "flatgeobuf: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("flatgeobuf: unsupported axes %d", e.Axes)
+}
+
+type UnsupportedGeometryTypeError struct {
+	GeometryType int
+}
+
+func (e UnsupportedGeometryTypeError) Error() string {
+	return fmt.Sprintf("flatgeobuf: unsupported geometry type %d", e.GeometryType)
+}
+
+type UnsupportedPropertiesError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedPropertiesError) Error() string {
+	return "flatgeobuf: unsupported properties type: " + e.Type.String()
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+type InvalidIndexNodeSizeError struct {
+	IndexNodeSize int
+}
+
+func (e InvalidIndexNodeSizeError) Error() string {
+	return fmt.Sprintf("flatgeobuf: invalid index node size %d", e.IndexNodeSize)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "flatgeobuf: " + e.Msg
+}
+
+func geometryTypeOf(t geom.T) (int, error) {
+	switch t.(type) {
+	case geom.Point:
+		return fgbPoint, nil
+	case geom.LineString:
+		return fgbLineString, nil
+	case geom.Polygon:
+		return fgbPolygon, nil
+	case geom.MultiPoint:
+		return fgbMultiPoint, nil
+	case geom.MultiLineString:
+		return fgbMultiLineString, nil
+	case geom.MultiPolygon:
+		return fgbMultiPolygon, nil
+	case geom.GeometryCollection:
+		return fgbGeometryCollection, nil
+	default:
+		return 0, UnsupportedGeometryError{reflect.TypeOf(t)}
+	}
+}
@@ -0,0 +1,304 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/foobaz/geom"
+)
+
+func TestFlatGeobuf(t *testing.T) {
+	date := time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+	var testCases = []struct {
+		fc      geom.FeatureCollection
+		options Options
+	}{
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{
+					"name":  "a",
+					"count": int64(-3),
+					"big":   uint64(1 << 63),
+					"ratio": 0.5,
+					"ok":    true,
+					"when":  date,
+					"raw":   []byte{1, 2},
+					"tags":  []interface{}{"x", 1.0},
+				}),
+				geom.NewFeature(geom.Point{3, 4}, map[string]interface{}{"name": "b"}),
+				geom.NewFeature(nil, nil),
+			}},
+			Options{NoIndex: true, Name: "points", SRID: 4326},
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{}, nil),
+				geom.NewFeature(geom.Point{1, 2}, nil),
+			}},
+			Options{},
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.LineString{{1, 2}, {3, 4}}, nil),
+				geom.NewFeature(geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}, nil),
+				geom.NewFeature(geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}}, nil),
+				geom.NewFeature(geom.MultiPoint{{1, 2}, {3, 4}}, nil),
+				geom.NewFeature(geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}, nil),
+				geom.NewFeature(geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}}, nil),
+				geom.NewFeature(geom.GeometryCollection{geom.Point{1, 2}, geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}}, nil),
+			}},
+			Options{NoIndex: true},
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.LineString{{1, 2, 3, 4}, {5, 6, 7, 8}}, nil),
+			}},
+			Options{Axes: geom.ZM},
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{1, 2, 3}, nil),
+			}},
+			Options{Axes: geom.M},
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{}},
+			Options{},
+		},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.fc, tc.options)
+		if err != nil {
+			t.Errorf("Encode(%#v, %#v) == %v, want nil", tc.fc, tc.options, err)
+			continue
+		}
+		got, err := Decode(data)
+		if err != nil || !reflect.DeepEqual(got, tc.fc) {
+			t.Errorf("Decode(Encode(%#v, %#v)) == %#v, %v, want %#v, nil", tc.fc, tc.options, got, err, tc.fc)
+		}
+
+		r, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Name() != tc.options.Name || r.SRID() != tc.options.SRID || r.Count() != len(tc.fc.Features) || r.Axes() != tc.options.Axes {
+			t.Errorf("NewReader header == %q, %d, %d, %d", r.Name(), r.SRID(), r.Count(), r.Axes())
+		}
+	}
+}
+
+// reference is a FlatGeobuf file assembled by hand from the schema,
+// independently of this package's builder. Its vtables end at their last
+// field, the index node size is left at its default, the CRS has a code but
+// no organization, and the features leave out the geometry type given in
+// the header. It holds two points with a string column, and an index of
+// node size 16.
+const reference = "6667620366676200" +
+	// header: size, root offset and vtable
+	"a4000000200000001a00200004000800" +
+	"1c00000000000000000014000c000000" +
+	// header table: name, envelope, features count, columns, crs and
+	// geometry type
+	"180000001c0000001c00000020000000" +
+	"02000000000000003800000064000000" +
+	"01000000" +
+	// name, envelope and columns
+	"030000007265660004000000" +
+	"000000000000f03f0000000000000040" +
+	"00000000000008400000000000001040" +
+	"010000000c000000" +
+	// column "name" of type string
+	"08000c0004000800" +
+	"08000000080000000b00000004000000" +
+	"6e616d6500000000" +
+	// crs with code 4326
+	"0800080000000400" +
+	"08000000e6100000" +
+	// index: the root, then a leaf for each feature
+	"000000000000f03f0000000000000040" +
+	"00000000000008400000000000001040" +
+	"0100000000000000" +
+	"000000000000f03f0000000000000040" +
+	"000000000000f03f0000000000000040" +
+	"0000000000000000" +
+	"00000000000008400000000000001040" +
+	"00000000000008400000000000001040" +
+	"5000000000000000" +
+	// first feature: point 1 2, name "a"
+	"4c0000000c00000008000c0004000800" +
+	"080000001c0000000400000007000000" +
+	"00000100000061000800080000000400" +
+	"08000000080000000000000002000000" +
+	"000000000000f03f0000000000000040" +
+	// second feature: point 3 4, name "bc"
+	"4c0000000c00000008000c0004000800" +
+	"080000001c0000000400000008000000" +
+	"00000200000062630800080000000400" +
+	"08000000080000000000000002000000" +
+	"00000000000008400000000000001040"
+
+func TestFlatGeobufReference(t *testing.T) {
+	data, err := hex.DecodeString(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.FeatureCollection{Features: []geom.T{
+		geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"name": "a"}),
+		geom.NewFeature(geom.Point{3, 4}, map[string]interface{}{"name": "bc"}),
+	}}
+	if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(reference) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name() != "ref" || r.SRID() != 4326 || r.Count() != 2 || r.Axes() != geom.TwoD {
+		t.Errorf("NewReader header == %q, %d, %d, %d", r.Name(), r.SRID(), r.Count(), r.Axes())
+	}
+	query := geom.Bounds{Min: geom.Point{2.5, 3.5}, Max: geom.Point{5, 5}}
+	if got, err := r.Search(query); err != nil || !reflect.DeepEqual(got.Features, want.Features[1:]) {
+		t.Errorf("Search(%#v) == %#v, %v, want %#v, nil", query, got, err, want.Features[1:])
+	}
+}
+
+func TestFlatGeobufSearch(t *testing.T) {
+	fc := geom.FeatureCollection{}
+	for i := 0; i < 300; i++ {
+		x, y := float64(i%20), float64(i/20)
+		var g geom.T = geom.Point{x, y}
+		if i%7 == 0 {
+			g = geom.LineString{{x, y}, {x + 2.5, y + 0.5}}
+		}
+		fc = fc.AppendGeometry(g, map[string]interface{}{"i": int64(i)})
+	}
+	fc = fc.AppendGeometry(nil, map[string]interface{}{"i": int64(-1)})
+
+	for _, nodeSize := range []int{2, 4, 16} {
+		for _, noIndex := range []bool{false, true} {
+			data, err := Encode(fc, Options{IndexNodeSize: nodeSize, NoIndex: noIndex})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := r.Bounds(); !reflect.DeepEqual(b, geom.Bounds{Min: geom.Point{0, 0}, Max: geom.Point{21.5, 14.5}}) {
+				t.Errorf("Bounds() == %#v", b)
+			}
+
+			query := geom.Bounds{Min: geom.Point{3.5, 2.5}, Max: geom.Point{7, 6}}
+			found, err := r.Search(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want []int64
+			for _, f := range found.Features {
+				got = append(got, f.(geom.Feature).Properties.(map[string]interface{})["i"].(int64))
+			}
+			for _, f := range fc.Features {
+				f := f.(geom.Feature)
+				if f.T != nil && f.T.Bounds(geom.NewBounds()).Overlaps(query) {
+					want = append(want, f.Properties.(map[string]interface{})["i"].(int64))
+				}
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if len(want) == 0 || !reflect.DeepEqual(got, want) {
+				t.Errorf("Search with node size %d, no index %v == %v, want %v", nodeSize, noIndex, got, want)
+			}
+		}
+	}
+}
+
+func TestLevelBounds(t *testing.T) {
+	if got, want := levelBounds(10, 4), [][2]int{{4, 14}, {1, 4}, {0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("levelBounds(10, 4) == %v, want %v", got, want)
+	}
+	if got, want := levelBounds(1, 16), [][2]int{{1, 2}, {0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("levelBounds(1, 16) == %v, want %v", got, want)
+	}
+	if got := hilbert(0, 0); got != 0 {
+		t.Errorf("hilbert(0, 0) == %d, want 0", got)
+	}
+	if got := hilbert(hilbertMax, 0); got != math.MaxUint32 {
+		t.Errorf("hilbert(max, 0) == %d, want %d", got, uint32(math.MaxUint32))
+	}
+}
+
+// TestFlatBuffersLayout checks the alignment that FlatBuffers verifiers
+// insist on: vectors of doubles must start on an eight byte boundary.
+func TestFlatBuffersLayout(t *testing.T) {
+	b := newBuilder(1)
+	name := b.createString("abc")
+	v := b.createFloat64s([]float64{1, 2})
+	b.startTable(3)
+	b.addUint8(2, 7)
+	b.addOffset(0, name)
+	b.addOffset(1, v)
+	buf := b.finish(b.endTable())
+
+	if len(buf)%8 != 0 || int(binary.LittleEndian.Uint32(buf)) != len(buf)-4 {
+		t.Fatalf("finish returned %d bytes with size prefix %d", len(buf), binary.LittleEndian.Uint32(buf))
+	}
+	d := &decoder{buf: buf[4:]}
+	root := d.root()
+	pos, n := d.vector(root, 1, 8)
+	if d.string(root, 0) != "abc" || n != 2 || d.uint8Field(root, 2, 0) != 7 || d.uint8Field(root, 5, 9) != 9 {
+		t.Errorf("decoded %q, %d, %d", d.string(root, 0), n, d.uint8Field(root, 2, 0))
+	}
+	if (pos+4)%8 != 0 {
+		t.Errorf("double vector at %d, want multiple of 8", pos+4)
+	}
+	if got := d.float64s(root, 1); !reflect.DeepEqual(got, []float64{1, 2}) {
+		t.Errorf("float64s == %v", got)
+	}
+}
+
+func TestFlatGeobufError(t *testing.T) {
+	point := geom.FeatureCollection{Features: []geom.T{geom.Point{1, 2}}}
+	var testCases = []struct {
+		fc      geom.FeatureCollection
+		options Options
+		err     error
+	}{
+		{point, Options{Axes: 9}, UnsupportedAxesError{9}},
+		{point, Options{Axes: geom.Z}, DimensionError{Dimension: 3, ElementCount: 2}},
+		{point, Options{IndexNodeSize: 1}, InvalidIndexNodeSizeError{1}},
+		{geom.FeatureCollection{Features: []geom.T{geom.FeatureCollection{}}}, Options{}, UnsupportedGeometryError{reflect.TypeOf(geom.FeatureCollection{})}},
+		{geom.FeatureCollection{Features: []geom.T{geom.NewFeature(nil, 1)}}, Options{}, UnsupportedPropertiesError{reflect.TypeOf(1)}},
+	}
+	for _, tc := range testCases {
+		if _, err := Encode(tc.fc, tc.options); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Encode(%#v, %#v) == _, %#v, want %#v", tc.fc, tc.options, err, tc.err)
+		}
+	}
+
+	data, err := Encode(point, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decode([]byte("fgb\x02fgb\x00\x00\x00\x00\x00")); !reflect.DeepEqual(err, FormatError{"unsupported version"}) {
+		t.Errorf("Decode(version 2) == _, %#v", err)
+	}
+	if _, err := Decode([]byte("not flatgeobuf")); !reflect.DeepEqual(err, FormatError{"not a FlatGeobuf file"}) {
+		t.Errorf("Decode(garbage) == _, %#v", err)
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := Decode(data[:i]); err == nil {
+			t.Errorf("Decode(data[:%d]) succeeded", i)
+		}
+	}
+	for i := len(magic); i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0xff
+		Decode(corrupt)
+	}
+}
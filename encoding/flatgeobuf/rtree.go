@@ -0,0 +1,256 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	nodeItemSize = 40
+	hilbertMax   = 1<<16 - 1
+)
+
+// nodeItem is an R-tree node: a bounding box and, for leaves, the byte
+// offset of a feature in the feature data, or for interior nodes the index
+// of the first child.
+type nodeItem struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+func emptyNode() nodeItem {
+	return nodeItem{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1), 0}
+}
+
+func (n nodeItem) empty() bool {
+	return n.maxX < n.minX || n.maxY < n.minY
+}
+
+func (n *nodeItem) expand(other nodeItem) {
+	n.minX = math.Min(n.minX, other.minX)
+	n.minY = math.Min(n.minY, other.minY)
+	n.maxX = math.Max(n.maxX, other.maxX)
+	n.maxY = math.Max(n.maxY, other.maxY)
+}
+
+func (n nodeItem) intersects(other nodeItem) bool {
+	return n.minX <= other.maxX && n.minY <= other.maxY && n.maxX >= other.minX && n.maxY >= other.minY
+}
+
+func (n nodeItem) append(dst []byte) []byte {
+	var b [nodeItemSize]byte
+	for i, v := range []float64{n.minX, n.minY, n.maxX, n.maxY} {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(v))
+	}
+	binary.LittleEndian.PutUint64(b[32:], n.offset)
+	return append(dst, b[:]...)
+}
+
+func readNodeItem(b []byte) nodeItem {
+	f := func(i int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return nodeItem{f(0), f(1), f(2), f(3), binary.LittleEndian.Uint64(b[32:])}
+}
+
+// levelBounds returns the range of node indexes in each level of a tree
+// with numItems leaves, from the leaves up to the root. The root is node 0
+// and the leaves are last.
+func levelBounds(numItems, nodeSize int) [][2]int {
+	n := numItems
+	levelNumNodes := []int{n}
+	numNodes := n
+	// even a single item gets a root above it, as in the reference
+	// implementations
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		levelNumNodes = append(levelNumNodes, n)
+		numNodes += n
+		if n == 1 {
+			break
+		}
+	}
+
+	bounds := make([][2]int, len(levelNumNodes))
+	end := numNodes
+	for i, size := range levelNumNodes {
+		bounds[i] = [2]int{end - size, end}
+		end -= size
+	}
+	return bounds
+}
+
+// indexSize returns the size in bytes of the tree for numItems features.
+func indexSize(numItems, nodeSize int) int {
+	if numItems == 0 || nodeSize < 2 {
+		return 0
+	}
+	levels := levelBounds(numItems, nodeSize)
+	return levels[0][1] * nodeItemSize
+}
+
+// buildIndex returns the encoded tree whose leaves are items, which must
+// already be in Hilbert order.
+func buildIndex(items []nodeItem, nodeSize int) []byte {
+	levels := levelBounds(len(items), nodeSize)
+	nodes := make([]nodeItem, levels[0][1])
+	copy(nodes[levels[0][0]:], items)
+
+	for i := 0; i < len(levels)-1; i++ {
+		children, parents := levels[i], levels[i+1]
+		parent := parents[0]
+		for child := children[0]; child < children[1]; child += nodeSize {
+			node := emptyNode()
+			node.offset = uint64(child)
+			for j := child; j < child+nodeSize && j < children[1]; j++ {
+				node.expand(nodes[j])
+			}
+			nodes[parent] = node
+			parent++
+		}
+	}
+
+	dst := make([]byte, 0, len(nodes)*nodeItemSize)
+	for _, node := range nodes {
+		dst = node.append(dst)
+	}
+	return dst
+}
+
+// hilbert returns the position of (x, y) along a Hilbert curve filling a
+// 2^16 by 2^16 grid.
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00FF00FF
+	i0 = (i0 | (i0 << 4)) & 0x0F0F0F0F
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00FF00FF
+	i1 = (i1 | (i1 << 4)) & 0x0F0F0F0F
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
+
+// hilbertValue returns the Hilbert position of the center of a node within
+// extent. Empty nodes go to the start of the curve.
+func hilbertValue(n, extent nodeItem) uint32 {
+	if n.empty() {
+		return 0
+	}
+	scale := func(v, min, max float64) uint32 {
+		if max <= min {
+			return 0
+		}
+		return uint32(math.Floor(hilbertMax * (v - min) / (max - min)))
+	}
+	x := scale((n.minX+n.maxX)/2, extent.minX, extent.maxX)
+	y := scale((n.minY+n.maxY)/2, extent.minY, extent.maxY)
+	return hilbert(x, y)
+}
+
+// hilbertOrder returns the indexes of items sorted by descending Hilbert
+// value, the order the reference implementations write features in.
+func hilbertOrder(items []nodeItem, extent nodeItem) []int {
+	values := make([]uint32, len(items))
+	order := make([]int, len(items))
+	for i, item := range items {
+		values[i] = hilbertValue(item, extent)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] > values[order[j]]
+	})
+	return order
+}
+
+// searchResult is a feature found by searchIndex: its byte offset in the
+// feature data and its position in the file.
+type searchResult struct {
+	offset uint64
+	index  int
+}
+
+// searchIndex returns the leaves of the tree stored at offset in r whose
+// boxes intersect query, in file order.
+func searchIndex(r io.ReaderAt, offset int64, numItems, nodeSize int, query nodeItem) ([]searchResult, error) {
+	levels := levelBounds(numItems, nodeSize)
+	leavesStart := levels[0][0]
+	numNodes := levels[0][1]
+
+	type entry struct {
+		node, level int
+	}
+	queue := []entry{{0, len(levels) - 1}}
+	results := []searchResult{}
+	buf := make([]byte, nodeSize*nodeItemSize)
+	for len(queue) != 0 {
+		next := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		end := next.node + nodeSize
+		if levelEnd := levels[next.level][1]; end > levelEnd {
+			end = levelEnd
+		}
+		b := buf[:(end-next.node)*nodeItemSize]
+		if _, err := r.ReadAt(b, offset+int64(next.node)*nodeItemSize); err != nil {
+			return nil, err
+		}
+
+		for i := next.node; i < end; i++ {
+			node := readNodeItem(b[(i-next.node)*nodeItemSize:])
+			if !query.intersects(node) {
+				continue
+			}
+			if next.node >= leavesStart {
+				results = append(results, searchResult{node.offset, i - leavesStart})
+			} else {
+				child := int(node.offset)
+				if child < 0 || child >= numNodes || next.level == 0 {
+					return nil, FormatError{"invalid index node"}
+				}
+				queue = append(queue, entry{child, next.level - 1})
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].index < results[j].index
+	})
+	return results, nil
+}
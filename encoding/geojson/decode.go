@@ -56,6 +56,51 @@ func decodeCoordinates3(jsonCoordinates interface{}) [][][]float64 {
 	return coordinates
 }
 
+func decodeCoordinates4(jsonCoordinates interface{}) [][][][]float64 {
+	array, ok := jsonCoordinates.([]interface{})
+	if !ok {
+		panic(&InvalidGeometryError{})
+	}
+
+	coordinates := make([][][][]float64, len(array))
+	for i, element := range array {
+		coordinates[i] = decodeCoordinates3(element)
+	}
+
+	return coordinates
+}
+
+// decodeGeometries accepts the members of a GeometryCollection either as
+// built by ToGeoJSON or as decoded by encoding/json.
+func decodeGeometries(jsonGeometries interface{}) []Geometry {
+	switch g := jsonGeometries.(type) {
+	case []Geometry:
+		return g
+	case []interface{}:
+		geometries := make([]Geometry, len(g))
+		for i, element := range g {
+			object, ok := element.(map[string]interface{})
+			if !ok {
+				panic(&InvalidGeometryError{})
+			}
+
+			geometryType, ok := object["type"].(string)
+			if !ok {
+				panic(&InvalidGeometryError{})
+			}
+
+			geometries[i] = Geometry{
+				Type:        geometryType,
+				Coordinates: object["coordinates"],
+				Geometries:  object["geometries"],
+			}
+		}
+		return geometries
+	default:
+		panic(&InvalidGeometryError{})
+	}
+}
+
 func makeLinearRing(coordinates [][]float64) []geom.Point {
 	points := make([]geom.Point, len(coordinates))
 
@@ -101,6 +146,38 @@ func doFromGeoJSON(g Geometry) geom.T {
 
 		rings := makeLinearRings(coordinates)
 		return geom.Polygon(rings)
+	case "MultiPoint":
+		coordinates := decodeCoordinates2(g.Coordinates)
+		return geom.MultiPoint(makeLinearRing(coordinates))
+	case "MultiLineString":
+		coordinates := decodeCoordinates3(g.Coordinates)
+		multiLineString := make(geom.MultiLineString, len(coordinates))
+		for i, element := range coordinates {
+			multiLineString[i] = makeLinearRing(element)
+		}
+
+		if err := validateMultiLineString(multiLineString); err != nil {
+			panic(err)
+		}
+		return multiLineString
+	case "MultiPolygon":
+		coordinates := decodeCoordinates4(g.Coordinates)
+		multiPolygon := make(geom.MultiPolygon, len(coordinates))
+		for i, element := range coordinates {
+			multiPolygon[i] = makeLinearRings(element)
+		}
+
+		if err := validateMultiPolygon(multiPolygon); err != nil {
+			panic(err)
+		}
+		return multiPolygon
+	case "GeometryCollection":
+		geometries := decodeGeometries(g.Geometries)
+		geometryCollection := make(geom.GeometryCollection, len(geometries))
+		for i, geometry := range geometries {
+			geometryCollection[i] = doFromGeoJSON(geometry)
+		}
+		return geometryCollection
 	default:
 		panic(&UnsupportedGeometryError{g.Type})
 	}
@@ -116,7 +193,101 @@ func FromGeoJSON(geom Geometry) (g geom.T, err error) {
 	return doFromGeoJSON(geom), nil
 }
 
+func decodeBBox(bbox []float64) geom.Bounds {
+	elementCount := len(bbox)
+	if elementCount == 0 {
+		return geom.Bounds{}
+	}
+	if elementCount < 4 || elementCount%2 != 0 {
+		panic(&InvalidBBoxError{elementCount})
+	}
+
+	dimension := elementCount / 2
+	return geom.Bounds{
+		Min: geom.Point(bbox[:dimension]),
+		Max: geom.Point(bbox[dimension:]),
+	}
+}
+
+func doFeatureFromGeoJSON(f Feature) geom.Feature {
+	if f.Type != "Feature" {
+		panic(&UnsupportedGeometryError{f.Type})
+	}
+
+	var t geom.T
+	if f.Geometry.Type != "" {
+		t = doFromGeoJSON(f.Geometry)
+	}
+
+	return geom.Feature{
+		T:          t,
+		Properties: f.Properties,
+		ID:         f.ID,
+		BBox:       decodeBBox(f.BBox),
+	}
+}
+
+// FeatureFromGeoJSON converts a decoded Feature. A null geometry becomes a
+// nil T, and properties are kept as decoded by encoding/json.
+func FeatureFromGeoJSON(f Feature) (feature geom.Feature, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			feature = geom.Feature{}
+			err = e.(error)
+		}
+	}()
+	return doFeatureFromGeoJSON(f), nil
+}
+
+func FeatureCollectionFromGeoJSON(fc FeatureCollection) (collection geom.FeatureCollection, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			collection = geom.FeatureCollection{}
+			err = e.(error)
+		}
+	}()
+
+	if fc.Type != "FeatureCollection" {
+		panic(&UnsupportedGeometryError{fc.Type})
+	}
+
+	features := make([]geom.T, len(fc.Features))
+	for i, f := range fc.Features {
+		features[i] = doFeatureFromGeoJSON(f)
+	}
+
+	return geom.FeatureCollection{
+		Features:   features,
+		Properties: fc.Properties,
+		BBox:       decodeBBox(fc.BBox),
+	}, nil
+}
+
+// Decode accepts a geometry, a Feature or a FeatureCollection, returning
+// geom.Feature and geom.FeatureCollection for the latter two.
 func Decode(data []byte) (geom.T, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	switch object.Type {
+	case "Feature":
+		if feature, err := DecodeFeature(data); err == nil {
+			return feature, nil
+		} else {
+			return nil, err
+		}
+	case "FeatureCollection":
+		if collection, err := DecodeFeatureCollection(data); err == nil {
+			return collection, nil
+		} else {
+			return nil, err
+		}
+	}
+
 	var geom Geometry
 	if err := json.Unmarshal(data, &geom); err == nil {
 		return FromGeoJSON(geom)
@@ -124,3 +295,19 @@ func Decode(data []byte) (geom.T, error) {
 		return nil, err
 	}
 }
+
+func DecodeFeature(data []byte) (geom.Feature, error) {
+	var f Feature
+	if err := json.Unmarshal(data, &f); err != nil {
+		return geom.Feature{}, err
+	}
+	return FeatureFromGeoJSON(f)
+}
+
+func DecodeFeatureCollection(data []byte) (geom.FeatureCollection, error) {
+	var fc FeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return geom.FeatureCollection{}, err
+	}
+	return FeatureCollectionFromGeoJSON(fc)
+}
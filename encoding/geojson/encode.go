@@ -7,13 +7,30 @@ import (
 	"github.com/foobaz/geom"
 )
 
+// ToGeoJSON returns the GeoJSON object for t, ready to be marshalled.
+// SRIDs and Layouts are dropped.
 func ToGeoJSON(t geom.T) (interface{}, error) {
 	switch g := t.(type) {
 	case geom.Point:
+		err := validatePoint(g)
+		if err != nil {
+			return nil, err
+		}
+
 		return Geometry{
 			Type:        "Point",
 			Coordinates: g,
 		}, nil
+	case geom.MultiPoint:
+		err := validateMultiPoint(g)
+		if err != nil {
+			return nil, err
+		}
+
+		return Geometry{
+			Type:        "MultiPoint",
+			Coordinates: g,
+		}, nil
 	case geom.LineString:
 		err := validateLineString(g)
 		if err != nil {
@@ -54,39 +71,69 @@ func ToGeoJSON(t geom.T) (interface{}, error) {
 			Type:        "MultiPolygon",
 			Coordinates: g,
 		}, nil
-	case geom.Feature:
-		serializable, err := ToGeoJSON(g.T)
-		if err != nil {
-			return nil, err
+	case geom.GeometryCollection:
+		geometries := make([]Geometry, len(g))
+		for i, t := range g {
+			serializable, err := ToGeoJSON(t)
+			if err != nil {
+				return nil, err
+			}
+
+			geometry, ok := serializable.(Geometry)
+			if !ok {
+				return nil, &UnsupportedGeometryError{reflect.TypeOf(t).String()}
+			}
+
+			geometries[i] = geometry
 		}
 
-		geometry, ok := serializable.(Geometry)
-		if !ok {
-			return nil, &UnsupportedGeometryError{reflect.TypeOf(geometry).String()}
+		return Geometry{
+			Type:       "GeometryCollection",
+			Geometries: geometries,
+		}, nil
+	case geom.Triangle, geom.TIN, geom.PolyhedralSurface:
+		return ToGeoJSON(exportSurface(g))
+	case geom.SRIDGeometry, geom.LayoutGeometry:
+		return ToGeoJSON(geom.Unwrap(g))
+	case geom.Feature:
+		var geometry Geometry
+		if g.T != nil {
+			serializable, err := ToGeoJSON(g.T)
+			if err != nil {
+				return nil, err
+			}
+
+			var ok bool
+			if geometry, ok = serializable.(Geometry); !ok {
+				return nil, &UnsupportedGeometryError{reflect.TypeOf(serializable).String()}
+			}
 		}
 
 		return Feature{
 			Type:       "Feature",
+			ID:         g.ID,
+			BBox:       encodeBBox(g.BBox),
 			Geometry:   geometry,
 			Properties: g.Properties,
 		}, nil
 	case geom.FeatureCollection:
-		features := make([]Feature, 0, len(g.Features))
-		for _, geomFeature := range g.Features {
-			serializable, err := ToGeoJSON(geomFeature)
-			if err != nil {
-				continue
+		features := make([]Feature, len(g.Features))
+		for i, t := range g.Features {
+			geomFeature, ok := t.(geom.Feature)
+			if !ok {
+				geomFeature = geom.NewFeature(t, nil)
 			}
 
-			jsonFeature, ok := serializable.(Feature)
-			if !ok {
-				continue
+			serializable, err := ToGeoJSON(geomFeature)
+			if err != nil {
+				return nil, err
 			}
 
-			features = append(features, jsonFeature)
+			features[i] = serializable.(Feature)
 		}
 		return FeatureCollection{
 			Type:       "FeatureCollection",
+			BBox:       encodeBBox(g.BBox),
 			Features:   features,
 			Properties: g.Properties,
 		}, nil
@@ -103,6 +150,65 @@ func Encode(g geom.T) ([]byte, error) {
 	}
 }
 
+// exportSurface returns the Polygon, or GeometryCollection of Polygons,
+// that stands for a Triangle, TIN or PolyhedralSurface, which GeoJSON
+// lacks. Other geometries are returned unchanged.
+func exportSurface(t geom.T) geom.T {
+	switch g := t.(type) {
+	case geom.Triangle:
+		return geom.Polygon(g)
+	case geom.TIN:
+		polygons := make(geom.GeometryCollection, len(g))
+		for i, triangle := range g {
+			polygons[i] = geom.Polygon(triangle)
+		}
+		return polygons
+	case geom.PolyhedralSurface:
+		polygons := make(geom.GeometryCollection, len(g))
+		for i, polygon := range g {
+			polygons[i] = polygon
+		}
+		return polygons
+	}
+	return t
+}
+
+func encodeBBox(b geom.Bounds) []float64 {
+	if b.IsZero() {
+		return nil
+	}
+
+	dimension := len(b.Min)
+	if len(b.Max) < dimension {
+		dimension = len(b.Max)
+	}
+
+	bbox := make([]float64, 0, 2*dimension)
+	bbox = append(bbox, b.Min[:dimension]...)
+	bbox = append(bbox, b.Max[:dimension]...)
+	return bbox
+}
+
+func validatePoint(p geom.Point) error {
+	elementCount := len(p)
+	if elementCount < 2 {
+		return InsufficientElementsError{elementCount}
+	}
+
+	return nil
+}
+
+func validateMultiPoint(m geom.MultiPoint) error {
+	for _, p := range m {
+		err := validatePoint(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func validateLineString(l geom.LineString) error {
 	pointCount := len(l)
 	if pointCount < 2 {
@@ -1,22 +1,66 @@
 package geojson
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
+// Geometries holds the members of a GeometryCollection, either as
+// []Geometry when encoding or as decoded JSON when decoding.
 type Geometry struct {
 	Type        string      `json:"type"`
-	Coordinates interface{} `json:"coordinates"`
+	BBox        []float64   `json:"bbox,omitempty"`
+	Coordinates interface{} `json:"coordinates,omitempty"`
+	Geometries  interface{} `json:"geometries,omitempty"`
 }
 
+// A Feature whose Geometry has no Type has a null geometry.
 type Feature struct {
-	Type       string `json:"type"`
+	Type       string      `json:"type"`
+	ID         interface{} `json:"id,omitempty"`
+	BBox       []float64   `json:"bbox,omitempty"`
 	Geometry   `json:"geometry"`
 	Properties interface{} `json:"properties,omitempty"`
 }
 
+// feature is the JSON form of a Feature, with a null geometry as nil. Nil
+// properties are written as null, as the properties member is required.
+type feature struct {
+	Type       string      `json:"type"`
+	ID         interface{} `json:"id,omitempty"`
+	BBox       []float64   `json:"bbox,omitempty"`
+	Geometry   *Geometry   `json:"geometry"`
+	Properties interface{} `json:"properties"`
+}
+
+func (f Feature) MarshalJSON() ([]byte, error) {
+	j := feature{f.Type, f.ID, f.BBox, nil, f.Properties}
+	if f.Geometry.Type != "" {
+		j.Geometry = &f.Geometry
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a null geometry to a Geometry with no Type. A
+// geometry object without a type is an InvalidGeometryError.
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	var j feature
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*f = Feature{Type: j.Type, ID: j.ID, BBox: j.BBox, Properties: j.Properties}
+	if j.Geometry != nil {
+		if j.Geometry.Type == "" {
+			return &InvalidGeometryError{}
+		}
+		f.Geometry = *j.Geometry
+	}
+	return nil
+}
+
 type FeatureCollection struct {
 	Type       string      `json:"type"`
+	BBox       []float64   `json:"bbox,omitempty"`
 	Features   []Feature   `json:"features"`
 	Properties interface{} `json:"properties,omitempty"`
 }
@@ -50,3 +94,11 @@ type InsufficientPointsError struct {
 func (e InsufficientPointsError) Error() string {
 	return fmt.Sprintf("geojson: need more than %d points", e.PointCount)
 }
+
+type InvalidBBoxError struct {
+	ElementCount int
+}
+
+func (e InvalidBBoxError) Error() string {
+	return fmt.Sprintf("geojson: bbox needs an even number of at least four elements, got %d", e.ElementCount)
+}
@@ -1,8 +1,11 @@
 package geojson
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/foobaz/geom"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -47,6 +50,26 @@ func TestGeoJSON(t *testing.T) {
 			geom.LineString{{1, 2}, {3, 4, 5}},
 			[]byte(`{"type":"LineString","coordinates":[[1,2],[3,4,5]]}`),
 		},
+		{
+			geom.MultiPoint{{1, 2}, {3, 4}},
+			[]byte(`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`),
+		},
+		{
+			geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+			[]byte(`{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]]}`),
+		},
+		{
+			geom.MultiPolygon{{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}}, {{{7, 8}, {9, 10}, {11, 12}, {7, 8}}}},
+			[]byte(`{"type":"MultiPolygon","coordinates":[[[[1,2],[3,4],[5,6],[1,2]]],[[[7,8],[9,10],[11,12],[7,8]]]]}`),
+		},
+		{
+			geom.GeometryCollection{geom.Point{1, 2}, geom.GeometryCollection{geom.LineString{{1, 2}, {3, 4}}}},
+			[]byte(`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[1,2],[3,4]]}]}]}`),
+		},
+		{
+			geom.GeometryCollection{},
+			[]byte(`{"type":"GeometryCollection","geometries":[]}`),
+		},
 	}
 	for _, tc := range testCases {
 		if got, err := Encode(tc.g); err != nil || !reflect.DeepEqual(got, tc.geoJSON) {
@@ -77,6 +100,15 @@ func TestGeoJSONDecode(t *testing.T) {
 		[]byte(`{"coordinates":[[[1,2],[3,4,5]]],"type":"Polygon"}`),
 		[]byte(`{"type":"Polygon","coordinates":[[[1,2,3],[4,5,6],[7,8,9]]]}`),
 		[]byte(`{"type":"Polygon","coordinates":[[[1,2],[3,4],[5,6]]]}`),
+		[]byte(`{"type":"MultiPoint","coordinates":[[1]]}`),
+		[]byte(`{"type":"MultiPoint","coordinates":[1,2]}`),
+		[]byte(`{"type":"MultiLineString","coordinates":[[[1,2]]]}`),
+		[]byte(`{"type":"MultiLineString","coordinates":[[1,2],[3,4]]}`),
+		[]byte(`{"type":"MultiPolygon","coordinates":[[]]}`),
+		[]byte(`{"type":"MultiPolygon","coordinates":[[[[1,2],[3,4],[1,2]]]]}`),
+		[]byte(`{"type":"GeometryCollection"}`),
+		[]byte(`{"type":"GeometryCollection","geometries":[1]}`),
+		[]byte(`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1]}]}`),
 	}
 	for _, tc := range testCases {
 		if got, err := Decode(tc); err == nil {
@@ -84,3 +116,334 @@ func TestGeoJSONDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestGeoJSONEncodeError(t *testing.T) {
+	testCases := []geom.T{
+		geom.Point{1},
+		geom.MultiPoint{{1, 2}, {3}},
+		geom.GeometryCollection{geom.LineString{{1, 2}}},
+		geom.GeometryCollection{geom.NewFeature(geom.Point{1, 2}, nil)},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc); err == nil {
+			t.Errorf("Encode(%#v) == %s, %v, want err != nil", tc, string(got), err)
+		}
+	}
+}
+
+func TestGeoJSONWrapped(t *testing.T) {
+	var testCases = []struct {
+		g       geom.T
+		geoJSON string
+	}{
+		{geom.NewSRIDGeometry(geom.Point{1, 2}, 4326), `{"type":"Point","coordinates":[1,2]}`},
+		{geom.NewLayoutGeometry(geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326), geom.XYZ), `{"type":"Point","coordinates":[1,2,3]}`},
+		{geom.GeometryCollection{geom.NewSRIDGeometry(geom.Point{1, 2}, 4326)}, `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}`},
+		{geom.NewFeature(geom.NewSRIDGeometry(geom.Point{1, 2}, 4326), nil), `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}`},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g); err != nil || string(got) != tc.geoJSON {
+			t.Errorf("Encode(%#v) == %s, %v, want %s, nil", tc.g, got, err, tc.geoJSON)
+		}
+	}
+}
+
+func TestGeoJSONSurface(t *testing.T) {
+	testCases := []struct {
+		g       geom.T
+		geoJSON []byte
+	}{
+		{
+			geom.Triangle{{{0, 0, 0}, {1, 0, 0}, {0, 1, 1}, {0, 0, 0}}},
+			[]byte(`{"type":"Polygon","coordinates":[[[0,0,0],[1,0,0],[0,1,1],[0,0,0]]]}`),
+		},
+		{
+			geom.TIN{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}, {{{1, 0}, {1, 1}, {0, 1}, {1, 0}}}},
+			[]byte(`{"type":"GeometryCollection","geometries":[` +
+				`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1],[0,0]]]},` +
+				`{"type":"Polygon","coordinates":[[[1,0],[1,1],[0,1],[1,0]]]}]}`),
+		},
+		{
+			geom.PolyhedralSurface{{{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 0}}}},
+			[]byte(`{"type":"GeometryCollection","geometries":[{"type":"Polygon","coordinates":[[[0,0,0],[0,1,0],[1,1,0],[0,0,0]]]}]}`),
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g); err != nil || !reflect.DeepEqual(got, tc.geoJSON) {
+			t.Errorf("Encode(%#v) == %s, %#v, want %s, nil", tc.g, string(got), err, string(tc.geoJSON))
+		}
+	}
+
+	// Options apply to the exported polygons.
+	g := geom.TIN{{{{0, 0}, {1.04, 0}, {0, 1}, {0, 0}}}}
+	want := []byte(`{"type":"GeometryCollection","geometries":[{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1],[0,0]]]}]}`)
+	if got, err := (EncodeOptions{Precision: 1}).Encode(g); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeOptions{Precision: 1}.Encode(%#v) == %s, %v, want %s, nil", g, got, err, want)
+	}
+}
+
+func TestGeoJSONFeature(t *testing.T) {
+	testCases := []struct {
+		g       geom.T
+		geoJSON []byte
+	}{
+		{
+			geom.Feature{
+				T:          geom.Point{1, 2},
+				Properties: map[string]interface{}{"name": "a", "value": 1.5},
+				ID:         "f1",
+				BBox:       geom.Bounds{Min: geom.Point{1, 2}, Max: geom.Point{1, 2}},
+			},
+			[]byte(`{"type":"Feature","id":"f1","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a","value":1.5}}`),
+		},
+		{
+			geom.Feature{
+				Properties: map[string]interface{}{"nested": []interface{}{"x", true}},
+				ID:         float64(7),
+			},
+			[]byte(`{"type":"Feature","id":7,"geometry":null,"properties":{"nested":["x",true]}}`),
+		},
+		{
+			geom.FeatureCollection{
+				Features: []geom.T{
+					geom.NewFeature(geom.LineString{{1, 2}, {3, 4}}, map[string]interface{}{"name": "b"}),
+				},
+				BBox: geom.Bounds{Min: geom.Point{1, 2, 0}, Max: geom.Point{3, 4, 0}},
+			},
+			[]byte(`{"type":"FeatureCollection","bbox":[1,2,0,3,4,0],"features":[{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]},"properties":{"name":"b"}}]}`),
+		},
+		{
+			geom.FeatureCollection{
+				Features: []geom.T{},
+			},
+			[]byte(`{"type":"FeatureCollection","features":[]}`),
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g); err != nil || !reflect.DeepEqual(got, tc.geoJSON) {
+			t.Errorf("Encode(%#v) == %s, %#v, want %s, nil", tc.g, string(got), err, string(tc.geoJSON))
+		}
+		if got, err := Decode(tc.geoJSON); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %#v, want %#v, nil", string(tc.geoJSON), got, err, tc.g)
+		}
+	}
+
+	// Feature embeds its Geometry by value; a zero Geometry is null. Nil
+	// properties are written as null, as RFC 7946 requires the member.
+	var zero Feature
+	if got, err := json.Marshal(zero); err != nil || string(got) != `{"type":"","geometry":null,"properties":null}` {
+		t.Errorf("json.Marshal(Feature{}) == %s, %v", got, err)
+	}
+	f := Feature{Type: "Feature", Geometry: Geometry{Type: "Point", Coordinates: []float64{1, 2}}}
+	want := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}`
+	if got, err := json.Marshal(f); err != nil || string(got) != want {
+		t.Errorf("json.Marshal(%#v) == %s, %v, want %s", f, got, err, want)
+	}
+	var decoded Feature
+	if err := json.Unmarshal([]byte(want), &decoded); err != nil || decoded.Geometry.Type != "Point" {
+		t.Errorf("json.Unmarshal(%s) == %#v, %v", want, decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"Feature","geometry":{}}`), &decoded); !reflect.DeepEqual(err, &InvalidGeometryError{}) {
+		t.Errorf("json.Unmarshal of an untyped geometry == %#v", err)
+	}
+}
+
+func TestGeoJSONFeatureError(t *testing.T) {
+	decodeCases := [][]byte{
+		[]byte(`{"type":"Feature","bbox":[1,2,3],"geometry":null}`),
+		[]byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1]}}`),
+		[]byte(`{"type":"FeatureCollection","features":[{"type":"Point","coordinates":[1,2]}]}`),
+		[]byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Circle"}}]}`),
+	}
+	for _, tc := range decodeCases {
+		if got, err := Decode(tc); err == nil {
+			t.Errorf("Decode(%s) == %#v, %v, want err != nil", string(tc), got, err)
+		}
+	}
+
+	// features that fail to encode are reported rather than skipped
+	fc := geom.FeatureCollection{
+		Features: []geom.T{
+			geom.NewFeature(geom.Point{1, 2}, nil),
+			geom.NewFeature(geom.LineString{{1, 2}}, nil),
+		},
+	}
+	if got, err := Encode(fc); err == nil {
+		t.Errorf("Encode(%#v) == %s, %v, want err != nil", fc, string(got), err)
+	}
+}
+
+func TestFeatureStream(t *testing.T) {
+	features := []geom.Feature{
+		geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"name": "a"}),
+		{T: geom.LineString{{1, 2}, {3, 4}}, ID: "b"},
+		geom.NewFeature(nil, nil),
+	}
+
+	var collection bytes.Buffer
+	var sequence bytes.Buffer
+	writers := []*FeatureWriter{NewFeatureWriter(&collection), NewFeatureSequenceWriter(&sequence)}
+	for _, w := range writers {
+		for _, f := range features {
+			if err := w.Write(f); err != nil {
+				t.Fatalf("Write(%#v) == %v, want nil", f, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() == %v, want nil", err)
+		}
+	}
+
+	wantCollection := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},{"type":"Feature","id":"b","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]},"properties":null},{"type":"Feature","geometry":null,"properties":null}]}`
+	if got := collection.String(); got != wantCollection {
+		t.Errorf("FeatureWriter wrote %s, want %s", got, wantCollection)
+	}
+	if got, err := DecodeFeatureCollection(collection.Bytes()); err != nil || len(got.Features) != len(features) {
+		t.Errorf("DecodeFeatureCollection(%s) == %#v, %v", collection.String(), got, err)
+	}
+	if got := bytes.Count(sequence.Bytes(), []byte{recordSeparator}); got != len(features) {
+		t.Errorf("FeatureSequenceWriter wrote %d record separators, want %d", got, len(features))
+	}
+
+	// members may come in any order around the features array
+	reordered := `{"features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},{"type":"Feature","id":"b","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]}},{"type":"Feature","geometry":null}],"crs":{"type":"name"},"type":"FeatureCollection"}`
+	newlineDelimited := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}}
+{"type":"Feature","id":"b","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]}}
+{"type":"Feature","geometry":null}
+`
+	readers := []*FeatureReader{
+		NewFeatureReader(&collection),
+		NewFeatureReader(strings.NewReader(reordered)),
+		NewFeatureSequenceReader(&sequence),
+		NewFeatureSequenceReader(strings.NewReader(newlineDelimited)),
+	}
+	for i, r := range readers {
+		var got []geom.Feature
+		for r.Next() {
+			got = append(got, r.Feature())
+		}
+		if err := r.Err(); err != nil || !reflect.DeepEqual(got, features) {
+			t.Errorf("reader %d read %#v, %v, want %#v, nil", i, got, err, features)
+		}
+	}
+}
+
+func TestFeatureSequenceTruncated(t *testing.T) {
+	point := `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}`
+	sequence := "\x1e" + point + "\n" +
+		"\x1e" + `{"type":"Feature","geometry":{"type":"Po` +
+		"\x1e" + point + "\n" +
+		"\x1e" + `{"type":"Feature","geometry":null`
+	r := NewFeatureSequenceReader(strings.NewReader(sequence))
+	n := 0
+	for r.Next() {
+		n++
+	}
+	if err := r.Err(); err != nil || n != 2 || r.Skipped() != 2 {
+		t.Errorf("FeatureSequenceReader read %d features, skipped %d, %v, want 2, 2, nil", n, r.Skipped(), err)
+	}
+
+	r = NewFeatureSequenceReader(strings.NewReader("\x1e" + `{"type":"Feature","geometry":1}` + "\n"))
+	for r.Next() {
+	}
+	if r.Err() == nil {
+		t.Errorf("FeatureSequenceReader with an invalid feature: Err() == nil, want err != nil")
+	}
+}
+
+func TestFeatureReaderError(t *testing.T) {
+	testCases := []string{
+		``,
+		`[]`,
+		`{"type":"Feature","features":[]}`,
+		`{"features":[]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Point","coordinates":[1,2]}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null}`,
+	}
+	for _, tc := range testCases {
+		r := NewFeatureReader(strings.NewReader(tc))
+		for r.Next() {
+		}
+		if r.Err() == nil {
+			t.Errorf("FeatureReader(%s).Err() == nil, want err != nil", tc)
+		}
+	}
+}
+
+func TestEncodeOptions(t *testing.T) {
+	testCases := []struct {
+		options EncodeOptions
+		g       geom.T
+		geoJSON []byte
+	}{
+		{
+			EncodeOptions{},
+			geom.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+			[]byte(`{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}`),
+		},
+		{
+			EncodeOptions{RightHandRule: true},
+			geom.Polygon{{{0, 0}, {0, 3}, {3, 3}, {3, 0}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}}},
+			[]byte(`{"type":"Polygon","coordinates":[[[0,0],[3,0],[3,3],[0,3],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`),
+		},
+		{
+			EncodeOptions{Precision: 2},
+			geom.LineString{{1.23456, -2.34567, 100.555}, {3.005, 4}},
+			[]byte(`{"type":"LineString","coordinates":[[1.23,-2.35,100.56],[3.01,4]]}`),
+		},
+		{
+			EncodeOptions{ExplicitPrecision: true},
+			geom.LineString{{1.23456, -2.6}, {3.5, 4}},
+			[]byte(`{"type":"LineString","coordinates":[[1,-3],[4,4]]}`),
+		},
+		{
+			EncodeOptions{},
+			geom.LineString{{1.5, 2}, {3, 4}},
+			[]byte(`{"type":"LineString","coordinates":[[1.5,2],[3,4]]}`),
+		},
+		{
+			EncodeOptions{BBox: true},
+			geom.LineString{{1, 2}, {3, -4}},
+			[]byte(`{"type":"LineString","bbox":[1,-4,3,2],"coordinates":[[1,2],[3,-4]]}`),
+		},
+		{
+			EncodeOptions{BBox: true},
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.Point{1, 2}, nil),
+				geom.NewFeature(geom.Point{3, 4}, nil),
+			}},
+			[]byte(`{"type":"FeatureCollection","bbox":[1,2,3,4],"features":[{"type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":null},{"type":"Feature","bbox":[3,4,3,4],"geometry":{"type":"Point","coordinates":[3,4]},"properties":null}]}`),
+		},
+		{
+			EncodeOptions{SplitAntimeridian: true},
+			geom.LineString{{170, 10}, {-170, 20}},
+			[]byte(`{"type":"MultiLineString","coordinates":[[[170,10],[180,15]],[[-180,15],[-170,20]]]}`),
+		},
+		{
+			EncodeOptions{SplitAntimeridian: true},
+			geom.LineString{{-170, 10}, {170, 20}, {160, 20}},
+			[]byte(`{"type":"MultiLineString","coordinates":[[[-170,10],[-180,15]],[[180,15],[170,20],[160,20]]]}`),
+		},
+		{
+			EncodeOptions{SplitAntimeridian: true},
+			geom.Polygon{{{170, 0}, {-170, 0}, {-170, 10}, {170, 10}, {170, 0}}},
+			[]byte(`{"type":"MultiPolygon","coordinates":[[[[170,0],[180,0],[180,10],[170,10],[170,0]]],[[[-180,0],[-170,0],[-170,10],[-180,10],[-180,0]]]]}`),
+		},
+		{
+			EncodeOptions{SplitAntimeridian: true},
+			geom.Polygon{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}},
+			[]byte(`{"type":"Polygon","coordinates":[[[10,0],[20,0],[20,10],[10,10],[10,0]]]}`),
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := tc.options.Encode(tc.g); err != nil || !reflect.DeepEqual(got, tc.geoJSON) {
+			t.Errorf("%#v.Encode(%#v) == %s, %#v, want %s, nil", tc.options, tc.g, string(got), err, string(tc.geoJSON))
+		}
+	}
+
+	// the input must not be modified
+	polygon := geom.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}
+	if _, err := RFC7946.Encode(polygon); err != nil || !reflect.DeepEqual(polygon, geom.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}) {
+		t.Errorf("RFC7946.Encode modified its input to %#v, %v", polygon, err)
+	}
+}
@@ -0,0 +1,402 @@
+package geojson
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+// EncodeOptions makes encoded GeoJSON comply with RFC 7946. The zero value
+// encodes geometries unchanged, like Encode. The geometries passed in are
+// never modified.
+type EncodeOptions struct {
+	// RightHandRule makes exterior rings counter-clockwise and holes
+	// clockwise.
+	RightHandRule bool
+	// SplitAntimeridian cuts lines and polygons that cross the antimeridian
+	// into parts on either side of it, so LineStrings may become
+	// MultiLineStrings and Polygons may become MultiPolygons.
+	SplitAntimeridian bool
+	// Precision, if positive, rounds coordinates to that many decimal
+	// places.
+	Precision int
+	// ExplicitPrecision means Precision is used as given, so that a
+	// precision of zero rounds coordinates to integers.
+	ExplicitPrecision bool
+	// BBox adds a bbox member, computed from geom.Bounds, to the encoded
+	// object and to every Feature in a FeatureCollection.
+	BBox bool
+}
+
+// RFC7946 enables every option, rounding to the six decimal places (about
+// 10 centimeters) suggested by the RFC.
+var RFC7946 = EncodeOptions{
+	RightHandRule:     true,
+	SplitAntimeridian: true,
+	Precision:         6,
+	BBox:              true,
+}
+
+func (o EncodeOptions) ToGeoJSON(t geom.T) (interface{}, error) {
+	t = o.transform(t)
+	object, err := ToGeoJSON(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.BBox {
+		object = addBBox(object, t)
+	}
+	return object, nil
+}
+
+func (o EncodeOptions) Encode(t geom.T) ([]byte, error) {
+	if object, err := o.ToGeoJSON(t); err == nil {
+		return json.Marshal(object)
+	} else {
+		return nil, err
+	}
+}
+
+func (o EncodeOptions) transform(t geom.T) geom.T {
+	t = exportSurface(t)
+	switch g := t.(type) {
+	case geom.Feature:
+		if g.T != nil {
+			g.T = o.transform(g.T)
+		}
+		return g
+	case geom.FeatureCollection:
+		features := make([]geom.T, len(g.Features))
+		for i, feature := range g.Features {
+			features[i] = o.transform(feature)
+		}
+		g.Features = features
+		return g
+	case geom.GeometryCollection:
+		geometries := make(geom.GeometryCollection, len(g))
+		for i, t := range g {
+			geometries[i] = o.transform(t)
+		}
+		return geometries
+	}
+
+	if o.SplitAntimeridian {
+		t = splitAntimeridian(t)
+	}
+	if o.RightHandRule {
+		t = rightHandRule(t)
+	}
+	if o.Precision > 0 || o.ExplicitPrecision {
+		scale := math.Pow(10, float64(o.Precision))
+		t = mapPoints(t, func(p geom.Point) geom.Point {
+			rounded := make(geom.Point, len(p))
+			for i, c := range p {
+				rounded[i] = math.Round(c*scale) / scale
+			}
+			return rounded
+		})
+	}
+	return t
+}
+
+func boundsBBox(t geom.T) []float64 {
+	b := t.Bounds(geom.NewBounds())
+	if b.IsZero() || b.Empty() {
+		return nil
+	}
+	return encodeBBox(b)
+}
+
+func addBBox(object interface{}, t geom.T) interface{} {
+	switch o := object.(type) {
+	case Geometry:
+		o.BBox = boundsBBox(t)
+		return o
+	case Feature:
+		o.BBox = boundsBBox(t)
+		return o
+	case FeatureCollection:
+		collection := t.(geom.FeatureCollection)
+		features := make([]Feature, len(o.Features))
+		for i, f := range o.Features {
+			features[i] = addBBox(f, collection.Features[i]).(Feature)
+		}
+		o.Features = features
+		o.BBox = boundsBBox(t)
+		return o
+	default:
+		return object
+	}
+}
+
+// mapPoints returns a copy of t with f applied to every point.
+func mapPoints(t geom.T, f func(geom.Point) geom.Point) geom.T {
+	mapRing := func(points []geom.Point) []geom.Point {
+		mapped := make([]geom.Point, len(points))
+		for i, p := range points {
+			mapped[i] = f(p)
+		}
+		return mapped
+	}
+	mapPolygon := func(polygon geom.Polygon) geom.Polygon {
+		mapped := make(geom.Polygon, len(polygon))
+		for i, ring := range polygon {
+			mapped[i] = mapRing(ring)
+		}
+		return mapped
+	}
+
+	switch g := t.(type) {
+	case geom.Point:
+		return f(g)
+	case geom.MultiPoint:
+		return geom.MultiPoint(mapRing(g))
+	case geom.LineString:
+		return geom.LineString(mapRing(g))
+	case geom.MultiLineString:
+		mapped := make(geom.MultiLineString, len(g))
+		for i, lineString := range g {
+			mapped[i] = mapRing(lineString)
+		}
+		return mapped
+	case geom.Polygon:
+		return mapPolygon(g)
+	case geom.MultiPolygon:
+		mapped := make(geom.MultiPolygon, len(g))
+		for i, polygon := range g {
+			mapped[i] = mapPolygon(polygon)
+		}
+		return mapped
+	default:
+		return t
+	}
+}
+
+func rightHandRulePolygon(polygon geom.Polygon) geom.Polygon {
+	oriented := make(geom.Polygon, len(polygon))
+	for i, ring := range polygon {
+		a := planar.SignedArea(ring)
+		if (i == 0 && a < 0) || (i != 0 && a > 0) {
+			oriented[i] = planar.Reversed(ring)
+		} else {
+			oriented[i] = ring
+		}
+	}
+	return oriented
+}
+
+func rightHandRule(t geom.T) geom.T {
+	switch g := t.(type) {
+	case geom.Polygon:
+		return rightHandRulePolygon(g)
+	case geom.MultiPolygon:
+		oriented := make(geom.MultiPolygon, len(g))
+		for i, polygon := range g {
+			oriented[i] = rightHandRulePolygon(polygon)
+		}
+		return oriented
+	default:
+		return t
+	}
+}
+
+// interpolate returns the point where the segment from a to b crosses the
+// meridian at x, interpolating any further components.
+func interpolate(a, b geom.Point, x float64) geom.Point {
+	t := (x - a[geom.X]) / (b[geom.X] - a[geom.X])
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	p := make(geom.Point, n)
+	for i := range p {
+		p[i] = a[i] + t*(b[i]-a[i])
+	}
+	p[geom.X] = x
+	return p
+}
+
+func shifted(p geom.Point, dx float64) geom.Point {
+	q := make(geom.Point, len(p))
+	copy(q, p)
+	q[geom.X] += dx
+	return q
+}
+
+func splitLineString(lineString geom.LineString) geom.MultiLineString {
+	parts := geom.MultiLineString{}
+	part := geom.LineString{}
+	for i, p := range lineString {
+		if i != 0 {
+			prev := lineString[i-1]
+			dx := p[geom.X] - prev[geom.X]
+			if dx > 180 {
+				// crosses westward, from -180 to 180
+				crossing := interpolate(prev, shifted(p, -360), -180)
+				parts = append(parts, append(part, crossing))
+				part = geom.LineString{shifted(crossing, 360)}
+			} else if dx < -180 {
+				// crosses eastward, from 180 to -180
+				crossing := interpolate(prev, shifted(p, 360), 180)
+				parts = append(parts, append(part, crossing))
+				part = geom.LineString{shifted(crossing, -360)}
+			}
+		}
+		part = append(part, p)
+	}
+	return append(parts, part)
+}
+
+// unwrapRing returns a copy of ring with longitudes shifted by multiples of
+// 360 so that no edge spans more than 180 degrees.
+func unwrapRing(ring geom.Ring) geom.Ring {
+	unwrapped := make(geom.Ring, len(ring))
+	offset := 0.0
+	for i, p := range ring {
+		if i != 0 {
+			dx := p[geom.X] + offset - unwrapped[i-1][geom.X]
+			if dx > 180 {
+				offset -= 360
+			} else if dx < -180 {
+				offset += 360
+			}
+		}
+		unwrapped[i] = shifted(p, offset)
+	}
+	return unwrapped
+}
+
+func longitudeRange(ring geom.Ring) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, p := range ring {
+		min = math.Min(min, p[geom.X])
+		max = math.Max(max, p[geom.X])
+	}
+	return min, max
+}
+
+// clipRing clips a closed ring to the side of the meridian at x given by
+// side: -1 keeps longitudes <= x, 1 keeps longitudes >= x. It returns nil
+// if nothing is left.
+func clipRing(ring geom.Ring, x float64, side float64) geom.Ring {
+	inside := func(p geom.Point) bool {
+		return side*(p[geom.X]-x) >= 0
+	}
+
+	open := ring[:len(ring)-1]
+	clipped := geom.Ring{}
+	for i, p := range open {
+		prev := open[(i+len(open)-1)%len(open)]
+		if inside(p) {
+			if !inside(prev) {
+				clipped = append(clipped, interpolate(prev, p, x))
+			}
+			clipped = append(clipped, p)
+		} else if inside(prev) {
+			clipped = append(clipped, interpolate(prev, p, x))
+		}
+	}
+	if len(clipped) < 3 {
+		return nil
+	}
+	return append(clipped, clipped[0])
+}
+
+func splitPolygon(polygon geom.Polygon) geom.MultiPolygon {
+	if len(polygon) == 0 || len(polygon[0]) < 4 {
+		return geom.MultiPolygon{polygon}
+	}
+
+	exterior := unwrapRing(polygon[0])
+	if exterior[0][geom.X] != exterior[len(exterior)-1][geom.X] {
+		// the ring encircles a pole, which cannot be split
+		return geom.MultiPolygon{polygon}
+	}
+
+	min, max := longitudeRange(exterior)
+	var meridian, westShift, eastShift float64
+	if max > 180 {
+		meridian, eastShift = 180, -360
+	} else if min < -180 {
+		meridian, westShift = -180, 360
+	} else {
+		return geom.MultiPolygon{polygon}
+	}
+
+	// move each hole next to the exterior before clipping
+	center := (min + max) / 2
+	rings := geom.Polygon{exterior}
+	for _, ring := range polygon[1:] {
+		if len(ring) < 4 {
+			continue
+		}
+		hole := unwrapRing(ring)
+		holeMin, holeMax := longitudeRange(hole)
+		offset := 360 * math.Round((center-(holeMin+holeMax)/2)/360)
+		if offset != 0 {
+			for i, p := range hole {
+				hole[i] = shifted(p, offset)
+			}
+		}
+		rings = append(rings, hole)
+	}
+
+	west, east := geom.Polygon{}, geom.Polygon{}
+	for i, ring := range rings {
+		if w := clipRing(ring, meridian, -1); w != nil && (i == 0 || len(west) != 0) {
+			for j, p := range w {
+				w[j] = shifted(p, westShift)
+			}
+			west = append(west, w)
+		}
+		if e := clipRing(ring, meridian, 1); e != nil && (i == 0 || len(east) != 0) {
+			for j, p := range e {
+				e[j] = shifted(p, eastShift)
+			}
+			east = append(east, e)
+		}
+	}
+
+	parts := geom.MultiPolygon{}
+	if len(west) != 0 {
+		parts = append(parts, west)
+	}
+	if len(east) != 0 {
+		parts = append(parts, east)
+	}
+	return parts
+}
+
+func splitAntimeridian(t geom.T) geom.T {
+	switch g := t.(type) {
+	case geom.LineString:
+		parts := splitLineString(g)
+		if len(parts) == 1 {
+			return g
+		}
+		return parts
+	case geom.MultiLineString:
+		parts := geom.MultiLineString{}
+		for _, lineString := range g {
+			parts = append(parts, splitLineString(lineString)...)
+		}
+		return parts
+	case geom.Polygon:
+		parts := splitPolygon(g)
+		if len(parts) == 1 {
+			return parts[0]
+		}
+		return parts
+	case geom.MultiPolygon:
+		parts := geom.MultiPolygon{}
+		for _, polygon := range g {
+			parts = append(parts, splitPolygon(polygon)...)
+		}
+		return parts
+	default:
+		return t
+	}
+}
@@ -0,0 +1,325 @@
+package geojson
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/foobaz/geom"
+)
+
+var errWriterClosed = errors.New("geojson: write after close")
+
+// recordSeparator starts each text in a GeoJSON Text Sequence (RFC 8142).
+const recordSeparator = 0x1e
+
+// FeatureReader decodes features one at a time, so that a large
+// FeatureCollection need not be held in memory. Call Next until it returns
+// false, then check Err.
+//
+//	r := geojson.NewFeatureReader(file)
+//	for r.Next() {
+//		feature := r.Feature()
+//		...
+//	}
+//	if err := r.Err(); err != nil {
+//		...
+//	}
+type FeatureReader struct {
+	dec      *json.Decoder
+	sequence bool
+	records  *recordReader
+	skipped  int
+	started  bool
+	done     bool
+	typeSeen bool
+	feature  geom.Feature
+	err      error
+}
+
+// NewFeatureReader reads the features of a single FeatureCollection.
+func NewFeatureReader(r io.Reader) *FeatureReader {
+	return &FeatureReader{dec: json.NewDecoder(r)}
+}
+
+// NewFeatureSequenceReader reads a GeoJSON Text Sequence (RFC 8142) of
+// Features. Newline-delimited Features without record separators are also
+// accepted. As RFC 8142 recommends, truncated texts are skipped; Skipped
+// reports how many were.
+func NewFeatureSequenceReader(r io.Reader) *FeatureReader {
+	records := &recordReader{r: bufio.NewReader(r)}
+	return &FeatureReader{
+		dec:      json.NewDecoder(records),
+		sequence: true,
+		records:  records,
+	}
+}
+
+// Feature returns the feature decoded by the last call to Next.
+func (fr *FeatureReader) Feature() geom.Feature {
+	return fr.feature
+}
+
+// Err returns the first error encountered, or nil at a clean end of input.
+func (fr *FeatureReader) Err() error {
+	return fr.err
+}
+
+// Skipped returns the number of truncated texts skipped so far in a text
+// sequence.
+func (fr *FeatureReader) Skipped() int {
+	return fr.skipped
+}
+
+// Next decodes the next feature, returning false at the end of the input or
+// on error.
+func (fr *FeatureReader) Next() bool {
+	if fr.done {
+		return false
+	}
+
+	var more bool
+	var err error
+	if fr.sequence {
+		more, err = fr.nextInSequence()
+	} else {
+		more, err = fr.nextInCollection()
+	}
+	if err != nil {
+		fr.err = err
+	}
+	if !more || err != nil {
+		fr.done = true
+		fr.feature = geom.Feature{}
+		return false
+	}
+	return true
+}
+
+func (fr *FeatureReader) decodeFeature() error {
+	var f Feature
+	if err := fr.dec.Decode(&f); err != nil {
+		return err
+	}
+
+	feature, err := FeatureFromGeoJSON(f)
+	if err != nil {
+		return err
+	}
+
+	fr.feature = feature
+	return nil
+}
+
+func (fr *FeatureReader) nextInSequence() (bool, error) {
+	for {
+		if fr.dec.More() {
+			err := fr.decodeFeature()
+			if err == nil {
+				return true, nil
+			} else if err != io.ErrUnexpectedEOF {
+				return false, err
+			}
+			fr.skipped++
+		}
+		if !fr.records.next() {
+			return false, nil
+		}
+		fr.dec = json.NewDecoder(fr.records)
+	}
+}
+
+func (fr *FeatureReader) nextInCollection() (bool, error) {
+	if !fr.started {
+		fr.started = true
+		if err := fr.expectDelim('{'); err != nil {
+			return false, err
+		}
+		if found, err := fr.findFeatures(); !found || err != nil {
+			return false, err
+		}
+	}
+
+	if fr.dec.More() {
+		if err := fr.decodeFeature(); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	// end of the features array; check the members after it
+	if err := fr.expectDelim(']'); err != nil {
+		return false, err
+	}
+	if found, err := fr.findFeatures(); found {
+		return false, &InvalidGeometryError{}
+	} else {
+		return false, err
+	}
+}
+
+// findFeatures consumes object members until it has consumed the opening of
+// the features array, returning false if the object ends first.
+func (fr *FeatureReader) findFeatures() (bool, error) {
+	for fr.dec.More() {
+		token, err := fr.dec.Token()
+		if err != nil {
+			return false, err
+		}
+
+		switch token {
+		case "features":
+			return true, fr.expectDelim('[')
+		case "type":
+			var t string
+			if err := fr.dec.Decode(&t); err != nil {
+				return false, err
+			}
+			if t != "FeatureCollection" {
+				return false, &UnsupportedGeometryError{t}
+			}
+			fr.typeSeen = true
+		default:
+			var skipped json.RawMessage
+			if err := fr.dec.Decode(&skipped); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := fr.expectDelim('}'); err != nil {
+		return false, err
+	}
+	if !fr.typeSeen {
+		return false, &UnsupportedGeometryError{""}
+	}
+	return false, nil
+}
+
+func (fr *FeatureReader) expectDelim(delim json.Delim) error {
+	token, err := fr.dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if token != delim {
+		return &InvalidGeometryError{}
+	}
+	return nil
+}
+
+// recordReader reads one record of a text sequence at a time, returning
+// io.EOF at the next record separator, so that a truncated text ends at
+// its record rather than running into the next one.
+type recordReader struct {
+	r    *bufio.Reader
+	end  bool // the current record has ended
+	last bool // the input has ended
+}
+
+func (rr *recordReader) Read(p []byte) (int, error) {
+	n := 0
+	for !rr.end && n < len(p) {
+		c, err := rr.r.ReadByte()
+		if err == io.EOF {
+			rr.end, rr.last = true, true
+		} else if err != nil {
+			return n, err
+		} else if c == recordSeparator {
+			rr.end = true
+		} else {
+			p[n] = c
+			n++
+		}
+	}
+	if n == 0 && rr.end {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// next moves to the following record, returning false at the end of the
+// input.
+func (rr *recordReader) next() bool {
+	if rr.last {
+		return false
+	}
+	rr.end = false
+	return true
+}
+
+// FeatureWriter encodes features one at a time. Close must be called to
+// finish a FeatureCollection; it does not close the underlying writer.
+type FeatureWriter struct {
+	w        io.Writer
+	sequence bool
+	count    int
+	closed   bool
+	err      error
+}
+
+// NewFeatureWriter writes a single FeatureCollection.
+func NewFeatureWriter(w io.Writer) *FeatureWriter {
+	return &FeatureWriter{w: w}
+}
+
+// NewFeatureSequenceWriter writes a GeoJSON Text Sequence (RFC 8142), with
+// each Feature preceded by a record separator and followed by a newline.
+func NewFeatureSequenceWriter(w io.Writer) *FeatureWriter {
+	return &FeatureWriter{w: w, sequence: true}
+}
+
+func (fw *FeatureWriter) writeString(s string) {
+	if fw.err == nil {
+		_, fw.err = io.WriteString(fw.w, s)
+	}
+}
+
+func (fw *FeatureWriter) Write(feature geom.Feature) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if fw.closed {
+		return errWriterClosed
+	}
+
+	data, err := Encode(feature)
+	if err != nil {
+		return err
+	}
+
+	if fw.sequence {
+		fw.writeString(string(rune(recordSeparator)))
+	} else if fw.count == 0 {
+		fw.writeString(`{"type":"FeatureCollection","features":[`)
+	} else {
+		fw.writeString(",")
+	}
+	if fw.err == nil {
+		_, fw.err = fw.w.Write(data)
+	}
+	if fw.sequence {
+		fw.writeString("\n")
+	}
+
+	fw.count++
+	return fw.err
+}
+
+// Close finishes the FeatureCollection. It writes nothing for a sequence.
+func (fw *FeatureWriter) Close() error {
+	if fw.closed {
+		return fw.err
+	}
+	fw.closed = true
+	if fw.sequence {
+		return fw.err
+	}
+	if fw.count == 0 {
+		fw.writeString(`{"type":"FeatureCollection","features":[`)
+	}
+	fw.writeString("]}")
+	return fw.err
+}
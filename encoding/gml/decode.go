@@ -0,0 +1,314 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/foobaz/geom"
+)
+
+// Decode parses a document whose root element is a GML geometry. See
+// DecodeElement.
+func Decode(data []byte) (geom.T, error) {
+	return Read(bytes.NewReader(data))
+}
+
+// Read parses a document whose root element is a GML geometry from r.
+func Read(r io.Reader) (geom.T, error) {
+	d := xml.NewDecoder(r)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil, FormatError{"no geometry element"}
+		} else if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return DecodeElement(d, start)
+		}
+	}
+}
+
+// DecodeElement decodes the geometry element that start begins, consuming
+// d up to and including its end element, so that GML embedded in other
+// documents such as WFS responses can be decoded. The deprecated
+// gml:MultiLineString and gml:MultiPolygon, and the GML 2 polygon
+// boundaries, are also accepted. Without an srsDimension, gml:pos takes
+// its dimension from the number of values and gml:posList has two.
+func DecodeElement(d *xml.Decoder, start xml.StartElement) (geom.T, error) {
+	c, err := context{dimension: 2}.update(start)
+	if err != nil {
+		return nil, err
+	}
+	g, err := c.geometry(d, start)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := attr(start, "srsName"); ok {
+		if srid, _, ok := parseSRSName(name); ok {
+			return geom.NewSRIDGeometry(g, srid), nil
+		}
+	}
+	return g, nil
+}
+
+// context holds the srsDimension and axis order in force, which elements
+// inherit from their ancestors.
+type context struct {
+	dimension int
+	explicit  bool
+	swap      bool
+}
+
+func (c context) update(start xml.StartElement) (context, error) {
+	if s, ok := attr(start, "srsDimension"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 2 || n > 4 {
+			return c, FormatError{"invalid srsDimension " + strconv.Quote(s)}
+		}
+		c.dimension, c.explicit = n, true
+	}
+	if s, ok := attr(start, "srsName"); ok {
+		if _, swap, ok := parseSRSName(s); ok {
+			c.swap = swap
+		}
+	}
+	return c, nil
+}
+
+// children calls f for each child element of the element just started,
+// returning after its end. f must consume the child, up to and including
+// its end element.
+func children(d *xml.Decoder, f func(xml.StartElement) error) error {
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := f(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// text returns the character data of the element just started.
+func text(d *xml.Decoder) (string, error) {
+	var s strings.Builder
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			s.Write(t)
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			return s.String(), nil
+		}
+	}
+}
+
+func attr(start xml.StartElement, name string) (string, bool) {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// positions reads the points in the gml:pos and gml:posList children of
+// the element just started.
+func (c context) positions(d *xml.Decoder, element string) ([]geom.Point, error) {
+	points := []geom.Point{}
+	err := children(d, func(child xml.StartElement) error {
+		name := child.Name.Local
+		if name != "pos" && name != "posList" {
+			return d.Skip()
+		}
+		cc, err := c.update(child)
+		if err != nil {
+			return err
+		}
+		s, err := text(d)
+		if err != nil {
+			return err
+		}
+
+		values := strings.Fields(s)
+		dimension := cc.dimension
+		if name == "pos" && !cc.explicit {
+			dimension = len(values)
+		}
+		if dimension < 2 || dimension > 4 || len(values)%dimension != 0 {
+			return InvalidCoordinatesError{element, s}
+		}
+		for i := 0; i < len(values); i += dimension {
+			point := make(geom.Point, dimension)
+			for j := range point {
+				if point[j], err = strconv.ParseFloat(values[i+j], 64); err != nil {
+					return InvalidCoordinatesError{element, s}
+				}
+			}
+			if cc.swap {
+				point[0], point[1] = point[1], point[0]
+			}
+			points = append(points, point)
+		}
+		return nil
+	})
+	return points, err
+}
+
+func (c context) polygon(d *xml.Decoder) (geom.Polygon, error) {
+	var exterior geom.Ring
+	interiors := []geom.Ring{}
+	err := children(d, func(boundary xml.StartElement) error {
+		name := boundary.Name.Local
+		switch name {
+		case "exterior", "outerBoundaryIs", "interior", "innerBoundaryIs":
+		default:
+			return d.Skip()
+		}
+		return children(d, func(ring xml.StartElement) error {
+			if ring.Name.Local != "LinearRing" {
+				return UnknownGeometryError{ring.Name.Local}
+			}
+			rc, err := c.update(ring)
+			if err != nil {
+				return err
+			}
+			points, err := rc.positions(d, "LinearRing")
+			if name == "exterior" || name == "outerBoundaryIs" {
+				exterior = points
+			} else {
+				interiors = append(interiors, points)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if exterior == nil {
+		return geom.Polygon{}, nil
+	}
+	return append(geom.Polygon{exterior}, interiors...), nil
+}
+
+// members reads the geometries inside the member elements of the
+// aggregate just started.
+func (c context) members(d *xml.Decoder, names ...string) ([]geom.T, error) {
+	members := []geom.T{}
+	err := children(d, func(member xml.StartElement) error {
+		found := false
+		for _, name := range names {
+			if member.Name.Local == name {
+				found = true
+			}
+		}
+		if !found {
+			return d.Skip()
+		}
+		return children(d, func(child xml.StartElement) error {
+			cc, err := c.update(child)
+			if err != nil {
+				return err
+			}
+			g, err := cc.geometry(d, child)
+			members = append(members, g)
+			return err
+		})
+	})
+	return members, err
+}
+
+func (c context) geometry(d *xml.Decoder, start xml.StartElement) (geom.T, error) {
+	name := start.Name.Local
+	switch name {
+	case "Point":
+		points, err := c.positions(d, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) != 1 {
+			return nil, InvalidCoordinatesError{name, ""}
+		}
+		return points[0], nil
+	case "LineString", "LinearRing":
+		points, err := c.positions(d, name)
+		if err != nil {
+			return nil, err
+		}
+		return geom.LineString(points), nil
+	case "Polygon":
+		return c.polygon(d)
+	case "MultiPoint":
+		members, err := c.members(d, "pointMember", "pointMembers")
+		if err != nil {
+			return nil, err
+		}
+		multiPoint := make(geom.MultiPoint, len(members))
+		for i, member := range members {
+			point, ok := member.(geom.Point)
+			if !ok {
+				return nil, FormatError{"MultiPoint member is not a Point"}
+			}
+			multiPoint[i] = point
+		}
+		return multiPoint, nil
+	case "MultiCurve", "MultiLineString":
+		members, err := c.members(d, "curveMember", "curveMembers", "lineStringMember")
+		if err != nil {
+			return nil, err
+		}
+		multiLineString := make(geom.MultiLineString, len(members))
+		for i, member := range members {
+			lineString, ok := member.(geom.LineString)
+			if !ok {
+				return nil, FormatError{name + " member is not a LineString"}
+			}
+			multiLineString[i] = lineString
+		}
+		return multiLineString, nil
+	case "MultiSurface", "MultiPolygon":
+		members, err := c.members(d, "surfaceMember", "surfaceMembers", "polygonMember")
+		if err != nil {
+			return nil, err
+		}
+		multiPolygon := make(geom.MultiPolygon, len(members))
+		for i, member := range members {
+			polygon, ok := member.(geom.Polygon)
+			if !ok {
+				return nil, FormatError{name + " member is not a Polygon"}
+			}
+			multiPolygon[i] = polygon
+		}
+		return multiPolygon, nil
+	case "MultiGeometry":
+		members, err := c.members(d, "geometryMember", "geometryMembers")
+		if err != nil {
+			return nil, err
+		}
+		return geom.GeometryCollection(members), nil
+	}
+	return nil, UnknownGeometryError{name}
+}
@@ -0,0 +1,142 @@
+package gml
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/foobaz/geom"
+)
+
+type encoder struct {
+	b         bytes.Buffer
+	dimension int
+	swap      bool
+}
+
+// Encode returns t as a GML element with the given axes. The element
+// declares the gml namespace, an srsName if t is a geom.SRIDGeometry and,
+// unless axes is geom.TwoD, an srsDimension. As a third component would be
+// read back as Z, geom.M is not supported.
+func Encode(t geom.T, axes int) ([]byte, error) {
+	e := encoder{dimension: geom.Layout(axes).Stride()}
+	if e.dimension == 0 || axes == geom.M {
+		return nil, UnsupportedAxesError{axes}
+	}
+
+	attrs := ` xmlns:gml="` + Namespace + `"`
+	if s, ok := t.(geom.SRIDGeometry); ok {
+		attrs += ` srsName="` + srsName(s.SRID) + `"`
+		e.swap = latitudeFirst[s.SRID]
+		t = s.T
+	}
+	if axes != geom.TwoD {
+		attrs += ` srsDimension="` + strconv.Itoa(e.dimension) + `"`
+	}
+	if err := e.geometry(t, attrs); err != nil {
+		return nil, err
+	}
+	return e.b.Bytes(), nil
+}
+
+func Write(w io.Writer, t geom.T, axes int) error {
+	data, err := Encode(t, axes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (e *encoder) positions(element string, points []geom.Point) error {
+	var dst []byte
+	for i, point := range points {
+		if len(point) < e.dimension {
+			return DimensionError{Dimension: e.dimension, ElementCount: len(point)}
+		}
+		for j := 0; j < e.dimension; j++ {
+			if i != 0 || j != 0 {
+				dst = append(dst, ' ')
+			}
+			c := point[j]
+			if e.swap && j < 2 {
+				c = point[1-j]
+			}
+			dst = strconv.AppendFloat(dst, c, 'f', -1, 64)
+		}
+	}
+	e.b.WriteString("<gml:" + element + ">")
+	e.b.Write(dst)
+	e.b.WriteString("</gml:" + element + ">")
+	return nil
+}
+
+func (e *encoder) polygon(polygon geom.Polygon, attrs string) error {
+	e.b.WriteString("<gml:Polygon" + attrs + ">")
+	for i, ring := range polygon {
+		boundary := "interior"
+		if i == 0 {
+			boundary = "exterior"
+		}
+		e.b.WriteString("<gml:" + boundary + "><gml:LinearRing>")
+		if err := e.positions("posList", ring); err != nil {
+			return err
+		}
+		e.b.WriteString("</gml:LinearRing></gml:" + boundary + ">")
+	}
+	e.b.WriteString("</gml:Polygon>")
+	return nil
+}
+
+// geometry writes t, with attrs added to its outermost element.
+func (e *encoder) geometry(t geom.T, attrs string) error {
+	var err error
+	switch g := t.(type) {
+	case geom.Point:
+		e.b.WriteString("<gml:Point" + attrs + ">")
+		err = e.positions("pos", []geom.Point{g})
+		e.b.WriteString("</gml:Point>")
+	case geom.LineString:
+		e.b.WriteString("<gml:LineString" + attrs + ">")
+		err = e.positions("posList", g)
+		e.b.WriteString("</gml:LineString>")
+	case geom.Polygon:
+		err = e.polygon(g, attrs)
+	case geom.MultiPoint:
+		e.b.WriteString("<gml:MultiPoint" + attrs + ">")
+		for i := 0; i < len(g) && err == nil; i++ {
+			e.b.WriteString("<gml:pointMember>")
+			err = e.geometry(g[i], "")
+			e.b.WriteString("</gml:pointMember>")
+		}
+		e.b.WriteString("</gml:MultiPoint>")
+	case geom.MultiLineString:
+		e.b.WriteString("<gml:MultiCurve" + attrs + ">")
+		for i := 0; i < len(g) && err == nil; i++ {
+			e.b.WriteString("<gml:curveMember>")
+			err = e.geometry(g[i], "")
+			e.b.WriteString("</gml:curveMember>")
+		}
+		e.b.WriteString("</gml:MultiCurve>")
+	case geom.MultiPolygon:
+		e.b.WriteString("<gml:MultiSurface" + attrs + ">")
+		for i := 0; i < len(g) && err == nil; i++ {
+			e.b.WriteString("<gml:surfaceMember>")
+			err = e.polygon(g[i], "")
+			e.b.WriteString("</gml:surfaceMember>")
+		}
+		e.b.WriteString("</gml:MultiSurface>")
+	case geom.GeometryCollection:
+		e.b.WriteString("<gml:MultiGeometry" + attrs + ">")
+		for i := 0; i < len(g) && err == nil; i++ {
+			e.b.WriteString("<gml:geometryMember>")
+			err = e.geometry(g[i], "")
+			e.b.WriteString("</gml:geometryMember>")
+		}
+		e.b.WriteString("</gml:MultiGeometry>")
+	default:
+		return UnsupportedGeometryError{reflect.TypeOf(t)}
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+// Package gml encodes and decodes Geography Markup Language 3 geometries.
+//
+// Point, LineString, Polygon, MultiPoint, MultiLineString, MultiPolygon and
+// GeometryCollection map to gml:Point, gml:LineString, gml:Polygon,
+// gml:MultiPoint, gml:MultiCurve, gml:MultiSurface and gml:MultiGeometry.
+// Coordinates are written in gml:pos and gml:posList elements.
+//
+// GML has no notion of a measure, so srsDimension only gives the number of
+// components in each position. As with WKT, three components are taken to
+// be X, Y and Z, and four X, Y, Z and M. Encoding with geom.M is therefore
+// not supported, while geom.ZM follows this four component convention.
+//
+// The SRID of a geom.SRIDGeometry is written as an EPSG URN in srsName,
+// and an srsName naming an EPSG code is decoded to a geom.SRIDGeometry.
+// Positions in EPSG geographic systems named by URN or http URI are in
+// latitude, longitude order, as those systems define; they are swapped to
+// and from the longitude, latitude order used elsewhere.
+package gml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/foobaz/geom"
+)
+
+const Namespace = "http://www.opengis.net/gml"
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "gml: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes int
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("gml: unsupported axes %d", e.Axes)
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+// UnknownGeometryError reports a geometry element this package does not
+// decode, such as gml:Curve.
+type UnknownGeometryError struct {
+	Name string
+}
+
+func (e UnknownGeometryError) Error() string {
+	return "gml: unknown geometry element " + e.Name
+}
+
+// InvalidCoordinatesError reports a gml:pos or gml:posList that cannot be
+// parsed, or has the wrong number of positions for its geometry.
+type InvalidCoordinatesError struct {
+	Element     string
+	Coordinates string
+}
+
+func (e InvalidCoordinatesError) Error() string {
+	return fmt.Sprintf("gml: invalid coordinates in %s: %q", e.Element, e.Coordinates)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "gml: " + e.Msg
+}
+
+// latitudeFirst lists the EPSG geographic systems whose first axis is
+// latitude.
+var latitudeFirst = map[uint32]bool{
+	4258: true, // ETRS89
+	4269: true, // NAD83
+	4283: true, // GDA94
+	4326: true, // WGS 84
+	4617: true, // NAD83(CSRS)
+	4674: true, // SIRGAS 2000
+	7844: true, // GDA2020
+}
+
+// srsName returns the srsName for srid.
+func srsName(srid uint32) string {
+	return "urn:ogc:def:crs:EPSG::" + strconv.FormatUint(uint64(srid), 10)
+}
+
+// parseSRSName returns the EPSG code named by s and whether positions are
+// in latitude, longitude order. It accepts EPSG:<code>, URNs of the form
+// urn:ogc:def:crs:EPSG:<version>:<code>, URIs of the form
+// http://www.opengis.net/def/crs/EPSG/<version>/<code> and the older
+// http://www.opengis.net/gml/srs/epsg.xml#<code>.
+func parseSRSName(s string) (uint32, bool, bool) {
+	lower := strings.ToLower(s)
+	var code string
+	authority := false
+	switch {
+	case strings.HasPrefix(lower, "epsg:"):
+		code = s[len("epsg:"):]
+	case strings.HasPrefix(lower, "http://www.opengis.net/gml/srs/epsg.xml#"):
+		code = s[len("http://www.opengis.net/gml/srs/epsg.xml#"):]
+	case strings.HasPrefix(lower, "urn:ogc:def:crs:epsg:"), strings.HasPrefix(lower, "urn:x-ogc:def:crs:epsg:"):
+		code = s[strings.LastIndexByte(s, ':')+1:]
+		authority = true
+	case strings.HasPrefix(lower, "http://www.opengis.net/def/crs/epsg/"):
+		code = s[strings.LastIndexByte(s, '/')+1:]
+		authority = true
+	default:
+		return 0, false, false
+	}
+	srid, err := strconv.ParseUint(code, 10, 32)
+	if err != nil {
+		return 0, false, false
+	}
+	return uint32(srid), authority && latitudeFirst[uint32(srid)], true
+}
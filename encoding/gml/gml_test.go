@@ -0,0 +1,189 @@
+package gml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+const ns = ` xmlns:gml="http://www.opengis.net/gml"`
+
+func TestGML(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		axes int
+		xml  string
+	}{
+		{
+			geom.Point{1, 2},
+			geom.TwoD,
+			`<gml:Point` + ns + `><gml:pos>1 2</gml:pos></gml:Point>`,
+		},
+		{
+			geom.LineString{{1, 2, 3}, {4.5, 5, 6}},
+			geom.Z,
+			`<gml:LineString` + ns + ` srsDimension="3"><gml:posList>1 2 3 4.5 5 6</gml:posList></gml:LineString>`,
+		},
+		{
+			geom.Polygon{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+				{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+			},
+			geom.TwoD,
+			`<gml:Polygon` + ns + `>` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 4 0 4 4 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>1 1 2 1 2 2 1 1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			geom.MultiPoint{{1, 2}, {3, 4}},
+			geom.TwoD,
+			`<gml:MultiPoint` + ns + `>` +
+				`<gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+				`<gml:pointMember><gml:Point><gml:pos>3 4</gml:pos></gml:Point></gml:pointMember>` +
+				`</gml:MultiPoint>`,
+		},
+		{
+			geom.NewSRIDGeometry(geom.Point{2.35, 48.85}, 4326),
+			geom.TwoD,
+			`<gml:Point` + ns + ` srsName="urn:ogc:def:crs:EPSG::4326"><gml:pos>48.85 2.35</gml:pos></gml:Point>`,
+		},
+		{
+			geom.NewSRIDGeometry(geom.Point{500000, 4000000}, 32631),
+			geom.TwoD,
+			`<gml:Point` + ns + ` srsName="urn:ogc:def:crs:EPSG::32631"><gml:pos>500000 4000000</gml:pos></gml:Point>`,
+		},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.g, tc.axes)
+		if err != nil || string(data) != tc.xml {
+			t.Errorf("Encode(%#v, %d) == %s, %v, want %s, nil", tc.g, tc.axes, data, err, tc.xml)
+		}
+		if got, err := Decode([]byte(tc.xml)); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", tc.xml, got, err, tc.g)
+		}
+	}
+}
+
+func TestGMLRoundTrip(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		axes int
+	}{
+		{geom.Point{1, 2}, geom.TwoD},
+		{geom.Point{1, 2, 3}, geom.Z},
+		{geom.Point{1, 2, 3, 4}, geom.ZM},
+		{geom.LineString{}, geom.TwoD},
+		{geom.LineString{{1, 2, 3, 4}, {5, 6, 7, 8}}, geom.ZM},
+		{geom.Polygon{}, geom.TwoD},
+		{geom.Polygon{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}}, geom.Z},
+		{geom.MultiPoint{}, geom.TwoD},
+		{geom.MultiPoint{{1, 2, 3}, {4, 5, 6}}, geom.Z},
+		{geom.MultiLineString{{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}, {4, 4}}}, geom.TwoD},
+		{geom.MultiPolygon{
+			{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			{{{5, 5}, {9, 5}, {9, 9}, {5, 5}}, {{6, 6}, {7, 6}, {7, 7}, {6, 6}}},
+		}, geom.TwoD},
+		{geom.GeometryCollection{
+			geom.Point{1, 2, 3, 4},
+			geom.LineString{{0, 0, 0, 0}, {1, 1, 1, 1}},
+			geom.GeometryCollection{geom.MultiPoint{{5, 6, 7, 8}}},
+		}, geom.ZM},
+		{geom.NewSRIDGeometry(geom.MultiLineString{{{2, 48}, {3, 49}}}, 4326), geom.TwoD},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.g, tc.axes)
+		if err != nil {
+			t.Errorf("Encode(%#v, %d) == %v", tc.g, tc.axes, err)
+			continue
+		}
+		if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", data, got, err, tc.g)
+		}
+	}
+}
+
+func TestGMLDecode(t *testing.T) {
+	var testCases = []struct {
+		xml string
+		g   geom.T
+	}{
+		// srsDimension on posList, and a namespace other than gml.
+		{
+			`<LineString xmlns="http://www.opengis.net/gml/3.2"><posList srsDimension="3">1 2 3 4 5 6</posList></LineString>`,
+			geom.LineString{{1, 2, 3}, {4, 5, 6}},
+		},
+		// gml:pos without srsDimension takes its dimension from its values.
+		{
+			`<gml:Point` + ns + `><gml:pos>1 2 3</gml:pos></gml:Point>`,
+			geom.Point{1, 2, 3},
+		},
+		{
+			`<gml:LineString` + ns + `><gml:pos>1 2</gml:pos><gml:pos>3 4</gml:pos></gml:LineString>`,
+			geom.LineString{{1, 2}, {3, 4}},
+		},
+		// EPSG:<code> names are longitude first.
+		{
+			`<gml:Point` + ns + ` srsName="EPSG:4326"><gml:pos>2 48</gml:pos></gml:Point>`,
+			geom.NewSRIDGeometry(geom.Point{2, 48}, 4326),
+		},
+		{
+			`<gml:Point` + ns + ` srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>48 2</gml:pos></gml:Point>`,
+			geom.NewSRIDGeometry(geom.Point{2, 48}, 4326),
+		},
+		// Deprecated aggregates and GML 2 boundaries.
+		{
+			`<gml:MultiPolygon` + ns + `><gml:polygonMember><gml:Polygon>` +
+				`<gml:outerBoundaryIs><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:outerBoundaryIs>` +
+				`</gml:Polygon></gml:polygonMember></gml:MultiPolygon>`,
+			geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+		},
+		{
+			`<gml:MultiCurve` + ns + `><gml:curveMembers><gml:LineString><gml:posList>0 0 1 1</gml:posList></gml:LineString>` +
+				`<gml:LineString><gml:posList>2 2 3 3</gml:posList></gml:LineString></gml:curveMembers></gml:MultiCurve>`,
+			geom.MultiLineString{{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}}},
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := Decode([]byte(tc.xml)); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", tc.xml, got, err, tc.g)
+		}
+	}
+}
+
+func TestGMLError(t *testing.T) {
+	if _, err := Encode(geom.Point{1, 2, 3}, geom.M); !reflect.DeepEqual(err, UnsupportedAxesError{geom.M}) {
+		t.Errorf("Encode with M == _, %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, 4); !reflect.DeepEqual(err, UnsupportedAxesError{4}) {
+		t.Errorf("Encode with axes 4 == %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, geom.Z); !reflect.DeepEqual(err, DimensionError{Dimension: 3, ElementCount: 2}) {
+		t.Errorf("Encode(Point{1, 2}, Z) == %#v", err)
+	}
+	if _, err := Encode(geom.Feature{}, geom.TwoD); !reflect.DeepEqual(err, UnsupportedGeometryError{reflect.TypeOf(geom.Feature{})}) {
+		t.Errorf("Encode(Feature) == %#v", err)
+	}
+
+	var testCases = []struct {
+		xml string
+		err error
+	}{
+		{``, FormatError{"no geometry element"}},
+		{`<gml:Curve` + ns + `/>`, UnknownGeometryError{"Curve"}},
+		{`<gml:Point` + ns + `><gml:pos>1 x</gml:pos></gml:Point>`, InvalidCoordinatesError{"Point", "1 x"}},
+		{`<gml:LineString` + ns + `><gml:posList>1 2 3</gml:posList></gml:LineString>`, InvalidCoordinatesError{"LineString", "1 2 3"}},
+		{`<gml:Point` + ns + `><gml:posList>1 2 3 4</gml:posList></gml:Point>`, InvalidCoordinatesError{"Point", ""}},
+		{`<gml:Point` + ns + ` srsDimension="5"><gml:pos>1 2</gml:pos></gml:Point>`, FormatError{`invalid srsDimension "5"`}},
+		{
+			`<gml:MultiPoint` + ns + `><gml:pointMember><gml:LineString/></gml:pointMember></gml:MultiPoint>`,
+			FormatError{"MultiPoint member is not a Point"},
+		},
+	}
+	for _, tc := range testCases {
+		if _, err := Decode([]byte(tc.xml)); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%s) == %#v, want %#v", tc.xml, err, tc.err)
+		}
+	}
+}
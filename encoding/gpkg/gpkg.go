@@ -0,0 +1,283 @@
+// Package gpkg implements the GeoPackage binary geometry encoding: a "GP"
+// header holding flags, an SRS id and an optional envelope, followed by
+// ISO WKB.
+package gpkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/encoding/wkb"
+)
+
+// Envelope contents, as stored in bits 1-3 of the flags byte.
+const (
+	NoEnvelope   = 0
+	EnvelopeXY   = 1
+	EnvelopeXYZ  = 2
+	EnvelopeXYM  = 3
+	EnvelopeXYZM = 4
+)
+
+const (
+	flagLittleEndian = 0x01
+	flagEnvelope     = 0x0e
+	flagEmpty        = 0x10
+	flagExtended     = 0x20
+)
+
+var magic = []byte("GP")
+
+// Header is the GeoPackage header in front of the WKB.
+type Header struct {
+	SRID         int32
+	EnvelopeType int
+	// Envelope holds the envelope in GeoPackage order: min x, max x, min
+	// y, max y, then the Z and M ranges if present.
+	Envelope []float64
+	Empty    bool
+	// Extended is set for geometry types beyond those of the core
+	// specification.
+	Extended bool
+}
+
+type InvalidEnvelopeError struct {
+	EnvelopeType int
+}
+
+func (e InvalidEnvelopeError) Error() string {
+	return fmt.Sprintf("gpkg: invalid envelope type %d", e.EnvelopeType)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "gpkg: " + e.Msg
+}
+
+func envelopeLength(envelopeType int) int {
+	switch envelopeType {
+	case NoEnvelope:
+		return 0
+	case EnvelopeXY:
+		return 4
+	case EnvelopeXYZ, EnvelopeXYM:
+		return 6
+	case EnvelopeXYZM:
+		return 8
+	default:
+		return -1
+	}
+}
+
+// DecodeHeader decodes the header of a GeoPackage geometry, returning it
+// and the length of the header in bytes.
+func DecodeHeader(data []byte) (Header, int, error) {
+	if len(data) < 8 {
+		return Header{}, 0, FormatError{"header too short"}
+	}
+	if !bytes.Equal(data[:2], magic) {
+		return Header{}, 0, FormatError{"bad magic"}
+	}
+	if data[2] != 0 {
+		return Header{}, 0, FormatError{fmt.Sprintf("unsupported version %d", data[2])}
+	}
+
+	flags := data[3]
+	var byteOrder binary.ByteOrder = binary.BigEndian
+	if flags&flagLittleEndian != 0 {
+		byteOrder = binary.LittleEndian
+	}
+	h := Header{
+		SRID:         int32(byteOrder.Uint32(data[4:])),
+		EnvelopeType: int(flags&flagEnvelope) >> 1,
+		Empty:        flags&flagEmpty != 0,
+		Extended:     flags&flagExtended != 0,
+	}
+
+	n := envelopeLength(h.EnvelopeType)
+	if n < 0 {
+		return Header{}, 0, InvalidEnvelopeError{h.EnvelopeType}
+	}
+	length := 8 + 8*n
+	if len(data) < length {
+		return Header{}, 0, FormatError{"header too short"}
+	}
+	if n != 0 {
+		h.Envelope = make([]float64, n)
+		for i := range h.Envelope {
+			h.Envelope[i] = math.Float64frombits(byteOrder.Uint64(data[8+8*i:]))
+		}
+	}
+	return h, length, nil
+}
+
+// Decode decodes a GeoPackage geometry. A nonzero SRS id is returned as the
+// SRID of a geom.SRIDGeometry; -1, for undefined Cartesian systems, becomes
+// 0xffffffff.
+func Decode(data []byte) (geom.T, error) {
+	h, length, err := DecodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	g, err := wkb.Decode(data[length:])
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := g.(geom.SRIDGeometry); ok {
+		g = s.T
+	}
+	if h.SRID != 0 {
+		g = geom.NewSRIDGeometry(g, uint32(h.SRID))
+	}
+	return g, nil
+}
+
+// Encode encodes g as a GeoPackage geometry. byteOrder is used for both the
+// header and the WKB. The SRS id is the SRID of a geom.SRIDGeometry, or 0.
+// envelopeType selects which envelope to compute: the X and Y ranges come
+// from geom.Bounds, and the Z and M ranges, which need the matching axes,
+// from the points. The envelope of an empty geometry is NaN. With
+// geom.LayoutAxes the axes are those of geom.LayoutOf(g). Geometries with
+// curve or surface types are flagged as extended.
+func Encode(g geom.T, byteOrder binary.ByteOrder, axes uint32, envelopeType int) ([]byte, error) {
+	var srid uint32
+	if s, ok := g.(geom.SRIDGeometry); ok {
+		g, srid = s.T, s.SRID
+	}
+	if axes == geom.LayoutAxes {
+		layout, err := geom.LayoutOf(g)
+		if err != nil {
+			return nil, err
+		}
+		axes = uint32(layout)
+	}
+
+	n := envelopeLength(envelopeType)
+	if n < 0 ||
+		(envelopeType == EnvelopeXYZ && axes&geom.Z == 0) ||
+		(envelopeType == EnvelopeXYM && axes&geom.M == 0) ||
+		(envelopeType == EnvelopeXYZM && axes != geom.ZM) {
+		return nil, InvalidEnvelopeError{envelopeType}
+	}
+
+	data, err := wkb.Encode(g, byteOrder, axes)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := byte(envelopeType << 1)
+	if byteOrder == wkb.NDR {
+		flags |= flagLittleEndian
+	}
+	if extended(g) {
+		flags |= flagExtended
+	}
+	b := g.Bounds(geom.NewBounds())
+	empty := b.IsZero() || b.Empty()
+	if empty {
+		flags |= flagEmpty
+	}
+
+	dst := make([]byte, 8+8*n, 8+8*n+len(data))
+	copy(dst, magic)
+	dst[3] = flags
+	byteOrder.PutUint32(dst[4:], srid)
+	if n != 0 {
+		envelope := []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+		if !empty {
+			envelope = []float64{b.Min[geom.X], b.Max[geom.X], b.Min[geom.Y], b.Max[geom.Y]}
+		}
+		if envelopeType == EnvelopeXYZ || envelopeType == EnvelopeXYZM {
+			envelope = append(envelope, axisRange(g, 2)...)
+		}
+		if envelopeType == EnvelopeXYM || envelopeType == EnvelopeXYZM {
+			index := 2
+			if axes == geom.ZM {
+				index = 3
+			}
+			envelope = append(envelope, axisRange(g, index)...)
+		}
+		for i, v := range envelope {
+			byteOrder.PutUint64(dst[8+8*i:], math.Float64bits(v))
+		}
+	}
+	return append(dst, data...), nil
+}
+
+// extended reports whether g is or contains a geometry type outside the
+// core GeoPackage types.
+func extended(g geom.T) bool {
+	switch g := g.(type) {
+	case geom.Point, geom.LineString, geom.Polygon, geom.MultiPoint, geom.MultiLineString, geom.MultiPolygon:
+		return false
+	case geom.GeometryCollection:
+		for _, t := range g {
+			if extended(t) {
+				return true
+			}
+		}
+		return false
+	case geom.SRIDGeometry, geom.LayoutGeometry:
+		return extended(geom.Unwrap(g))
+	default:
+		return true
+	}
+}
+
+// axisRange returns the minimum and maximum of one component of the points
+// of g, or NaNs if there are none.
+func axisRange(g geom.T, index int) []float64 {
+	min, max := math.Inf(1), math.Inf(-1)
+	var visit func(geom.T)
+	points := func(points []geom.Point) {
+		for _, p := range points {
+			if index < len(p) {
+				min = math.Min(min, p[index])
+				max = math.Max(max, p[index])
+			}
+		}
+	}
+	visit = func(t geom.T) {
+		switch g := t.(type) {
+		case geom.Point:
+			points([]geom.Point{g})
+		case geom.LineString:
+			points(g)
+		case geom.MultiPoint:
+			points(g)
+		case geom.Polygon:
+			for _, ring := range g {
+				points(ring)
+			}
+		case geom.MultiLineString:
+			for _, lineString := range g {
+				points(lineString)
+			}
+		case geom.MultiPolygon:
+			for _, polygon := range g {
+				visit(polygon)
+			}
+		case geom.GeometryCollection:
+			for _, t := range g {
+				visit(t)
+			}
+		case geom.LayoutGeometry:
+			visit(g.T)
+		case geom.SRIDGeometry:
+			visit(g.T)
+		}
+	}
+	visit(g)
+
+	if min > max {
+		return []float64{math.NaN(), math.NaN()}
+	}
+	return []float64{min, max}
+}
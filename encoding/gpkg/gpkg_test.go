@@ -0,0 +1,133 @@
+package gpkg
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/encoding/wkb"
+)
+
+func TestGPKG(t *testing.T) {
+	var testCases = []struct {
+		g        geom.T
+		axes     uint32
+		envelope int
+		gpkg     string
+	}{
+		{
+			geom.NewSRIDGeometry(geom.Point{1, 2}, 4326),
+			geom.TwoD,
+			EnvelopeXY,
+			"47500003e6100000" +
+				"000000000000f03f000000000000f03f00000000000000400000000000000040" +
+				"0101000000000000000000f03f0000000000000040",
+		},
+		{
+			geom.Point{1, 2},
+			geom.TwoD,
+			NoEnvelope,
+			"4750000100000000" + "0101000000000000000000f03f0000000000000040",
+		},
+	}
+	for _, tc := range testCases {
+		got, err := Encode(tc.g, wkb.NDR, tc.axes, tc.envelope)
+		if err != nil || hex.EncodeToString(got) != tc.gpkg {
+			t.Errorf("Encode(%#v, NDR, %d, %d) == %x, %v, want %s, nil", tc.g, tc.axes, tc.envelope, got, err, tc.gpkg)
+		}
+	}
+
+	var roundTrips = []struct {
+		g        geom.T
+		axes     uint32
+		envelope int
+		want     []float64
+	}{
+		{geom.LineString{{1, 2}, {3, 4}}, geom.TwoD, EnvelopeXY, []float64{1, 3, 2, 4}},
+		{geom.Polygon{{{0, 0, 5}, {1, 0, 6}, {1, 1, 7}, {0, 0, 5}}}, geom.Z, EnvelopeXYZ, []float64{0, 1, 0, 1, 5, 7}},
+		{geom.NewLayoutGeometry(geom.MultiPoint{{1, 2, 3}, {4, 5, 6}}, geom.XYM), geom.M, EnvelopeXYM, []float64{1, 4, 2, 5, 3, 6}},
+		{geom.NewLayoutGeometry(geom.MultiLineString{{{1, 2, 3, 4}, {5, 6, 7, 8}}}, geom.XYZM), geom.ZM, EnvelopeXYZM, []float64{1, 5, 2, 6, 3, 7, 4, 8}},
+		{geom.NewSRIDGeometry(geom.GeometryCollection{geom.Point{1, 2}}, 3857), geom.TwoD, NoEnvelope, nil},
+		{geom.NewLayoutGeometry(geom.MultiPoint{{1, 2, 3}, {4, 5, 6}}, geom.XYM), geom.LayoutAxes, EnvelopeXYM, []float64{1, 4, 2, 5, 3, 6}},
+		{geom.NewLayoutGeometry(geom.LineString{{1, 2, 3, 4}, {5, 6, 7, 8}}, geom.XYZM), geom.LayoutAxes, EnvelopeXYM, []float64{1, 5, 2, 6, 4, 8}},
+		{geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.LayoutAxes, EnvelopeXYZ, []float64{1, 4, 2, 5, 3, 6}},
+	}
+	for _, tc := range roundTrips {
+		for _, byteOrder := range []interface{}{wkb.NDR, wkb.XDR} {
+			var data []byte
+			var err error
+			if byteOrder == wkb.NDR {
+				data, err = Encode(tc.g, wkb.NDR, tc.axes, tc.envelope)
+			} else {
+				data, err = Encode(tc.g, wkb.XDR, tc.axes, tc.envelope)
+			}
+			if err != nil {
+				t.Errorf("Encode(%#v) == %v", tc.g, err)
+				continue
+			}
+			h, _, err := DecodeHeader(data)
+			if err != nil || h.EnvelopeType != tc.envelope || !reflect.DeepEqual(h.Envelope, tc.want) || h.Empty || h.Extended {
+				t.Errorf("DecodeHeader(Encode(%#v)) == %#v, %v", tc.g, h, err)
+			}
+			if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Decode(Encode(%#v)) == %#v, %v", tc.g, got, err)
+			}
+		}
+	}
+
+	for _, g := range []geom.T{
+		geom.CircularString{{0, 0}, {1, 1}, {2, 0}},
+		geom.NewSRIDGeometry(geom.GeometryCollection{geom.Point{1, 2}, geom.CompoundCurve{geom.LineString{{0, 0}, {1, 1}}}}, 4326),
+	} {
+		data, err := Encode(g, wkb.NDR, geom.TwoD, EnvelopeXY)
+		if err != nil {
+			t.Errorf("Encode(%#v) == %v", g, err)
+			continue
+		}
+		if h, _, err := DecodeHeader(data); err != nil || !h.Extended {
+			t.Errorf("DecodeHeader(Encode(%#v)) == %#v, %v, want Extended", g, h, err)
+		}
+	}
+
+	for _, g := range []geom.T{geom.GeometryCollection{}, geom.Point{}} {
+		data, err := Encode(g, wkb.NDR, geom.TwoD, EnvelopeXY)
+		if err != nil {
+			t.Errorf("Encode(%#v) == %v", g, err)
+			continue
+		}
+		if h, _, err := DecodeHeader(data); err != nil || !h.Empty || !math.IsNaN(h.Envelope[0]) {
+			t.Errorf("DecodeHeader(Encode(%#v)) == %#v, %v", g, h, err)
+		}
+	}
+}
+
+func TestGPKGError(t *testing.T) {
+	if _, err := Encode(geom.Point{1, 2}, wkb.NDR, geom.TwoD, EnvelopeXYZ); !reflect.DeepEqual(err, InvalidEnvelopeError{EnvelopeXYZ}) {
+		t.Errorf("Encode with Z envelope and no Z == %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, wkb.NDR, geom.LayoutAxes, EnvelopeXYZ); !reflect.DeepEqual(err, InvalidEnvelopeError{EnvelopeXYZ}) {
+		t.Errorf("Encode with layout XY and Z envelope == %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, wkb.NDR, geom.TwoD, 5); !reflect.DeepEqual(err, InvalidEnvelopeError{5}) {
+		t.Errorf("Encode with envelope 5 == %#v", err)
+	}
+
+	var testCases = []struct {
+		gpkg string
+		err  error
+	}{
+		{"4750", FormatError{"header too short"}},
+		{"4751000100000000", FormatError{"bad magic"}},
+		{"4750010100000000", FormatError{"unsupported version 1"}},
+		{"4750000b00000000", InvalidEnvelopeError{5}},
+		{"4750000300000000", FormatError{"header too short"}},
+	}
+	for _, tc := range testCases {
+		data, _ := hex.DecodeString(tc.gpkg)
+		if _, err := Decode(data); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%s) == _, %#v, want %#v", tc.gpkg, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Package gpx reads and writes GPS Exchange Format files.
+//
+// Waypoints map to a MultiPoint, each route to a LineString and each track
+// to a MultiLineString with one LineString per segment. Points are
+// longitude, latitude and, depending on the axes requested, elevation as Z
+// and time as M. Times are seconds since the Unix epoch, kept to the
+// microsecond. A missing elevation or time is NaN.
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/foobaz/geom"
+)
+
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+type gpxFile struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Xmlns     string     `xml:"xmlns,attr,omitempty"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []gpxRoute `xml:"rte"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Ele  string `xml:"ele,omitempty"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name,omitempty"`
+	Desc   string     `xml:"desc,omitempty"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name,omitempty"`
+	Desc     string       `xml:"desc,omitempty"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "gpx: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("gpx: unsupported axes %d", e.Axes)
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+// InvalidValueError reports a coordinate, elevation or time that cannot be
+// parsed.
+type InvalidValueError struct {
+	Name  string
+	Value string
+}
+
+func (e InvalidValueError) Error() string {
+	return fmt.Sprintf("gpx: invalid %s %q", e.Name, e.Value)
+}
+
+// timeLayouts are tried in order; GPX times should be UTC with a zone, but
+// some writers leave it off.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+
+func parseTime(s string) (float64, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return float64(t.Unix()) + float64(t.Nanosecond())/1e9, nil
+		}
+	}
+	return 0, InvalidValueError{"time", s}
+}
+
+func formatTime(m float64) string {
+	sec := math.Floor(m)
+	usec := math.Round((m - sec) * 1e6)
+	return time.Unix(int64(sec), int64(usec)*1e3).UTC().Format(time.RFC3339Nano)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Decode parses a GPX document. See Read.
+func Decode(data []byte, axes uint32) (geom.FeatureCollection, error) {
+	return Read(bytes.NewReader(data), axes)
+}
+
+// Read parses a GPX document into a FeatureCollection of geom.Features: a
+// MultiPoint of all waypoints if there are any, then a LineString for each
+// route and a MultiLineString for each track. Route and track names and
+// descriptions become the name and desc properties. axes selects which of
+// elevation and time are kept in the points.
+func Read(r io.Reader, axes uint32) (geom.FeatureCollection, error) {
+	if geom.Layout(axes).Stride() == 0 {
+		return geom.FeatureCollection{}, UnsupportedAxesError{axes}
+	}
+
+	var f gpxFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return geom.FeatureCollection{}, err
+	}
+
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	if len(f.Waypoints) != 0 {
+		points, err := decodePoints(f.Waypoints, axes)
+		if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		fc = fc.AppendGeometry(geom.MultiPoint(points), nil)
+	}
+	for _, route := range f.Routes {
+		points, err := decodePoints(route.Points, axes)
+		if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		fc = fc.AppendGeometry(geom.LineString(points), properties(route.Name, route.Desc))
+	}
+	for _, track := range f.Tracks {
+		segments := make(geom.MultiLineString, len(track.Segments))
+		for i, segment := range track.Segments {
+			points, err := decodePoints(segment.Points, axes)
+			if err != nil {
+				return geom.FeatureCollection{}, err
+			}
+			segments[i] = points
+		}
+		fc = fc.AppendGeometry(segments, properties(track.Name, track.Desc))
+	}
+	return fc, nil
+}
+
+func properties(name, desc string) interface{} {
+	if name == "" && desc == "" {
+		return nil
+	}
+	p := make(map[string]interface{})
+	if name != "" {
+		p["name"] = name
+	}
+	if desc != "" {
+		p["desc"] = desc
+	}
+	return p
+}
+
+func decodePoints(points []gpxPoint, axes uint32) ([]geom.Point, error) {
+	decoded := make([]geom.Point, len(points))
+	for i, p := range points {
+		point := make(geom.Point, 2, geom.Layout(axes).Stride())
+		var err error
+		if point[geom.X], err = strconv.ParseFloat(strings.TrimSpace(p.Lon), 64); err != nil {
+			return nil, InvalidValueError{"lon", p.Lon}
+		}
+		if point[geom.Y], err = strconv.ParseFloat(strings.TrimSpace(p.Lat), 64); err != nil {
+			return nil, InvalidValueError{"lat", p.Lat}
+		}
+		if axes&geom.Z != 0 {
+			ele := math.NaN()
+			if s := strings.TrimSpace(p.Ele); s != "" {
+				if ele, err = strconv.ParseFloat(s, 64); err != nil {
+					return nil, InvalidValueError{"ele", p.Ele}
+				}
+			}
+			point = append(point, ele)
+		}
+		if axes&geom.M != 0 {
+			m := math.NaN()
+			if s := strings.TrimSpace(p.Time); s != "" {
+				if m, err = parseTime(s); err != nil {
+					return nil, err
+				}
+			}
+			point = append(point, m)
+		}
+		decoded[i] = point
+	}
+	return decoded, nil
+}
+
+// Encode returns a GPX 1.1 document. t may be a FeatureCollection, a
+// Feature or a bare geometry. Points and MultiPoints are written as
+// waypoints, LineStrings as routes and MultiLineStrings as tracks, taking
+// names and descriptions from the name and desc properties of a Feature.
+// axes selects which of elevation (Z) and time (M) are written; NaN values
+// are left out. SRIDs are dropped, and a Layout attached with
+// geom.NewLayoutGeometry must match axes.
+func Encode(t geom.T, axes uint32) ([]byte, error) {
+	if geom.Layout(axes).Stride() == 0 {
+		return nil, UnsupportedAxesError{axes}
+	}
+
+	f := gpxFile{Xmlns: Namespace, Version: "1.1", Creator: "github.com/foobaz/geom"}
+	features := []geom.T{t}
+	if fc, ok := t.(geom.FeatureCollection); ok {
+		features = fc.Features
+	}
+	for _, feature := range features {
+		if err := f.add(feature, axes); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := xml.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func Write(w io.Writer, t geom.T, axes uint32) error {
+	data, err := Encode(t, axes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (f *gpxFile) add(t geom.T, axes uint32) error {
+	var name, desc string
+	if feature, ok := t.(geom.Feature); ok {
+		if p, ok := feature.Properties.(map[string]interface{}); ok {
+			name, _ = p["name"].(string)
+			desc, _ = p["desc"].(string)
+		}
+		t = feature.T
+	}
+	if err := geom.MatchLayout(t, geom.Layout(axes)); err != nil {
+		return err
+	}
+	t = geom.Unwrap(t)
+
+	switch g := t.(type) {
+	case nil:
+		return nil
+	case geom.Point:
+		points, err := encodePoints([]geom.Point{g}, axes)
+		f.Waypoints = append(f.Waypoints, points...)
+		return err
+	case geom.MultiPoint:
+		points, err := encodePoints(g, axes)
+		f.Waypoints = append(f.Waypoints, points...)
+		return err
+	case geom.LineString:
+		points, err := encodePoints(g, axes)
+		f.Routes = append(f.Routes, gpxRoute{Name: name, Desc: desc, Points: points})
+		return err
+	case geom.MultiLineString:
+		track := gpxTrack{Name: name, Desc: desc, Segments: make([]gpxSegment, len(g))}
+		for i, lineString := range g {
+			points, err := encodePoints(lineString, axes)
+			if err != nil {
+				return err
+			}
+			track.Segments[i].Points = points
+		}
+		f.Tracks = append(f.Tracks, track)
+		return nil
+	default:
+		return UnsupportedGeometryError{reflect.TypeOf(t)}
+	}
+}
+
+func encodePoints(points []geom.Point, axes uint32) ([]gpxPoint, error) {
+	dimension := geom.Layout(axes).Stride()
+	encoded := make([]gpxPoint, len(points))
+	for i, point := range points {
+		if len(point) < dimension {
+			return nil, DimensionError{Dimension: dimension, ElementCount: len(point)}
+		}
+
+		p := gpxPoint{Lat: formatFloat(point[geom.Y]), Lon: formatFloat(point[geom.X])}
+		next := 2
+		if axes&geom.Z != 0 {
+			if ele := point[next]; !math.IsNaN(ele) {
+				p.Ele = formatFloat(ele)
+			}
+			next++
+		}
+		if axes&geom.M != 0 {
+			if m := point[next]; !math.IsNaN(m) {
+				p.Time = formatTime(m)
+			}
+		}
+		encoded[i] = p
+	}
+	return encoded, nil
+}
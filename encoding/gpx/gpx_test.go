@@ -0,0 +1,137 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="47.644548" lon="-122.326897">
+    <ele>4.46</ele>
+    <time>2009-10-17T18:37:26Z</time>
+    <name>start</name>
+  </wpt>
+  <wpt lat="47.6" lon="-122.3"></wpt>
+  <rte>
+    <name>route</name>
+    <rtept lat="1" lon="2"><ele>3</ele></rtept>
+    <rtept lat="4" lon="5"><time>1970-01-01T00:00:01.5Z</time></rtept>
+  </rte>
+  <trk>
+    <name>trace</name>
+    <desc>morning</desc>
+    <trkseg>
+      <trkpt lat="10" lon="20"><ele> 30 </ele><time>1970-01-01T00:01:00Z</time></trkpt>
+      <trkpt lat="11" lon="21"><ele>31</ele><time>1970-01-01T00:02:00</time></trkpt>
+    </trkseg>
+    <trkseg></trkseg>
+  </trk>
+</gpx>`
+
+func TestGPXDecode(t *testing.T) {
+	nan := math.NaN()
+	got, err := Decode([]byte(sample), geom.ZM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.FeatureCollection{Features: []geom.T{
+		geom.NewFeature(geom.MultiPoint{{-122.326897, 47.644548, 4.46, 1255804646}, {-122.3, 47.6, nan, nan}}, nil),
+		geom.NewFeature(geom.LineString{{2, 1, 3, nan}, {5, 4, nan, 1.5}}, map[string]interface{}{"name": "route"}),
+		geom.NewFeature(geom.MultiLineString{{{20, 10, 30, 60}, {21, 11, 31, 120}}, {}}, map[string]interface{}{"name": "trace", "desc": "morning"}),
+	}}
+	// NaN != NaN, so compare the formatted values
+	if fmt.Sprintf("%#v", got) != fmt.Sprintf("%#v", want) {
+		t.Errorf("Decode(sample, ZM) == %#v, want %#v", got, want)
+	}
+
+	got, err = Decode([]byte(sample), geom.TwoD)
+	track := got.Features[2].(geom.Feature).T
+	if want := (geom.MultiLineString{{{20, 10}, {21, 11}}, {}}); err != nil || !reflect.DeepEqual(track, want) {
+		t.Errorf("Decode(sample, TwoD) track == %#v, %v, want %#v, nil", track, err, want)
+	}
+
+	got, err = Decode([]byte(sample), geom.M)
+	route := got.Features[1].(geom.Feature).T.(geom.LineString)
+	if err != nil || len(route[0]) != 3 || !math.IsNaN(route[0][2]) || route[1][2] != 1.5 {
+		t.Errorf("Decode(sample, M) route == %#v, %v", route, err)
+	}
+}
+
+func TestGPX(t *testing.T) {
+	var testCases = []struct {
+		fc   geom.FeatureCollection
+		axes uint32
+	}{
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.MultiPoint{{1, 2}, {3, 4}}, nil),
+				geom.NewFeature(geom.LineString{{1, 2}, {3, 4}}, map[string]interface{}{"name": "r"}),
+				geom.NewFeature(geom.MultiLineString{{{1, 2}, {3, 4}}}, map[string]interface{}{"name": "t", "desc": "d"}),
+			}},
+			geom.TwoD,
+		},
+		{
+			geom.FeatureCollection{Features: []geom.T{
+				geom.NewFeature(geom.MultiLineString{{{1e-7, 2, 100.5, 1600000000.25}, {3, 4, -1, 1600000001}}}, nil),
+			}},
+			geom.ZM,
+		},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.fc, tc.axes)
+		if err != nil {
+			t.Errorf("Encode(%#v, %d) == %v, want nil", tc.fc, tc.axes, err)
+			continue
+		}
+		got, err := Decode(data, tc.axes)
+		if err != nil || !reflect.DeepEqual(got, tc.fc) {
+			t.Errorf("Decode(Encode(%#v, %d)) == %#v, %v, want %#v, nil", tc.fc, tc.axes, got, err, tc.fc)
+		}
+	}
+
+	// SRIDs and Layouts are dropped.
+	bare, err := Encode(geom.LineString{{1, 2, 60}}, geom.M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := geom.NewFeature(geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.LineString{{1, 2, 60}}, geom.XYM), 4326), nil)
+	if got, err := Encode(wrapped, geom.M); err != nil || string(got) != string(bare) {
+		t.Errorf("Encode(%#v, M) == %s, %v, want %s, nil", wrapped, got, err, bare)
+	}
+
+	data, err := Encode(geom.LineString{{1, 2, math.NaN(), 60}}, geom.ZM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); !strings.Contains(s, `<rtept lat="2" lon="1"><time>1970-01-01T00:01:00Z</time></rtept>`) || !strings.Contains(s, `xmlns="`+Namespace+`"`) {
+		t.Errorf("Encode == %s", s)
+	}
+}
+
+func TestGPXError(t *testing.T) {
+	if _, err := Encode(geom.Polygon{}, geom.TwoD); !reflect.DeepEqual(err, UnsupportedGeometryError{reflect.TypeOf(geom.Polygon{})}) {
+		t.Errorf("Encode(Polygon) error == %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, geom.Z); !reflect.DeepEqual(err, DimensionError{Dimension: 3, ElementCount: 2}) {
+		t.Errorf("Encode(Point{1, 2}, Z) error == %#v", err)
+	}
+	measured := geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)
+	if _, err := Encode(measured, geom.Z); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XYZ}) {
+		t.Errorf("Encode(%#v, Z) error == %#v", measured, err)
+	}
+	if _, err := Decode([]byte(sample), 5); !reflect.DeepEqual(err, UnsupportedAxesError{5}) {
+		t.Errorf("Decode(sample, 5) error == %#v", err)
+	}
+	if _, err := Decode([]byte(`<gpx><wpt lat="x" lon="1"/></gpx>`), geom.TwoD); !reflect.DeepEqual(err, InvalidValueError{"lat", "x"}) {
+		t.Errorf("Decode error == %#v", err)
+	}
+	if _, err := Decode([]byte(`<gpx><wpt lat="1" lon="1"><time>noon</time></wpt></gpx>`), geom.M); !reflect.DeepEqual(err, InvalidValueError{"time", "noon"}) {
+		t.Errorf("Decode error == %#v", err)
+	}
+}
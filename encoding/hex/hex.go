@@ -1,3 +1,8 @@
+// Package hex encodes and decodes WKB and PostGIS extended WKB as
+// hexadecimal strings, the text form of a PostGIS geometry. Axes and
+// layouts are handled as in package wkb: a geom.Layout attached to a
+// geometry must match the axes given, and EncodeLayout uses it without
+// needing any.
 package hex
 
 import (
@@ -8,13 +13,52 @@ import (
 	"github.com/foobaz/geom/encoding/wkb"
 )
 
-// axes must be geom.TwoD, geom.Z, geom.M, or geom.ZM
+// axes must be geom.TwoD, geom.Z, geom.M, geom.ZM, or geom.LayoutAxes to use
+// the geom.Layout of g.
 func Encode(g geom.T, byteOrder binary.ByteOrder, axes uint32) (string, error) {
-	wkb, err := wkb.Encode(g, byteOrder, axes)
+	data, err := Append(nil, g, byteOrder, axes)
+	return string(data), err
+}
+
+// EncodeLayout encodes g as hex ISO WKB with the axes of its geom.Layout.
+func EncodeLayout(g geom.T, byteOrder binary.ByteOrder) (string, error) {
+	return Encode(g, byteOrder, geom.LayoutAxes)
+}
+
+// Append appends the hex ISO WKB encoding of g to dst.
+func Append(dst []byte, g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	n := len(dst)
+	dst, err := wkb.Append(dst, g, byteOrder, axes)
+	if err != nil {
+		return nil, err
+	}
+	return expand(dst, n), nil
+}
+
+// AppendEWKB appends the hex PostGIS extended WKB encoding of g to dst,
+// including the SRID of a geom.SRIDGeometry.
+func AppendEWKB(dst []byte, g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	n := len(dst)
+	dst, err := wkb.AppendEWKB(dst, g, byteOrder, axes)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return expand(dst, n), nil
+}
+
+const digits = "0123456789abcdef"
+
+// expand hex encodes dst[n:] in place. Working back from the end, each
+// byte is read before its digits overwrite it.
+func expand(dst []byte, n int) []byte {
+	m := len(dst) - n
+	dst = append(dst, dst[n:]...)
+	for i := m - 1; i >= 0; i-- {
+		b := dst[n+i]
+		dst[n+2*i] = digits[b>>4]
+		dst[n+2*i+1] = digits[b&0x0f]
 	}
-	return hex.EncodeToString(wkb), nil
+	return dst
 }
 
 func Decode(s string) (geom.T, error) {
@@ -24,3 +68,10 @@ func Decode(s string) (geom.T, error) {
 	}
 	return wkb.Decode(data)
 }
+
+// EncodeEWKB encodes g as hex PostGIS extended WKB, including the SRID of a
+// geom.SRIDGeometry.
+func EncodeEWKB(g geom.T, byteOrder binary.ByteOrder, axes uint32) (string, error) {
+	data, err := AppendEWKB(nil, g, byteOrder, axes)
+	return string(data), err
+}
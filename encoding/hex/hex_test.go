@@ -3,6 +3,7 @@ package hex
 import (
 	"github.com/foobaz/geom"
 	"github.com/foobaz/geom/encoding/wkb"
+	"reflect"
 	"testing"
 )
 
@@ -21,4 +22,88 @@ func Test(t *testing.T) {
 			t.Errorf("Encode(%#v, %#v) == %#v, %#v, want %#v, nil", c.g, wkb.NDR, got, err, c.ndr)
 		}
 	}
+
+	g := geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)
+	want := "01d1070000000000000000f03f00000000000000400000000000000840"
+	if got, err := EncodeLayout(g, wkb.NDR); err != nil || got != want {
+		t.Errorf("EncodeLayout(%#v, %#v) == %#v, %#v, want %#v, nil", g, wkb.NDR, got, err, want)
+	}
+}
+
+func TestEWKB(t *testing.T) {
+	var cases = []struct {
+		g    geom.T
+		ewkb string
+		axes uint32
+	}{
+		{
+			geom.NewSRIDGeometry(geom.Point{1, 2}, 4326),
+			"0101000020e6100000000000000000f03f0000000000000040",
+			geom.TwoD,
+		},
+		{
+			geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326),
+			"01010000a0e6100000000000000000f03f00000000000000400000000000000840",
+			geom.Z,
+		},
+		{
+			geom.NewSRIDGeometry(geom.MultiPoint{{1, 2}, {3, 4}}, 4326),
+			"0104000020e6100000020000000101000000000000000000f03f0000000000000040010100000000000000000008400000000000001040",
+			geom.TwoD,
+		},
+		{
+			geom.NewSRIDGeometry(geom.Triangle{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}, 4326),
+			"0111000020e6100000010000000400000000000000000000000000000000000000000000000000f03f00000000000000000000000000000000000000000000f03f00000000000000000000000000000000",
+			geom.TwoD,
+		},
+		{
+			geom.NewLayoutGeometry(geom.LineString{{1, 2, 3, 4}, {5, 6, 7, 8}}, geom.XYZM),
+			"01020000c002000000000000000000f03f000000000000004000000000000008400000000000001040000000000000144000000000000018400000000000001c400000000000002040",
+			geom.ZM,
+		},
+	}
+	for _, c := range cases {
+		if got, err := EncodeEWKB(c.g, wkb.NDR, c.axes); err != nil || got != c.ewkb {
+			t.Errorf("EncodeEWKB(%#v, %#v, %d) == %#v, %#v, want %#v, nil", c.g, wkb.NDR, c.axes, got, err, c.ewkb)
+		}
+		if got, err := Decode(c.ewkb); err != nil || !reflect.DeepEqual(got, c.g) {
+			t.Errorf("Decode(%#v) == %#v, %#v, want %#v, nil", c.ewkb, got, err, c.g)
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add("0101000000000000000000f03f0000000000000040")
+	f.Add("0104000020e6100000020000000101000000000000000000f03f0000000000000040010100000000000000000008400000000000001040")
+	f.Add("0102000000ffffffff")
+	f.Fuzz(func(t *testing.T, s string) {
+		g, err := Decode(s)
+		if err != nil {
+			return
+		}
+		if _, err := EncodeEWKB(g, wkb.NDR, geom.LayoutAxes); err != nil {
+			t.Errorf("EncodeEWKB(Decode(%q)) == %v", s, err)
+		}
+	})
+}
+
+func TestAppend(t *testing.T) {
+	dst := []byte("prefix ")
+	g := geom.NewSRIDGeometry(geom.Point{1, 2}, 4326)
+	if got, err := Append(dst, g, wkb.NDR, geom.TwoD); err != nil || string(got) != "prefix 0101000000000000000000f03f0000000000000040" {
+		t.Errorf("Append(%q, %#v) == %q, %v", dst, g, got, err)
+	}
+	if got, err := AppendEWKB(dst[:0], g, wkb.NDR, geom.TwoD); err != nil || string(got) != "0101000020e6100000000000000000f03f0000000000000040" {
+		t.Errorf("AppendEWKB(%#v) == %q, %v", g, got, err)
+	}
+
+	g = geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM), 4326)
+	if got, err := AppendEWKB(nil, g, wkb.NDR, geom.LayoutAxes); err != nil || string(got) != "0101000060e6100000000000000000f03f00000000000000400000000000000840" {
+		t.Errorf("AppendEWKB(%#v, LayoutAxes) == %q, %v", g, got, err)
+	}
+
+	mixed := geom.LineString{{1, 2}, {3, 4, 5}}
+	if got, err := Encode(mixed, wkb.NDR, geom.Z); !reflect.DeepEqual(err, geom.DimensionError{Dimension: 2, ElementCount: 3}) {
+		t.Errorf("Encode(%#v, Z) == %q, %#v", mixed, got, err)
+	}
 }
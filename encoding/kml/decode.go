@@ -0,0 +1,312 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/foobaz/geom"
+)
+
+// Decode parses a KML document into a FeatureCollection holding a
+// geom.Feature for every Placemark, wherever it is nested in Documents and
+// Folders. See Read.
+func Decode(data []byte) (geom.FeatureCollection, error) {
+	return Read(bytes.NewReader(data))
+}
+
+// Read parses a KML document from r. Placemark ids become feature IDs.
+// Placemark name and description, and ExtendedData Data and SimpleData
+// values, become string properties. A MultiGeometry whose members all have
+// the same type becomes a MultiPoint, MultiLineString or MultiPolygon;
+// otherwise it becomes a GeometryCollection. A Placemark without a geometry
+// has a nil T.
+func Read(r io.Reader) (geom.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return fc, nil
+		} else if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "Placemark" {
+			feature, err := readPlacemark(d, start)
+			if err != nil {
+				return geom.FeatureCollection{}, err
+			}
+			fc.Features = append(fc.Features, feature)
+		}
+	}
+}
+
+// children calls f for each child element of the element just started,
+// returning after its end. f must consume the child, up to and including
+// its end element.
+func children(d *xml.Decoder, f func(xml.StartElement) error) error {
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := f(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// text returns the character data of the element just started.
+func text(d *xml.Decoder) (string, error) {
+	var s strings.Builder
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			s.Write(t)
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			return s.String(), nil
+		}
+	}
+}
+
+func attr(start xml.StartElement, name string) (string, bool) {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func isGeometry(name string) bool {
+	switch name {
+	case "Point", "LineString", "LinearRing", "Polygon", "MultiGeometry":
+		return true
+	default:
+		return false
+	}
+}
+
+func readPlacemark(d *xml.Decoder, start xml.StartElement) (geom.Feature, error) {
+	feature := geom.Feature{}
+	if id, ok := attr(start, "id"); ok {
+		feature.ID = id
+	}
+
+	properties := make(map[string]interface{})
+	err := children(d, func(child xml.StartElement) error {
+		switch name := child.Name.Local; {
+		case name == "name" || name == "description":
+			s, err := text(d)
+			properties[name] = s
+			return err
+		case name == "ExtendedData":
+			return readExtendedData(d, properties)
+		case isGeometry(name):
+			var err error
+			feature.T, err = readGeometry(d, child)
+			return err
+		default:
+			return d.Skip()
+		}
+	})
+	if err != nil {
+		return geom.Feature{}, err
+	}
+
+	if len(properties) != 0 {
+		feature.Properties = properties
+	}
+	return feature, nil
+}
+
+func readExtendedData(d *xml.Decoder, properties map[string]interface{}) error {
+	return children(d, func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "Data":
+			name, _ := attr(child, "name")
+			return children(d, func(value xml.StartElement) error {
+				if value.Name.Local != "value" {
+					return d.Skip()
+				}
+				s, err := text(d)
+				properties[name] = s
+				return err
+			})
+		case "SchemaData":
+			return children(d, func(data xml.StartElement) error {
+				if data.Name.Local != "SimpleData" {
+					return d.Skip()
+				}
+				name, _ := attr(data, "name")
+				s, err := text(d)
+				properties[name] = s
+				return err
+			})
+		default:
+			return d.Skip()
+		}
+	})
+}
+
+// coordinates reads the points in the coordinates children of the element
+// just started.
+func coordinates(d *xml.Decoder, element string) ([]geom.Point, error) {
+	points := []geom.Point{}
+	err := children(d, func(child xml.StartElement) error {
+		if child.Name.Local != "coordinates" {
+			return d.Skip()
+		}
+		s, err := text(d)
+		if err != nil {
+			return err
+		}
+
+		for _, tuple := range strings.Fields(s) {
+			values := strings.Split(tuple, ",")
+			if len(values) < 2 || len(values) > 3 {
+				return InvalidCoordinatesError{element, s}
+			}
+			point := make(geom.Point, len(values))
+			for i, v := range values {
+				if point[i], err = strconv.ParseFloat(v, 64); err != nil {
+					return InvalidCoordinatesError{element, s}
+				}
+			}
+			points = append(points, point)
+		}
+		return nil
+	})
+	return points, err
+}
+
+func readPolygon(d *xml.Decoder) (geom.Polygon, error) {
+	var outer geom.Ring
+	inner := []geom.Ring{}
+	err := children(d, func(boundary xml.StartElement) error {
+		name := boundary.Name.Local
+		if name != "outerBoundaryIs" && name != "innerBoundaryIs" {
+			return d.Skip()
+		}
+		return children(d, func(ring xml.StartElement) error {
+			if ring.Name.Local != "LinearRing" {
+				return d.Skip()
+			}
+			points, err := coordinates(d, "LinearRing")
+			if name == "outerBoundaryIs" {
+				outer = points
+			} else {
+				inner = append(inner, points)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if outer == nil {
+		return geom.Polygon{}, nil
+	}
+	return append(geom.Polygon{outer}, inner...), nil
+}
+
+func readGeometry(d *xml.Decoder, start xml.StartElement) (geom.T, error) {
+	switch start.Name.Local {
+	case "Point":
+		points, err := coordinates(d, "Point")
+		if err != nil {
+			return nil, err
+		}
+		if len(points) != 1 {
+			return nil, InvalidCoordinatesError{"Point", ""}
+		}
+		return points[0], nil
+	case "LineString", "LinearRing":
+		points, err := coordinates(d, start.Name.Local)
+		if err != nil {
+			return nil, err
+		}
+		return geom.LineString(points), nil
+	case "Polygon":
+		return readPolygon(d)
+	case "MultiGeometry":
+		return readMultiGeometry(d)
+	default:
+		return nil, d.Skip()
+	}
+}
+
+func readMultiGeometry(d *xml.Decoder) (geom.T, error) {
+	collection := geom.GeometryCollection{}
+	err := children(d, func(child xml.StartElement) error {
+		if !isGeometry(child.Name.Local) {
+			return d.Skip()
+		}
+		g, err := readGeometry(d, child)
+		collection = append(collection, g)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(collection) == 0 {
+		return collection, nil
+	}
+
+	switch collection[0].(type) {
+	case geom.Point:
+		multiPoint := geom.MultiPoint{}
+		for _, g := range collection {
+			point, ok := g.(geom.Point)
+			if !ok {
+				return collection, nil
+			}
+			multiPoint = append(multiPoint, point)
+		}
+		return multiPoint, nil
+	case geom.LineString:
+		multiLineString := geom.MultiLineString{}
+		for _, g := range collection {
+			lineString, ok := g.(geom.LineString)
+			if !ok {
+				return collection, nil
+			}
+			multiLineString = append(multiLineString, lineString)
+		}
+		return multiLineString, nil
+	case geom.Polygon:
+		multiPolygon := geom.MultiPolygon{}
+		for _, g := range collection {
+			polygon, ok := g.(geom.Polygon)
+			if !ok {
+				return collection, nil
+			}
+			multiPolygon = append(multiPolygon, polygon)
+		}
+		return multiPolygon, nil
+	default:
+		return collection, nil
+	}
+}
@@ -0,0 +1,241 @@
+// Package kml encodes and decodes Keyhole Markup Language documents.
+//
+// Geometries are written inside Placemarks, with the properties of a
+// geom.Feature written as ExtendedData. KML coordinates are longitude,
+// latitude and optional altitude, so only the first three components of
+// each point are written. KML has no measure: in a geom.LayoutGeometry with
+// layout geom.XYM or geom.XYZM the M values are dropped rather than written
+// as altitudes. The layout is found with geom.LayoutOf, so a bare point with
+// three components is taken to have an altitude.
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/foobaz/geom"
+)
+
+const Namespace = "http://www.opengis.net/kml/2.2"
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "kml: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedPropertiesError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedPropertiesError) Error() string {
+	return "kml: unsupported properties type: " + e.Type.String()
+}
+
+// InvalidCoordinatesError reports a coordinates element that cannot be
+// parsed, or has the wrong number of points for its geometry.
+type InvalidCoordinatesError struct {
+	Element     string
+	Coordinates string
+}
+
+func (e InvalidCoordinatesError) Error() string {
+	return fmt.Sprintf("kml: invalid coordinates in %s: %q", e.Element, e.Coordinates)
+}
+
+// Encode returns a KML document. A FeatureCollection is written as a
+// Document of Placemarks; a Feature or bare geometry as a single Placemark.
+// Multi-geometries and GeometryCollections are written as MultiGeometry.
+// Feature properties must be nil or a map[string]interface{}; values other
+// than strings are formatted with fmt.Sprint.
+func Encode(t geom.T) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<kml xmlns="` + Namespace + `">`)
+	switch g := t.(type) {
+	case geom.FeatureCollection:
+		b.WriteString("<Document>")
+		for _, feature := range g.Features {
+			if err := writePlacemark(&b, feature); err != nil {
+				return nil, err
+			}
+		}
+		b.WriteString("</Document>")
+	default:
+		if err := writePlacemark(&b, t); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteString("</kml>\n")
+	return b.Bytes(), nil
+}
+
+func Write(w io.Writer, t geom.T) error {
+	data, err := Encode(t)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeText(b *bytes.Buffer, s string) {
+	xml.EscapeText(b, []byte(s))
+}
+
+func writePlacemark(b *bytes.Buffer, t geom.T) error {
+	f, ok := t.(geom.Feature)
+	if !ok {
+		f = geom.NewFeature(t, nil)
+	}
+
+	b.WriteString("<Placemark")
+	if f.ID != nil {
+		b.WriteString(` id="`)
+		writeText(b, fmt.Sprint(f.ID))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+
+	if f.Properties != nil {
+		properties, ok := f.Properties.(map[string]interface{})
+		if !ok {
+			return UnsupportedPropertiesError{reflect.TypeOf(f.Properties)}
+		}
+		writeExtendedData(b, properties)
+	}
+	if f.T != nil {
+		layout, err := geom.LayoutOf(f.T)
+		if err != nil {
+			return err
+		}
+		// only XYZ and XYZM points carry an altitude
+		dimension := 2
+		if layout == geom.XYZ || layout == geom.XYZM {
+			dimension = 3
+		}
+		if err := writeGeometry(b, f.T, dimension); err != nil {
+			return err
+		}
+	}
+
+	b.WriteString("</Placemark>")
+	return nil
+}
+
+func writeExtendedData(b *bytes.Buffer, properties map[string]interface{}) {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("<ExtendedData>")
+	for _, name := range names {
+		b.WriteString(`<Data name="`)
+		writeText(b, name)
+		b.WriteString(`"><value>`)
+		switch v := properties[name].(type) {
+		case nil:
+		case string:
+			writeText(b, v)
+		default:
+			writeText(b, fmt.Sprint(v))
+		}
+		b.WriteString("</value></Data>")
+	}
+	b.WriteString("</ExtendedData>")
+}
+
+// writeCoordinates writes at most dimension components of each point.
+func writeCoordinates(b *bytes.Buffer, points []geom.Point, dimension int) {
+	b.WriteString("<coordinates>")
+	var dst []byte
+	for i, point := range points {
+		if i != 0 {
+			dst = append(dst, ' ')
+		}
+		for j, c := range point {
+			if j == dimension {
+				break
+			}
+			if j != 0 {
+				dst = append(dst, ',')
+			}
+			dst = strconv.AppendFloat(dst, c, 'f', -1, 64)
+		}
+	}
+	b.Write(dst)
+	b.WriteString("</coordinates>")
+}
+
+func writePolygon(b *bytes.Buffer, polygon geom.Polygon, dimension int) {
+	b.WriteString("<Polygon>")
+	for i, ring := range polygon {
+		if i == 0 {
+			b.WriteString("<outerBoundaryIs><LinearRing>")
+		} else {
+			b.WriteString("<innerBoundaryIs><LinearRing>")
+		}
+		writeCoordinates(b, ring, dimension)
+		if i == 0 {
+			b.WriteString("</LinearRing></outerBoundaryIs>")
+		} else {
+			b.WriteString("</LinearRing></innerBoundaryIs>")
+		}
+	}
+	b.WriteString("</Polygon>")
+}
+
+func writeGeometry(b *bytes.Buffer, t geom.T, dimension int) error {
+	switch g := t.(type) {
+	case geom.Point:
+		b.WriteString("<Point>")
+		writeCoordinates(b, []geom.Point{g}, dimension)
+		b.WriteString("</Point>")
+	case geom.LineString:
+		b.WriteString("<LineString>")
+		writeCoordinates(b, g, dimension)
+		b.WriteString("</LineString>")
+	case geom.Polygon:
+		writePolygon(b, g, dimension)
+	case geom.MultiPoint:
+		b.WriteString("<MultiGeometry>")
+		for _, point := range g {
+			writeGeometry(b, point, dimension)
+		}
+		b.WriteString("</MultiGeometry>")
+	case geom.MultiLineString:
+		b.WriteString("<MultiGeometry>")
+		for _, lineString := range g {
+			writeGeometry(b, lineString, dimension)
+		}
+		b.WriteString("</MultiGeometry>")
+	case geom.MultiPolygon:
+		b.WriteString("<MultiGeometry>")
+		for _, polygon := range g {
+			writePolygon(b, polygon, dimension)
+		}
+		b.WriteString("</MultiGeometry>")
+	case geom.GeometryCollection:
+		b.WriteString("<MultiGeometry>")
+		for _, t := range g {
+			if err := writeGeometry(b, t, dimension); err != nil {
+				return err
+			}
+		}
+		b.WriteString("</MultiGeometry>")
+	case geom.SRIDGeometry, geom.LayoutGeometry:
+		return writeGeometry(b, geom.Unwrap(g), dimension)
+	default:
+		return UnsupportedGeometryError{reflect.TypeOf(t)}
+	}
+	return nil
+}
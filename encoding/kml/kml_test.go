@@ -0,0 +1,182 @@
+package kml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestKML(t *testing.T) {
+	var testCases = []geom.T{
+		geom.Point{1, 2},
+		geom.Point{1, 2, 3},
+		geom.LineString{{1, 2}, {3, 4}},
+		geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}},
+		geom.MultiPoint{{1, 2}, {3, 4}},
+		geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+		geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}},
+		geom.GeometryCollection{geom.Point{1, 2}, geom.LineString{{1, 2}, {3, 4}}},
+		geom.GeometryCollection{},
+	}
+	for _, g := range testCases {
+		data, err := Encode(g)
+		if err != nil {
+			t.Errorf("Encode(%#v) == %v, want nil", g, err)
+			continue
+		}
+		got, err := Decode(data)
+		want := geom.FeatureCollection{Features: []geom.T{geom.Feature{T: g}}}
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(Encode(%#v)) == %#v, %v, want %#v, nil", g, got, err, want)
+		}
+	}
+}
+
+func TestKMLMeasures(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{geom.NewLayoutGeometry(geom.Point{1, 2, 9}, geom.XYM), geom.Point{1, 2}},
+		{geom.NewLayoutGeometry(geom.LineString{{1, 2, 3, 9}, {4, 5, 6, 9}}, geom.XYZM), geom.LineString{{1, 2, 3}, {4, 5, 6}}},
+		{geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYZ), geom.Point{1, 2, 3}},
+		{geom.GeometryCollection{geom.NewLayoutGeometry(geom.Point{1, 2, 9}, geom.XYM)}, geom.MultiPoint{{1, 2}}},
+		{geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.MultiPoint{{1, 2, 9}}, geom.XYM), 4326), geom.MultiPoint{{1, 2}}},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.g)
+		if err != nil {
+			t.Errorf("Encode(%#v) == %v, want nil", tc.g, err)
+			continue
+		}
+		got, err := Decode(data)
+		want := geom.FeatureCollection{Features: []geom.T{geom.Feature{T: tc.want}}}
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(Encode(%#v)) == %#v, %v, want %#v, nil", tc.g, got, err, want)
+		}
+	}
+}
+
+func TestKMLFeatureCollection(t *testing.T) {
+	fc := geom.FeatureCollection{Features: []geom.T{
+		geom.Feature{
+			T:          geom.Point{1, 2},
+			Properties: map[string]interface{}{"name": "a & b", "count": 3, "empty": nil},
+			ID:         "p1",
+		},
+		geom.Feature{},
+	}}
+	data, err := Encode(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(data)
+	want := geom.FeatureCollection{Features: []geom.T{
+		geom.Feature{
+			T:          geom.Point{1, 2},
+			Properties: map[string]interface{}{"name": "a & b", "count": "3", "empty": ""},
+			ID:         "p1",
+		},
+		geom.Feature{},
+	}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(Encode(%#v)) == %#v, %v, want %#v, nil", fc, got, err, want)
+	}
+}
+
+func TestKMLDecode(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>survey</name>
+    <Style id="s"><LineStyle><width>2</width></LineStyle></Style>
+    <Placemark>
+      <name>well</name>
+      <description>dry</description>
+      <Point><altitudeMode>absolute</altitudeMode><coordinates> -122.1,37.4,12 </coordinates></Point>
+    </Placemark>
+    <Folder>
+      <name>north</name>
+      <Folder>
+        <Placemark id="fence">
+          <ExtendedData>
+            <SchemaData schemaUrl="#schema">
+              <SimpleData name="owner">county</SimpleData>
+            </SchemaData>
+            <Data name="height"><displayName>Height</displayName><value>2</value></Data>
+          </ExtendedData>
+          <LineString>
+            <tessellate>1</tessellate>
+            <coordinates>
+              -122.0,37.0
+              -122.5,37.5
+            </coordinates>
+          </LineString>
+        </Placemark>
+      </Folder>
+    </Folder>
+  </Document>
+</kml>`
+	got, err := Decode([]byte(data))
+	want := geom.FeatureCollection{Features: []geom.T{
+		geom.Feature{
+			T:          geom.Point{-122.1, 37.4, 12},
+			Properties: map[string]interface{}{"name": "well", "description": "dry"},
+		},
+		geom.Feature{
+			T:          geom.LineString{{-122.0, 37.0}, {-122.5, 37.5}},
+			Properties: map[string]interface{}{"owner": "county", "height": "2"},
+			ID:         "fence",
+		},
+	}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	if got := string(mustEncode(t, geom.Point{1e-7, 2})); !strings.Contains(got, "<coordinates>0.0000001,2</coordinates>") {
+		t.Errorf("Encode(Point{1e-7, 2}) == %s, want decimal coordinates", got)
+	}
+}
+
+func mustEncode(t *testing.T, g geom.T) []byte {
+	data, err := Encode(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestKMLError(t *testing.T) {
+	if _, err := Encode(geom.NewFeature(geom.Point{1, 2}, "name")); !reflect.DeepEqual(err, UnsupportedPropertiesError{reflect.TypeOf("")}) {
+		t.Errorf("Encode error == %#v, want UnsupportedPropertiesError", err)
+	}
+	if _, err := Encode(geom.GeometryCollection{geom.FeatureCollection{}}); !reflect.DeepEqual(err, UnsupportedGeometryError{reflect.TypeOf(geom.FeatureCollection{})}) {
+		t.Errorf("Encode error == %#v, want UnsupportedGeometryError", err)
+	}
+	mixed := geom.GeometryCollection{geom.NewLayoutGeometry(geom.Point{1, 2, 9}, geom.XYM), geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYZ)}
+	if _, err := Encode(mixed); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYZ, Other: geom.XYM}) {
+		t.Errorf("Encode error == %#v, want LayoutMismatchError", err)
+	}
+	if _, err := Encode(geom.LineString{{1, 2}, {3, 4, 5}}); !reflect.DeepEqual(err, geom.DimensionError{Dimension: 2, ElementCount: 3}) {
+		t.Errorf("Encode error == %#v, want DimensionError", err)
+	}
+
+	var testCases = []struct {
+		kml string
+		err error
+	}{
+		{"<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>", InvalidCoordinatesError{"Point", "1"}},
+		{"<kml><Placemark><LineString><coordinates>1,x</coordinates></LineString></Placemark></kml>", InvalidCoordinatesError{"LineString", "1,x"}},
+		{"<kml><Placemark><Point><coordinates>1,2 3,4</coordinates></Point></Placemark></kml>", InvalidCoordinatesError{"Point", ""}},
+	}
+	for _, tc := range testCases {
+		if _, err := Decode([]byte(tc.kml)); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%q) == _, %#v, want %#v", tc.kml, err, tc.err)
+		}
+	}
+	if _, err := Decode([]byte("<kml><Placemark>")); err == nil {
+		t.Errorf("Decode of truncated document succeeded")
+	}
+}
@@ -0,0 +1,252 @@
+package mvt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+// Decode decodes a tile. Geometries are in tile coordinates and each
+// feature is a geom.Feature whose properties hold its tags; the ID is a
+// uint64 if the feature has one. Tag values are strings, float64s, int64s,
+// uint64s or bools.
+func Decode(data []byte) ([]Layer, error) {
+	layers := []Layer{}
+	err := fields(data, func(num int, wire int, v uint64, b []byte) error {
+		if num != tileLayers {
+			return nil
+		}
+		layer, err := decodeLayer(b)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, layer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+func decodeLayer(data []byte) (Layer, error) {
+	layer := Layer{Extent: DefaultExtent, Features: []geom.T{}}
+	var features [][]byte
+	var keys []string
+	var values []interface{}
+	version := uint64(1)
+	err := fields(data, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case layerVersion:
+			version = v
+		case layerName:
+			layer.Name = string(b)
+		case layerFeatures:
+			features = append(features, b)
+		case layerKeys:
+			keys = append(keys, string(b))
+		case layerValues:
+			value, err := decodeValue(b)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		case layerExtent:
+			layer.Extent = int(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return Layer{}, err
+	}
+	if version > 2 {
+		return Layer{}, FormatError{fmt.Sprintf("unsupported version %d", version)}
+	}
+	if layer.Extent <= 0 {
+		return Layer{}, FormatError{fmt.Sprintf("invalid extent %d", layer.Extent)}
+	}
+
+	for _, data := range features {
+		f, err := decodeFeature(data, keys, values)
+		if err != nil {
+			return Layer{}, err
+		}
+		layer.Features = append(layer.Features, f)
+	}
+	return layer, nil
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	err := fields(data, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case valueString:
+			value = string(b)
+		case valueFloat:
+			value = float64(math.Float32frombits(uint32(v)))
+		case valueDouble:
+			value = math.Float64frombits(v)
+		case valueInt:
+			value = int64(v)
+		case valueUint:
+			value = v
+		case valueSint:
+			value = unzigzag(v)
+		case valueBool:
+			value = v != 0
+		}
+		return nil
+	})
+	if err == nil && value == nil {
+		err = FormatError{"value has no type"}
+	}
+	return value, err
+}
+
+func decodeFeature(data []byte, keys []string, values []interface{}) (geom.Feature, error) {
+	var id interface{}
+	var tags, geometry []uint64
+	geometryType := uint64(typeUnknown)
+	err := fields(data, func(num int, wire int, v uint64, b []byte) (err error) {
+		switch num {
+		case featureID:
+			id = v
+		case featureTags:
+			tags, err = appendVarints(tags, wire, v, b)
+		case featureType:
+			geometryType = v
+		case featureGeometry:
+			geometry, err = appendVarints(geometry, wire, v, b)
+		}
+		return err
+	})
+	if err != nil {
+		return geom.Feature{}, err
+	}
+
+	if len(tags)%2 != 0 {
+		return geom.Feature{}, FormatError{"odd number of tags"}
+	}
+	properties := make(map[string]interface{}, len(tags)/2)
+	for i := 0; i < len(tags); i += 2 {
+		if tags[i] >= uint64(len(keys)) || tags[i+1] >= uint64(len(values)) {
+			return geom.Feature{}, FormatError{"tag index out of range"}
+		}
+		properties[keys[tags[i]]] = values[tags[i+1]]
+	}
+
+	t, err := decodeGeometry(geometryType, geometry)
+	if err != nil {
+		return geom.Feature{}, err
+	}
+	f := geom.NewFeature(t, properties)
+	f.ID = id
+	return f, nil
+}
+
+// part is a run of points begun by a MoveTo command.
+type part struct {
+	points []geom.Point
+	closed bool
+}
+
+func parseCommands(geometry []uint64) ([]part, error) {
+	var parts []part
+	var x, y int64
+	for i := 0; i < len(geometry); {
+		id, count := int(geometry[i]&7), int(geometry[i]>>3)
+		i++
+		switch id {
+		case commandMoveTo, commandLineTo:
+			if count == 0 || count > (len(geometry)-i)/2 {
+				return nil, FormatError{"command runs past end of geometry"}
+			}
+			if id == commandLineTo && (len(parts) == 0 || parts[len(parts)-1].closed) {
+				return nil, FormatError{"LineTo without MoveTo"}
+			}
+			for j := 0; j < count; j++ {
+				x += unzigzag(geometry[i])
+				y += unzigzag(geometry[i+1])
+				i += 2
+				p := geom.Point{float64(x), float64(y)}
+				if id == commandMoveTo {
+					parts = append(parts, part{points: []geom.Point{p}})
+				} else {
+					last := &parts[len(parts)-1]
+					last.points = append(last.points, p)
+				}
+			}
+		case commandClosePath:
+			if count != 1 || len(parts) == 0 || parts[len(parts)-1].closed {
+				return nil, FormatError{"invalid ClosePath"}
+			}
+			last := &parts[len(parts)-1]
+			last.points = append(last.points, last.points[0])
+			last.closed = true
+		default:
+			return nil, FormatError{fmt.Sprintf("unknown command %d", id)}
+		}
+	}
+	return parts, nil
+}
+
+func decodeGeometry(geometryType uint64, geometry []uint64) (geom.T, error) {
+	parts, err := parseCommands(geometry)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	switch geometryType {
+	case typePoint:
+		mp := make(geom.MultiPoint, len(parts))
+		for i, part := range parts {
+			if len(part.points) != 1 || part.closed {
+				return nil, FormatError{"point geometry has lines"}
+			}
+			mp[i] = part.points[0]
+		}
+		if len(mp) == 1 {
+			return mp[0], nil
+		}
+		return mp, nil
+	case typeLineString:
+		mls := make(geom.MultiLineString, len(parts))
+		for i, part := range parts {
+			if len(part.points) < 2 || part.closed {
+				return nil, FormatError{"invalid line"}
+			}
+			mls[i] = part.points
+		}
+		if len(mls) == 1 {
+			return mls[0], nil
+		}
+		return mls, nil
+	case typePolygon:
+		mp := geom.MultiPolygon{}
+		for _, part := range parts {
+			if !part.closed || len(part.points) < 4 {
+				return nil, FormatError{"invalid ring"}
+			}
+			ring := geom.Ring(part.points)
+			switch a := planar.SignedArea(ring); {
+			case a > 0:
+				mp = append(mp, geom.Polygon{ring})
+			case a < 0:
+				if len(mp) == 0 {
+					return nil, FormatError{"interior ring before exterior ring"}
+				}
+				mp[len(mp)-1] = append(mp[len(mp)-1], ring)
+			}
+		}
+		if len(mp) == 1 {
+			return mp[0], nil
+		}
+		return mp, nil
+	}
+	return nil, FormatError{fmt.Sprintf("unknown geometry type %d", geometryType)}
+}
@@ -0,0 +1,348 @@
+package mvt
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+// Geometry commands.
+const (
+	commandMoveTo    = 1
+	commandLineTo    = 2
+	commandClosePath = 7
+)
+
+// vec is a point in tile coordinates before rounding.
+type vec [2]float64
+
+// ipoint is a point in tile coordinates after rounding.
+type ipoint [2]int64
+
+// transform maps Web Mercator meters to tile coordinates and clips to the
+// square from min to max on both axes.
+type transform struct {
+	bounds   geom.Bounds
+	extent   float64
+	min, max float64
+}
+
+func (tr *transform) project(p geom.Point) (vec, error) {
+	if len(p) < 2 {
+		return vec{}, DimensionError{Dimension: 2, ElementCount: len(p)}
+	}
+	b := tr.bounds
+	return vec{
+		(p[geom.X] - b.Min[geom.X]) / (b.Max[geom.X] - b.Min[geom.X]) * tr.extent,
+		(b.Max[geom.Y] - p[geom.Y]) / (b.Max[geom.Y] - b.Min[geom.Y]) * tr.extent,
+	}, nil
+}
+
+func (tr *transform) projectAll(ps []geom.Point) ([]vec, error) {
+	vs := make([]vec, len(ps))
+	for i, p := range ps {
+		v, err := tr.project(p)
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+func (tr *transform) contains(v vec) bool {
+	return v[0] >= tr.min && v[0] <= tr.max && v[1] >= tr.min && v[1] <= tr.max
+}
+
+// clipSegment clips the segment from a to b with the Liang-Barsky
+// algorithm.
+func (tr *transform) clipSegment(a, b vec) (vec, vec, bool) {
+	t0, t1 := 0.0, 1.0
+	d := vec{b[0] - a[0], b[1] - a[1]}
+	for axis := 0; axis < 2; axis++ {
+		for _, edge := range [2][2]float64{{-d[axis], a[axis] - tr.min}, {d[axis], tr.max - a[axis]}} {
+			p, q := edge[0], edge[1]
+			if p == 0 {
+				if q < 0 {
+					return a, b, false
+				}
+				continue
+			}
+			r := q / p
+			if p < 0 {
+				if r > t1 {
+					return a, b, false
+				}
+				t0 = math.Max(t0, r)
+			} else {
+				if r < t0 {
+					return a, b, false
+				}
+				t1 = math.Min(t1, r)
+			}
+		}
+	}
+	start, end := a, b
+	if t0 > 0 {
+		start = vec{a[0] + t0*d[0], a[1] + t0*d[1]}
+	}
+	if t1 < 1 {
+		end = vec{a[0] + t1*d[0], a[1] + t1*d[1]}
+	}
+	return start, end, true
+}
+
+// clipLine splits a line into the parts inside the clip square.
+func (tr *transform) clipLine(vs []vec) [][]vec {
+	var parts [][]vec
+	var part []vec
+	for i := 0; i+1 < len(vs); i++ {
+		a, b, ok := tr.clipSegment(vs[i], vs[i+1])
+		if !ok {
+			if len(part) != 0 {
+				parts, part = append(parts, part), nil
+			}
+			continue
+		}
+		if len(part) == 0 {
+			part = append(part, a)
+		}
+		part = append(part, b)
+		if b != vs[i+1] {
+			parts, part = append(parts, part), nil
+		}
+	}
+	if len(part) != 0 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// clipRing clips an open ring to the clip square with the
+// Sutherland-Hodgman algorithm.
+func (tr *transform) clipRing(ring []vec) []vec {
+	for edge := 0; edge < 4 && len(ring) != 0; edge++ {
+		axis, bound := edge/2, tr.min
+		if edge%2 == 1 {
+			bound = tr.max
+		}
+		inside := func(v vec) bool {
+			if edge%2 == 0 {
+				return v[axis] >= bound
+			}
+			return v[axis] <= bound
+		}
+		intersect := func(a, b vec) vec {
+			t := (bound - a[axis]) / (b[axis] - a[axis])
+			v := vec{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+			v[axis] = bound
+			return v
+		}
+
+		var clipped []vec
+		for i, v := range ring {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			if inside(v) {
+				if !inside(prev) {
+					clipped = append(clipped, intersect(prev, v))
+				}
+				clipped = append(clipped, v)
+			} else if inside(prev) {
+				clipped = append(clipped, intersect(prev, v))
+			}
+		}
+		ring = clipped
+	}
+	return ring
+}
+
+// round converts to integer tile coordinates, dropping repeated points.
+func round(vs []vec) []ipoint {
+	ps := make([]ipoint, 0, len(vs))
+	for _, v := range vs {
+		p := ipoint{int64(math.Round(v[0])), int64(math.Round(v[1]))}
+		if len(ps) == 0 || ps[len(ps)-1] != p {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// area is twice the signed area of an open ring in tile coordinates. It is
+// positive for exterior rings, which wind clockwise with y pointing down.
+func area(ring []ipoint) int64 {
+	var a int64
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a
+}
+
+func (tr *transform) points(ps []geom.Point) ([]ipoint, error) {
+	vs, err := tr.projectAll(ps)
+	if err != nil {
+		return nil, err
+	}
+	var out []ipoint
+	for _, v := range vs {
+		if tr.contains(v) {
+			out = append(out, round([]vec{v})...)
+		}
+	}
+	return out, nil
+}
+
+func (tr *transform) lines(ls []geom.LineString) ([][]ipoint, error) {
+	var out [][]ipoint
+	for _, l := range ls {
+		vs, err := tr.projectAll(l)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range tr.clipLine(vs) {
+			if ps := round(part); len(ps) >= 2 {
+				out = append(out, ps)
+			}
+		}
+	}
+	return out, nil
+}
+
+// ring returns a clipped, rounded open ring wound so that its area has the
+// sign wanted, or nil if nothing with any area is left.
+func (tr *transform) ring(r geom.Ring, sign int64) ([]ipoint, error) {
+	vs, err := tr.projectAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(vs) > 1 && vs[0] == vs[len(vs)-1] {
+		vs = vs[:len(vs)-1]
+	}
+	ps := round(tr.clipRing(vs))
+	if len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+		ps = ps[:len(ps)-1]
+	}
+	a := area(ps)
+	if len(ps) < 3 || a == 0 {
+		return nil, nil
+	}
+	if a*sign < 0 {
+		for i, j := 0, len(ps)-1; i < j; i, j = i+1, j-1 {
+			ps[i], ps[j] = ps[j], ps[i]
+		}
+	}
+	return ps, nil
+}
+
+func (tr *transform) polygons(polygons []geom.Polygon) ([][]ipoint, error) {
+	var out [][]ipoint
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		exterior, err := tr.ring(polygon[0], 1)
+		if err != nil {
+			return nil, err
+		}
+		if exterior == nil {
+			continue
+		}
+		out = append(out, exterior)
+		for _, r := range polygon[1:] {
+			interior, err := tr.ring(r, -1)
+			if err != nil {
+				return nil, err
+			}
+			if interior != nil {
+				out = append(out, interior)
+			}
+		}
+	}
+	return out, nil
+}
+
+// commands accumulates a geometry's command integers. Parameters are
+// offsets from the cursor, which carries over from one part to the next.
+type commands struct {
+	data   []uint32
+	cursor ipoint
+}
+
+func (c *commands) command(id, count int) {
+	c.data = append(c.data, uint32(id&7|count<<3))
+}
+
+func (c *commands) point(p ipoint) {
+	c.data = append(c.data, uint32(zigzag(p[0]-c.cursor[0])), uint32(zigzag(p[1]-c.cursor[1])))
+	c.cursor = p
+}
+
+// encode returns the geometry type and commands for t. A geometry clipped
+// away entirely has no commands.
+func (tr *transform) encode(t geom.T) (int, []uint32, error) {
+	var c commands
+	switch t := t.(type) {
+	case geom.Point:
+		ps, err := tr.points([]geom.Point{t})
+		if err != nil || len(ps) == 0 {
+			return typePoint, nil, err
+		}
+		c.command(commandMoveTo, 1)
+		c.point(ps[0])
+		return typePoint, c.data, nil
+	case geom.MultiPoint:
+		ps, err := tr.points(t)
+		if err != nil || len(ps) == 0 {
+			return typePoint, nil, err
+		}
+		c.command(commandMoveTo, len(ps))
+		for _, p := range ps {
+			c.point(p)
+		}
+		return typePoint, c.data, nil
+	case geom.LineString, geom.MultiLineString:
+		var ls []geom.LineString
+		if l, ok := t.(geom.LineString); ok {
+			ls = []geom.LineString{l}
+		} else {
+			ls = t.(geom.MultiLineString)
+		}
+		parts, err := tr.lines(ls)
+		if err != nil {
+			return typeLineString, nil, err
+		}
+		for _, part := range parts {
+			c.command(commandMoveTo, 1)
+			c.point(part[0])
+			c.command(commandLineTo, len(part)-1)
+			for _, p := range part[1:] {
+				c.point(p)
+			}
+		}
+		return typeLineString, c.data, nil
+	case geom.Polygon, geom.MultiPolygon:
+		var polygons []geom.Polygon
+		if p, ok := t.(geom.Polygon); ok {
+			polygons = []geom.Polygon{p}
+		} else {
+			polygons = t.(geom.MultiPolygon)
+		}
+		rings, err := tr.polygons(polygons)
+		if err != nil {
+			return typePolygon, nil, err
+		}
+		for _, ring := range rings {
+			c.command(commandMoveTo, 1)
+			c.point(ring[0])
+			c.command(commandLineTo, len(ring)-1)
+			for _, p := range ring[1:] {
+				c.point(p)
+			}
+			c.command(commandClosePath, 1)
+		}
+		return typePolygon, c.data, nil
+	}
+	return typeUnknown, nil, UnsupportedGeometryError{reflect.TypeOf(t)}
+}
@@ -0,0 +1,326 @@
+// Package mvt encodes and decodes Mapbox Vector Tiles, version 2.
+//
+// Geometries to encode are in Web Mercator meters (EPSG:3857), the same
+// projection carto draws raster tiles in. They are scaled to the tile's
+// extent, clipped to the tile plus a buffer and rounded to integers.
+// Decoded geometries are left in tile coordinates, with the origin at the
+// top left corner and y increasing downwards.
+//
+// Points, LineStrings and Polygons and their Multi variants are supported.
+// Only the X and Y components of each point are encoded.
+package mvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/foobaz/geom"
+)
+
+// DefaultExtent is the number of units across a tile used when a Layer's
+// Extent is zero.
+const DefaultExtent = 4096
+
+// originShift is half the circumference of the earth in Web Mercator
+// meters.
+const originShift = math.Pi * 6378137
+
+// A Tile identifies a tile in the XYZ scheme, where tile 0/0/0 covers the
+// whole world and y increases southwards.
+type Tile struct {
+	Z, X, Y int
+}
+
+// Bounds returns the extent of the tile in Web Mercator meters.
+func (t Tile) Bounds() geom.Bounds {
+	size := 2 * originShift / math.Exp2(float64(t.Z))
+	return geom.Bounds{
+		Min: geom.Point{-originShift + float64(t.X)*size, originShift - float64(t.Y+1)*size},
+		Max: geom.Point{-originShift + float64(t.X+1)*size, originShift - float64(t.Y)*size},
+	}
+}
+
+func (t Tile) valid() bool {
+	if t.Z < 0 || t.Z > 30 {
+		return false
+	}
+	n := 1 << uint(t.Z)
+	return t.X >= 0 && t.X < n && t.Y >= 0 && t.Y < n
+}
+
+// A Layer is a named set of features. Each element of Features is either a
+// geom.Feature, whose properties become the feature's tags and whose ID, if
+// a non-negative integer, becomes the feature's id, or a bare geometry.
+// Property values that are not strings, numbers or booleans are stored as
+// JSON strings; nil values are left out.
+type Layer struct {
+	Name     string
+	Extent   int
+	Features []geom.T
+}
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "mvt: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedPropertiesError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedPropertiesError) Error() string {
+	return "mvt: unsupported properties type: " + e.Type.String()
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+type InvalidTileError struct {
+	Tile Tile
+}
+
+func (e InvalidTileError) Error() string {
+	return fmt.Sprintf("mvt: invalid tile %d/%d/%d", e.Tile.Z, e.Tile.X, e.Tile.Y)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "mvt: " + e.Msg
+}
+
+// Geometry types.
+const (
+	typeUnknown    = 0
+	typePoint      = 1
+	typeLineString = 2
+	typePolygon    = 3
+)
+
+// Message field numbers.
+const (
+	tileLayers = 3
+
+	layerVersion  = 15
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueFloat  = 2
+	valueDouble = 3
+	valueInt    = 4
+	valueUint   = 5
+	valueSint   = 6
+	valueBool   = 7
+)
+
+// Encode encodes layers as tile t. Geometries are clipped to the tile
+// enlarged by buffer units on each side; features left with no geometry
+// are dropped.
+func Encode(t Tile, buffer int, layers ...Layer) ([]byte, error) {
+	if !t.valid() {
+		return nil, InvalidTileError{t}
+	}
+	var buf []byte
+	for _, layer := range layers {
+		data, err := encodeLayer(t.Bounds(), buffer, layer)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendBytes(buf, tileLayers, data)
+	}
+	return buf, nil
+}
+
+// table assigns indexes to keys and values in order of first use.
+type table struct {
+	keys       []string
+	values     []interface{}
+	keyIndex   map[string]uint64
+	valueIndex map[interface{}]uint64
+}
+
+func (tb *table) key(k string) uint64 {
+	i, ok := tb.keyIndex[k]
+	if !ok {
+		i = uint64(len(tb.keys))
+		tb.keyIndex[k] = i
+		tb.keys = append(tb.keys, k)
+	}
+	return i
+}
+
+func (tb *table) value(v interface{}) uint64 {
+	i, ok := tb.valueIndex[v]
+	if !ok {
+		i = uint64(len(tb.values))
+		tb.valueIndex[v] = i
+		tb.values = append(tb.values, v)
+	}
+	return i
+}
+
+// normalize converts a property value to one of the types a Value message
+// holds: string, float32, float64, int64, uint64 or bool.
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string, float32, float64, bool:
+		return v, nil
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func appendValue(dst []byte, v interface{}) []byte {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		data = appendBytes(nil, valueString, []byte(v))
+	case float32:
+		data = appendFixed32(nil, valueFloat, math.Float32bits(v))
+	case float64:
+		data = appendFixed64(nil, valueDouble, math.Float64bits(v))
+	case int64:
+		if v < 0 {
+			data = appendVarint(nil, valueSint, zigzag(v))
+		} else {
+			data = appendVarint(nil, valueInt, uint64(v))
+		}
+	case uint64:
+		data = appendVarint(nil, valueUint, v)
+	case bool:
+		b := uint64(0)
+		if v {
+			b = 1
+		}
+		data = appendVarint(nil, valueBool, b)
+	}
+	return appendBytes(dst, layerValues, data)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func featureIDOf(id interface{}) (uint64, bool) {
+	if id == nil {
+		return 0, false
+	}
+	value := reflect.ValueOf(id)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() >= 0 {
+			return uint64(value.Int()), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), true
+	}
+	return 0, false
+}
+
+func encodeLayer(bounds geom.Bounds, buffer int, layer Layer) ([]byte, error) {
+	extent := layer.Extent
+	if extent <= 0 {
+		extent = DefaultExtent
+	}
+	tr := transform{
+		bounds: bounds,
+		extent: float64(extent),
+		min:    float64(-buffer),
+		max:    float64(extent + buffer),
+	}
+	tb := table{keyIndex: map[string]uint64{}, valueIndex: map[interface{}]uint64{}}
+
+	buf := appendVarint(nil, layerVersion, 2)
+	buf = appendBytes(buf, layerName, []byte(layer.Name))
+	for _, t := range layer.Features {
+		var properties map[string]interface{}
+		var id interface{}
+		if f, ok := t.(geom.Feature); ok {
+			t, id = f.T, f.ID
+			if f.Properties != nil {
+				p, ok := f.Properties.(map[string]interface{})
+				if !ok {
+					return nil, UnsupportedPropertiesError{reflect.TypeOf(f.Properties)}
+				}
+				properties = p
+			}
+		}
+		if s, ok := t.(geom.SRIDGeometry); ok {
+			t = s.T
+		}
+		if t == nil {
+			continue
+		}
+
+		geometryType, commands, err := tr.encode(t)
+		if err != nil {
+			return nil, err
+		}
+		if len(commands) == 0 {
+			continue
+		}
+
+		var data []byte
+		if n, ok := featureIDOf(id); ok {
+			data = appendVarint(data, featureID, n)
+		}
+		var tags []uint32
+		for _, k := range sortedKeys(properties) {
+			if properties[k] == nil {
+				continue
+			}
+			v, err := normalize(properties[k])
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, uint32(tb.key(k)), uint32(tb.value(v)))
+		}
+		if len(tags) != 0 {
+			data = appendPacked(data, featureTags, tags)
+		}
+		data = appendVarint(data, featureType, uint64(geometryType))
+		data = appendPacked(data, featureGeometry, commands)
+		buf = appendBytes(buf, layerFeatures, data)
+	}
+
+	for _, k := range tb.keys {
+		buf = appendBytes(buf, layerKeys, []byte(k))
+	}
+	for _, v := range tb.values {
+		buf = appendValue(buf, v)
+	}
+	return appendVarint(buf, layerExtent, uint64(extent)), nil
+}
@@ -0,0 +1,201 @@
+package mvt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestCommands(t *testing.T) {
+	// The examples from the specification. The transform maps a point
+	// (x, -y) to tile coordinates (x, y).
+	tr := transform{
+		bounds: geom.Bounds{Min: geom.Point{0, -4096}, Max: geom.Point{4096, 0}},
+		extent: 4096,
+		min:    0,
+		max:    4096,
+	}
+	var testCases = []struct {
+		g            geom.T
+		geometryType int
+		commands     []uint32
+	}{
+		{geom.Point{25, -17}, typePoint, []uint32{9, 50, 34}},
+		{geom.MultiPoint{{5, -7}, {3, -2}}, typePoint, []uint32{17, 10, 14, 3, 9}},
+		{geom.LineString{{2, -2}, {2, -10}, {10, -10}}, typeLineString, []uint32{9, 4, 4, 18, 0, 16, 16, 0}},
+		{
+			geom.MultiLineString{{{2, -2}, {2, -10}, {10, -10}}, {{1, -1}, {3, -5}}},
+			typeLineString,
+			[]uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{geom.Polygon{{{3, -6}, {8, -12}, {20, -34}, {3, -6}}}, typePolygon, []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15}},
+		{
+			geom.MultiPolygon{
+				{{{0, 0}, {10, 0}, {10, -10}, {0, -10}, {0, 0}}},
+				{
+					{{11, -11}, {20, -11}, {20, -20}, {11, -20}, {11, -11}},
+					{{13, -13}, {13, -17}, {17, -17}, {17, -13}, {13, -13}},
+				},
+			},
+			typePolygon,
+			[]uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		geometryType, commands, err := tr.encode(tc.g)
+		if err != nil || geometryType != tc.geometryType || !reflect.DeepEqual(commands, tc.commands) {
+			t.Errorf("encode(%#v) == %d, %v, %v, want %d, %v, nil", tc.g, geometryType, commands, err, tc.geometryType, tc.commands)
+		}
+	}
+}
+
+func feature(g geom.T, id interface{}, properties map[string]interface{}) geom.Feature {
+	f := geom.NewFeature(g, properties)
+	f.ID = id
+	return f
+}
+
+func TestMVT(t *testing.T) {
+	tile := Tile{1, 0, 0}
+	b := tile.Bounds()
+	// merc converts from the tile coordinates of a 4096 unit tile.
+	merc := func(x, y float64) geom.Point {
+		return geom.Point{
+			b.Min[geom.X] + x/4096*(b.Max[geom.X]-b.Min[geom.X]),
+			b.Max[geom.Y] - y/4096*(b.Max[geom.Y]-b.Min[geom.Y]),
+		}
+	}
+	ring := func(ps ...float64) geom.Ring {
+		var r geom.Ring
+		for i := 0; i < len(ps); i += 2 {
+			r = append(r, merc(ps[i], ps[i+1]))
+		}
+		return r
+	}
+
+	layers := []Layer{
+		{
+			Name: "things",
+			Features: []geom.T{
+				feature(merc(100, 200), 7, map[string]interface{}{
+					"name":   "a",
+					"n":      1,
+					"f":      1.5,
+					"ok":     true,
+					"nested": map[string]interface{}{"k": "v"},
+					"skip":   nil,
+				}),
+				feature(merc(5000, 5000), 8, map[string]interface{}{"name": "outside"}),
+				feature(geom.LineString{merc(0, 100), merc(5000, 100)}, "x", map[string]interface{}{"name": "a"}),
+				geom.Polygon{
+					ring(0, 0, 0, 1000, 1000, 1000, 1000, 0, 0, 0),
+					ring(200, 200, 400, 200, 400, 400, 200, 400, 200, 200),
+				},
+				geom.MultiPolygon{{ring(4000, -100, 4300, -100, 4300, 100, 4000, 100, 4000, -100)}},
+			},
+		},
+		{
+			Name:     "small",
+			Extent:   256,
+			Features: []geom.T{geom.MultiPoint{merc(2048, 2048), merc(1024, 0)}},
+		},
+	}
+	data, err := Encode(tile, 64, layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Layer{
+		{
+			Name:   "things",
+			Extent: 4096,
+			Features: []geom.T{
+				feature(geom.Point{100, 200}, uint64(7), map[string]interface{}{
+					"name":   "a",
+					"n":      int64(1),
+					"f":      1.5,
+					"ok":     true,
+					"nested": `{"k":"v"}`,
+				}),
+				feature(geom.LineString{{0, 100}, {4160, 100}}, nil, map[string]interface{}{"name": "a"}),
+				feature(geom.Polygon{
+					{{1000, 0}, {1000, 1000}, {0, 1000}, {0, 0}, {1000, 0}},
+					{{200, 400}, {400, 400}, {400, 200}, {200, 200}, {200, 400}},
+				}, nil, map[string]interface{}{}),
+				feature(geom.Polygon{
+					{{4000, -64}, {4160, -64}, {4160, 100}, {4000, 100}, {4000, -64}},
+				}, nil, map[string]interface{}{}),
+			},
+		},
+		{
+			Name:     "small",
+			Extent:   256,
+			Features: []geom.T{feature(geom.MultiPoint{{128, 128}, {64, 0}}, nil, map[string]interface{}{})},
+		},
+	}
+	if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(Encode(...)) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	// Keys and values shared between features are stored once.
+	var keys, values int
+	fields(data, func(num int, wire int, v uint64, b []byte) error {
+		if num == tileLayers && keys == 0 {
+			fields(b, func(num int, wire int, v uint64, b []byte) error {
+				switch num {
+				case layerKeys:
+					keys++
+				case layerValues:
+					values++
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if keys != 5 || values != 5 {
+		t.Errorf("got %d keys and %d values, want 5 and 5", keys, values)
+	}
+}
+
+func TestMVTError(t *testing.T) {
+	if _, err := Encode(Tile{1, 2, 0}, 0); !reflect.DeepEqual(err, InvalidTileError{Tile{1, 2, 0}}) {
+		t.Errorf("Encode(1/2/0) == %#v", err)
+	}
+	layer := Layer{Features: []geom.T{geom.GeometryCollection{}}}
+	if _, err := Encode(Tile{}, 0, layer); !reflect.DeepEqual(err, UnsupportedGeometryError{reflect.TypeOf(geom.GeometryCollection{})}) {
+		t.Errorf("Encode(GeometryCollection) == %#v", err)
+	}
+	layer = Layer{Features: []geom.T{geom.NewFeature(geom.Point{0, 0}, []string{})}}
+	if _, err := Encode(Tile{}, 0, layer); !reflect.DeepEqual(err, UnsupportedPropertiesError{reflect.TypeOf([]string{})}) {
+		t.Errorf("Encode(properties []string) == %#v", err)
+	}
+
+	var testCases = []struct {
+		geometryType int
+		commands     []uint32
+		err          error
+	}{
+		{typePoint, []uint32{9, 50}, FormatError{"command runs past end of geometry"}},
+		{typeLineString, []uint32{18, 0, 16, 16, 0}, FormatError{"LineTo without MoveTo"}},
+		{typeLineString, []uint32{9, 4, 4}, FormatError{"invalid line"}},
+		{typePolygon, []uint32{15}, FormatError{"invalid ClosePath"}},
+		{typePolygon, []uint32{9, 4, 4, 18, 0, 16, 16, 0}, FormatError{"invalid ring"}},
+		{typePolygon, []uint32{9, 0, 0, 26, 0, 20, 20, 0, 0, 19, 15}, FormatError{"interior ring before exterior ring"}},
+		{typePoint, []uint32{3}, FormatError{"unknown command 3"}},
+		{9, []uint32{9, 50, 34}, FormatError{"unknown geometry type 9"}},
+	}
+	for _, tc := range testCases {
+		f := appendVarint(nil, featureType, uint64(tc.geometryType))
+		f = appendPacked(f, featureGeometry, tc.commands)
+		data := appendBytes(nil, tileLayers, appendBytes(nil, layerFeatures, f))
+		if _, err := Decode(data); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%d, %v) == %#v, want %#v", tc.geometryType, tc.commands, err, tc.err)
+		}
+	}
+}
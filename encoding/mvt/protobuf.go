@@ -0,0 +1,128 @@
+package mvt
+
+import (
+	"encoding/binary"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendKey(dst []byte, num, wire int) []byte {
+	return binary.AppendUvarint(dst, uint64(num)<<3|uint64(wire))
+}
+
+func appendVarint(dst []byte, num int, v uint64) []byte {
+	return binary.AppendUvarint(appendKey(dst, num, wireVarint), v)
+}
+
+func appendFixed32(dst []byte, num int, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(appendKey(dst, num, wireFixed32), v)
+}
+
+func appendFixed64(dst []byte, num int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(appendKey(dst, num, wireFixed64), v)
+}
+
+func appendBytes(dst []byte, num int, b []byte) []byte {
+	dst = binary.AppendUvarint(appendKey(dst, num, wireBytes), uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendPacked(dst []byte, num int, vs []uint32) []byte {
+	var b []byte
+	for _, v := range vs {
+		b = binary.AppendUvarint(b, uint64(v))
+	}
+	return appendBytes(dst, num, b)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+var errTruncated = FormatError{"truncated protocol buffer message"}
+
+func varint(buf []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, 0, errTruncated
+	}
+	return v, n, nil
+}
+
+// fields calls f for each field of a protocol buffer message. Scalars are
+// passed in v and length-delimited fields in b.
+func fields(buf []byte, f func(num int, wire int, v uint64, b []byte) error) error {
+	for len(buf) != 0 {
+		key, n, err := varint(buf)
+		if err != nil {
+			return err
+		}
+		buf = buf[n:]
+		num, wire := int(key>>3), int(key&7)
+		var v uint64
+		var b []byte
+		switch wire {
+		case wireVarint:
+			if v, n, err = varint(buf); err != nil {
+				return err
+			}
+		case wireFixed64:
+			if len(buf) < 8 {
+				return errTruncated
+			}
+			v, n = binary.LittleEndian.Uint64(buf), 8
+		case wireBytes:
+			var length uint64
+			if length, n, err = varint(buf); err != nil {
+				return err
+			}
+			if length > uint64(len(buf)-n) {
+				return errTruncated
+			}
+			b = buf[n : n+int(length)]
+			n += int(length)
+		case wireFixed32:
+			if len(buf) < 4 {
+				return errTruncated
+			}
+			v, n = uint64(binary.LittleEndian.Uint32(buf)), 4
+		default:
+			return FormatError{"unsupported protocol buffer wire type"}
+		}
+		buf = buf[n:]
+		if err := f(num, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendVarints appends the values of a repeated varint field, which may
+// arrive packed or one value per field.
+func appendVarints(dst []uint64, wire int, v uint64, b []byte) ([]uint64, error) {
+	switch wire {
+	case wireVarint:
+		return append(dst, v), nil
+	case wireBytes:
+		for len(b) != 0 {
+			v, n, err := varint(b)
+			if err != nil {
+				return nil, err
+			}
+			dst = append(dst, v)
+			b = b[n:]
+		}
+		return dst, nil
+	}
+	return nil, FormatError{"unexpected wire type for repeated integer"}
+}
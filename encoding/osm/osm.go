@@ -0,0 +1,282 @@
+// Package osm imports OpenStreetMap data from .osm XML and .osm.pbf files.
+//
+// Tagged nodes become Points and tagged ways become LineStrings, or
+// Polygons when the way is closed and its tags describe an area.
+// Relations of type multipolygon become MultiPolygons, with the member ways
+// joined into rings and each inner ring assigned to the outer ring that
+// contains it. Untagged nodes and ways only contribute coordinates.
+//
+// Each element becomes a geom.Feature whose properties are its tags and
+// whose ID is the element type and id, as in "way/123". Points are
+// longitude, latitude.
+package osm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+// A Filter decides from its tags whether an element becomes a feature. A
+// nil Filter accepts every tagged element. Elements rejected by the filter
+// still contribute coordinates to the ways and relations that use them.
+type Filter func(tags map[string]string) bool
+
+// FormatError reports input that is not valid OSM data.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "osm: " + e.Msg
+}
+
+// UnsupportedFeatureError reports a PBF file that requires a feature this
+// package does not implement.
+type UnsupportedFeatureError struct {
+	Feature string
+}
+
+func (e UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("osm: unsupported feature %q", e.Feature)
+}
+
+type memberType int
+
+const (
+	nodeMember memberType = iota
+	wayMember
+	relationMember
+)
+
+type node struct {
+	id    int64
+	point geom.Point
+	tags  map[string]string
+}
+
+type way struct {
+	id   int64
+	refs []int64
+	tags map[string]string
+}
+
+type member struct {
+	typ  memberType
+	ref  int64
+	role string
+}
+
+type relation struct {
+	id      int64
+	members []member
+	tags    map[string]string
+}
+
+// data holds elements in input order, as read from either format.
+type data struct {
+	nodes     []node
+	ways      []way
+	relations []relation
+}
+
+// areaKeys lists the keys that make a closed way an area, along with the
+// values of each key that describe lines instead.
+var areaKeys = map[string]map[string]bool{
+	"aeroway":       {"taxiway": true},
+	"amenity":       nil,
+	"area:highway":  nil,
+	"building":      nil,
+	"building:part": nil,
+	"craft":         nil,
+	"historic":      nil,
+	"landuse":       nil,
+	"leisure":       {"track": true},
+	"man_made":      {"cutline": true, "embankment": true, "pipeline": true},
+	"military":      nil,
+	"natural":       {"cliff": true, "coastline": true, "ridge": true, "tree_row": true},
+	"office":        nil,
+	"place":         nil,
+	"shop":          nil,
+	"tourism":       nil,
+}
+
+func isArea(tags map[string]string) bool {
+	switch tags["area"] {
+	case "yes":
+		return true
+	case "no":
+		return false
+	}
+	for key, value := range tags {
+		if lines, ok := areaKeys[key]; ok && value != "no" && !lines[value] {
+			return true
+		}
+	}
+	return false
+}
+
+func properties(tags map[string]string) map[string]interface{} {
+	p := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		p[k] = v
+	}
+	return p
+}
+
+func feature(t geom.T, kind string, id int64, tags map[string]string) geom.Feature {
+	f := geom.NewFeature(t, properties(tags))
+	f.ID = kind + "/" + strconv.FormatInt(id, 10)
+	return f
+}
+
+func (d *data) features(filter Filter) geom.FeatureCollection {
+	accept := func(tags map[string]string) bool {
+		return len(tags) != 0 && (filter == nil || filter(tags))
+	}
+
+	points := make(map[int64]geom.Point, len(d.nodes))
+	for _, n := range d.nodes {
+		points[n.id] = n.point
+	}
+	coordinates := func(refs []int64) []geom.Point {
+		ps := make([]geom.Point, 0, len(refs))
+		for _, ref := range refs {
+			if p, ok := points[ref]; ok {
+				ps = append(ps, p)
+			}
+		}
+		return ps
+	}
+
+	fc := geom.FeatureCollection{Features: []geom.T{}}
+	for _, n := range d.nodes {
+		if accept(n.tags) {
+			fc.Features = append(fc.Features, feature(n.point, "node", n.id, n.tags))
+		}
+	}
+
+	ways := make(map[int64][]int64, len(d.ways))
+	for _, w := range d.ways {
+		ways[w.id] = w.refs
+		if !accept(w.tags) {
+			continue
+		}
+		ps := coordinates(w.refs)
+		var t geom.T
+		if closedRefs(w.refs) && isArea(w.tags) {
+			if !closedRing(ps) {
+				// A vertex at either end is missing from the extract.
+				continue
+			}
+			t = geom.Polygon{geom.Ring(ps)}
+		} else if len(ps) >= 2 {
+			t = geom.LineString(ps)
+		} else {
+			continue
+		}
+		fc.Features = append(fc.Features, feature(t, "way", w.id, w.tags))
+	}
+
+	for _, r := range d.relations {
+		if r.tags["type"] != "multipolygon" || !accept(r.tags) {
+			continue
+		}
+		var outers, inners [][]int64
+		for _, m := range r.members {
+			refs, ok := ways[m.ref]
+			if m.typ != wayMember || !ok {
+				continue
+			}
+			if m.role == "inner" {
+				inners = append(inners, refs)
+			} else {
+				outers = append(outers, refs)
+			}
+		}
+		mp := assemble(rings(outers, coordinates), rings(inners, coordinates))
+		if len(mp) != 0 {
+			fc.Features = append(fc.Features, feature(mp, "relation", r.id, r.tags))
+		}
+	}
+	return fc
+}
+
+// closedRefs reports whether a way's node references form a ring.
+func closedRefs(refs []int64) bool {
+	return len(refs) >= 4 && refs[0] == refs[len(refs)-1]
+}
+
+func closedRing(ps []geom.Point) bool {
+	if len(ps) < 4 {
+		return false
+	}
+	first, last := ps[0], ps[len(ps)-1]
+	return first[geom.X] == last[geom.X] && first[geom.Y] == last[geom.Y]
+}
+
+// rings joins ways end to end into closed rings. Ways that cannot be
+// closed, usually because the extract cut the relation off, are dropped.
+func rings(ways [][]int64, coordinates func([]int64) []geom.Point) []geom.Ring {
+	remaining := make([][]int64, len(ways))
+	copy(remaining, ways)
+	rs := []geom.Ring{}
+	for len(remaining) != 0 {
+		current := append([]int64(nil), remaining[0]...)
+		remaining = remaining[1:]
+		for len(current) != 0 && !closedRefs(current) {
+			end := current[len(current)-1]
+			found := false
+			for i, w := range remaining {
+				if len(w) == 0 {
+					continue
+				}
+				switch end {
+				case w[0]:
+					current = append(current, w[1:]...)
+				case w[len(w)-1]:
+					for j := len(w) - 2; j >= 0; j-- {
+						current = append(current, w[j])
+					}
+				default:
+					continue
+				}
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+			if !found {
+				current = nil
+			}
+		}
+		if ps := coordinates(current); closedRing(ps) {
+			rs = append(rs, geom.Ring(ps))
+		}
+	}
+	return rs
+}
+
+// assemble gives each inner ring to the smallest outer ring containing it.
+// Inner rings outside every outer ring are dropped.
+func assemble(outers, inners []geom.Ring) geom.MultiPolygon {
+	mp := make(geom.MultiPolygon, len(outers))
+	for i, outer := range outers {
+		mp[i] = geom.Polygon{outer}
+	}
+	for _, inner := range inners {
+		best, bestArea := -1, math.Inf(1)
+		for i, outer := range outers {
+			area := math.Abs(planar.SignedArea(outer))
+			if area < bestArea && planar.RingContains(outer, inner[0]) {
+				best, bestArea = i, area
+			}
+		}
+		if best >= 0 {
+			mp[best] = append(mp[best], inner)
+		}
+	}
+	return mp
+}
@@ -0,0 +1,222 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+const testXML = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6" generator="test">
+ <bounds minlat="0" minlon="0" maxlat="51.25" maxlon="10"/>
+ <node id="1" lat="0" lon="0"/>
+ <node id="2" lat="0" lon="10"/>
+ <node id="3" lat="10" lon="10"/>
+ <node id="4" lat="10" lon="0"/>
+ <node id="5" lat="2" lon="2"/>
+ <node id="6" lat="2" lon="4"/>
+ <node id="7" lat="4" lon="4"/>
+ <node id="8" lat="4" lon="2"/>
+ <node id="9" lat="51.25" lon="1.5">
+  <tag k="amenity" v="cafe"/>
+  <tag k="name" v="Foo"/>
+ </node>
+ <node id="20" lat="1" lon="1" visible="false"><tag k="amenity" v="bench"/></node>
+ <way id="10"><nd ref="1"/><nd ref="2"/><nd ref="3"/></way>
+ <way id="11"><nd ref="1"/><nd ref="4"/><nd ref="3"/></way>
+ <way id="12"><nd ref="5"/><nd ref="6"/><nd ref="7"/><nd ref="8"/><nd ref="5"/></way>
+ <way id="13"><nd ref="1"/><nd ref="2"/><tag k="highway" v="residential"/></way>
+ <way id="14"><nd ref="5"/><nd ref="6"/><nd ref="7"/><nd ref="8"/><nd ref="5"/><tag k="building" v="yes"/></way>
+ <way id="15"><nd ref="5"/><nd ref="6"/><nd ref="7"/><nd ref="8"/><nd ref="5"/><tag k="highway" v="footway"/></way>
+ <way id="16"><nd ref="1"/><nd ref="999"/><tag k="highway" v="service"/></way>
+ <relation id="100">
+  <member type="way" ref="10" role="outer"/>
+  <member type="way" ref="11" role="outer"/>
+  <member type="way" ref="12" role="inner"/>
+  <member type="node" ref="9" role=""/>
+  <tag k="type" v="multipolygon"/>
+  <tag k="landuse" v="forest"/>
+ </relation>
+ <relation id="101">
+  <member type="way" ref="10" role="outer"/>
+  <tag k="type" v="multipolygon"/>
+  <tag k="landuse" v="grass"/>
+ </relation>
+</osm>`
+
+func testFeature(t geom.T, id string, props map[string]interface{}) geom.Feature {
+	f := geom.NewFeature(t, props)
+	f.ID = id
+	return f
+}
+
+var (
+	outer = geom.Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	inner = geom.Ring{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}
+	cafe  = testFeature(geom.Point{1.5, 51.25}, "node/9", map[string]interface{}{"amenity": "cafe", "name": "Foo"})
+	road  = testFeature(geom.LineString{{0, 0}, {10, 0}}, "way/13", map[string]interface{}{"highway": "residential"})
+	house = testFeature(geom.Polygon{inner}, "way/14", map[string]interface{}{"building": "yes"})
+	path  = testFeature(geom.LineString(inner), "way/15", map[string]interface{}{"highway": "footway"})
+	wood  = testFeature(geom.MultiPolygon{{outer, inner}}, "relation/100", map[string]interface{}{"type": "multipolygon", "landuse": "forest"})
+)
+
+func TestXML(t *testing.T) {
+	want := geom.FeatureCollection{Features: []geom.T{cafe, road, house, path, wood}}
+	if got, err := DecodeXML([]byte(testXML), nil); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeXML(testXML, nil) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	highways := func(tags map[string]string) bool {
+		_, ok := tags["highway"]
+		return ok
+	}
+	want = geom.FeatureCollection{Features: []geom.T{road, path}}
+	if got, err := DecodeXML([]byte(testXML), highways); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeXML(testXML, highways) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	if _, err := DecodeXML([]byte(`<gpx/>`), nil); !reflect.DeepEqual(err, FormatError{"root element is gpx, not osm"}) {
+		t.Errorf("DecodeXML(<gpx/>) == %#v", err)
+	}
+}
+
+func pbVarint(num int, v uint64) []byte {
+	b := binary.AppendUvarint(nil, uint64(num)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func pbBytes(num int, data ...[]byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(num)<<3|wireBytes)
+	joined := bytes.Join(data, nil)
+	b = binary.AppendUvarint(b, uint64(len(joined)))
+	return append(b, joined...)
+}
+
+func pbPacked(num int, vs ...uint64) []byte {
+	var b []byte
+	for _, v := range vs {
+		b = binary.AppendUvarint(b, v)
+	}
+	return pbBytes(num, b)
+}
+
+func zz(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// zzDeltas encodes values as zigzag deltas, as in packed sint64 fields.
+func zzDeltas(vs ...int64) []uint64 {
+	out := make([]uint64, len(vs))
+	var last int64
+	for i, v := range vs {
+		out[i] = zz(v - last)
+		last = v
+	}
+	return out
+}
+
+func pbBlob(blobType string, raw []byte, compress bool) []byte {
+	var blob []byte
+	if compress {
+		var z bytes.Buffer
+		w := zlib.NewWriter(&z)
+		w.Write(raw)
+		w.Close()
+		blob = append(pbVarint(2, uint64(len(raw))), pbBytes(3, z.Bytes())...)
+	} else {
+		blob = pbBytes(1, raw)
+	}
+	header := append(pbBytes(1, []byte(blobType)), pbVarint(3, uint64(len(blob)))...)
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(header)))
+	return append(append(out, header...), blob...)
+}
+
+func testPBF(features ...string) []byte {
+	var hb []byte
+	for _, f := range features {
+		hb = append(hb, pbBytes(4, []byte(f))...)
+	}
+
+	strings := []string{"", "amenity", "cafe", "name", "Foo", "highway", "residential",
+		"building", "yes", "footway", "service", "type", "multipolygon", "landuse", "forest",
+		"outer", "inner", "grass"}
+	var st []byte
+	for _, s := range strings {
+		st = append(st, pbBytes(1, []byte(s))...)
+	}
+
+	// Coordinates are in units of granularity, 1000 nanodegrees here,
+	// relative to an offset of 1 degree on each axis.
+	c := func(deg float64) int64 { return int64(deg*1e6) - 1e6 }
+	dense := pbBytes(2, pbBytes(2,
+		pbPacked(1, zzDeltas(1, 2, 3, 4, 5, 6, 7, 8, 9)...),
+		pbPacked(8, zzDeltas(c(0), c(0), c(10), c(10), c(2), c(2), c(4), c(4), c(51.25))...),
+		pbPacked(9, zzDeltas(c(0), c(10), c(10), c(0), c(2), c(4), c(4), c(2), c(1.5))...),
+		pbPacked(10, 0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4, 0),
+	))
+	way := func(id uint64, keys, vals []uint64, refs ...int64) []byte {
+		return pbBytes(3, pbVarint(1, id), pbPacked(2, keys...), pbPacked(3, vals...), pbPacked(8, zzDeltas(refs...)...))
+	}
+	ways := pbBytes(2,
+		way(10, nil, nil, 1, 2, 3),
+		way(11, nil, nil, 1, 4, 3),
+		way(12, nil, nil, 5, 6, 7, 8, 5),
+		way(13, []uint64{5}, []uint64{6}, 1, 2),
+		way(14, []uint64{7}, []uint64{8}, 5, 6, 7, 8, 5),
+		way(15, []uint64{5}, []uint64{9}, 5, 6, 7, 8, 5),
+		way(16, []uint64{5}, []uint64{10}, 1, 999),
+	)
+	// Member types are sent unpacked to check both encodings are read.
+	relations := pbBytes(2,
+		pbBytes(4, pbVarint(1, 100), pbPacked(2, 11, 13), pbPacked(3, 12, 14),
+			pbPacked(8, 15, 15, 16, 0), pbPacked(9, zzDeltas(10, 11, 12, 9)...),
+			pbVarint(10, 1), pbVarint(10, 1), pbVarint(10, 1), pbVarint(10, 0)),
+		pbBytes(4, pbVarint(1, 101), pbPacked(2, 11, 13), pbPacked(3, 12, 17),
+			pbPacked(8, 15), pbPacked(9, zzDeltas(10)...), pbPacked(10, 1)),
+	)
+	block := bytes.Join([][]byte{
+		pbBytes(1, st), dense, ways, relations,
+		pbVarint(17, 1000), pbVarint(19, 1e9), pbVarint(20, 1e9),
+	}, nil)
+
+	return bytes.Join([][]byte{
+		pbBlob("OSMHeader", hb, false),
+		pbBlob("OSMIndex", []byte("ignored"), false),
+		pbBlob("OSMData", block, true),
+	}, nil)
+}
+
+func TestPBF(t *testing.T) {
+	want := geom.FeatureCollection{Features: []geom.T{cafe, road, house, path, wood}}
+	data := testPBF("OsmSchema-V0.6", "DenseNodes")
+	if got, err := DecodePBF(data, nil); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodePBF(testPBF, nil) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	cafes := func(tags map[string]string) bool { return tags["amenity"] == "cafe" }
+	want = geom.FeatureCollection{Features: []geom.T{cafe}}
+	if got, err := DecodePBF(data, cafes); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodePBF(testPBF, cafes) == %#v, %v, want %#v, nil", got, err, want)
+	}
+
+	if _, err := DecodePBF(testPBF("OsmSchema-V0.6", "HistoricalInformation"), nil); !reflect.DeepEqual(err, UnsupportedFeatureError{"HistoricalInformation"}) {
+		t.Errorf("DecodePBF with HistoricalInformation == %#v", err)
+	}
+	for _, n := range []int{1, 4, 10, len(data) - 1} {
+		if _, err := DecodePBF(data[:n], nil); err != io.ErrUnexpectedEOF {
+			t.Errorf("DecodePBF(data[:%d]) == %#v, want io.ErrUnexpectedEOF", n, err)
+		}
+	}
+
+	lzma := pbBytes(4, []byte{})
+	header := append(pbBytes(1, []byte("OSMData")), pbVarint(3, uint64(len(lzma)))...)
+	blob := append(binary.BigEndian.AppendUint32(nil, uint32(len(header))), append(header, lzma...)...)
+	if _, err := DecodePBF(blob, nil); !reflect.DeepEqual(err, UnsupportedFeatureError{"lzma compression"}) {
+		t.Errorf("DecodePBF(lzma) == %#v", err)
+	}
+}
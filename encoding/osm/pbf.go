@@ -0,0 +1,399 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/foobaz/geom"
+)
+
+// Size limits from the PBF specification.
+const (
+	maxBlobHeaderSize = 64 * 1024
+	maxBlobSize       = 32 * 1024 * 1024
+)
+
+// supportedFeatures lists the required features of an OSMHeader block that
+// this package can read.
+var supportedFeatures = map[string]bool{
+	"OsmSchema-V0.6": true,
+	"DenseNodes":     true,
+}
+
+// ReadPBF reads an .osm.pbf file. Blobs may be stored raw or compressed
+// with zlib.
+func ReadPBF(r io.Reader, filter Filter) (geom.FeatureCollection, error) {
+	var osm data
+	for {
+		blobType, blob, err := readBlob(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		switch blobType {
+		case "OSMHeader":
+			err = readHeaderBlock(blob)
+		case "OSMData":
+			err = osm.readPrimitiveBlock(blob)
+		}
+		// Blobs of other types are skipped, as the specification requires.
+		if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+	}
+	return osm.features(filter), nil
+}
+
+func DecodePBF(buf []byte, filter Filter) (geom.FeatureCollection, error) {
+	return ReadPBF(bytes.NewReader(buf), filter)
+}
+
+// readBlob reads one BlobHeader and its Blob, returning the blob type and
+// the uncompressed contents. It returns io.EOF only at a clean end of file.
+func readBlob(r io.Reader) (string, []byte, error) {
+	var headerSize uint32
+	if err := binary.Read(r, binary.BigEndian, &headerSize); err != nil {
+		return "", nil, err
+	}
+	if headerSize > maxBlobHeaderSize {
+		return "", nil, FormatError{fmt.Sprintf("blob header of %d bytes exceeds limit", headerSize)}
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", nil, noEOF(err)
+	}
+
+	var blobType string
+	var dataSize uint64
+	err := fields(header, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			blobType = string(b)
+		case 3:
+			dataSize = v
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if dataSize > maxBlobSize {
+		return "", nil, FormatError{fmt.Sprintf("blob of %d bytes exceeds limit", dataSize)}
+	}
+	blob := make([]byte, dataSize)
+	if _, err := io.ReadFull(r, blob); err != nil {
+		return "", nil, noEOF(err)
+	}
+
+	var raw, compressed []byte
+	var rawSize uint64
+	var compression string
+	err = fields(blob, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			raw = b
+		case 2:
+			rawSize = v
+		case 3:
+			compressed = b
+		case 4:
+			compression = "lzma"
+		case 5:
+			compression = "bzip2"
+		case 6:
+			compression = "lz4"
+		case 7:
+			compression = "zstd"
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return "", nil, err
+	case raw != nil:
+		return blobType, raw, nil
+	case compressed != nil:
+		if rawSize > maxBlobSize {
+			return "", nil, FormatError{fmt.Sprintf("blob of %d bytes exceeds limit", rawSize)}
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return "", nil, err
+		}
+		defer zr.Close()
+		buf, err := io.ReadAll(io.LimitReader(zr, int64(rawSize)+1))
+		if err != nil {
+			return "", nil, noEOF(err)
+		}
+		if uint64(len(buf)) != rawSize {
+			return "", nil, FormatError{"blob size does not match raw_size"}
+		}
+		return blobType, buf, nil
+	case compression != "":
+		return "", nil, UnsupportedFeatureError{compression + " compression"}
+	}
+	return blobType, nil, nil
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func readHeaderBlock(buf []byte) error {
+	return fields(buf, func(num int, wire int, v uint64, b []byte) error {
+		if num == 4 && !supportedFeatures[string(b)] {
+			return UnsupportedFeatureError{string(b)}
+		}
+		return nil
+	})
+}
+
+// block holds the state shared by the groups of a PrimitiveBlock.
+type block struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (bl *block) point(lat, lon int64) geom.Point {
+	return geom.Point{
+		float64(bl.lonOffset+bl.granularity*lon) / 1e9,
+		float64(bl.latOffset+bl.granularity*lat) / 1e9,
+	}
+}
+
+func (bl *block) string(i uint64) (string, error) {
+	if i >= uint64(len(bl.strings)) {
+		return "", FormatError{fmt.Sprintf("string index %d out of range", i)}
+	}
+	return bl.strings[i], nil
+}
+
+func (bl *block) tags(keys, vals []uint64) (map[string]string, error) {
+	if len(keys) != len(vals) {
+		return nil, FormatError{"tag keys and values differ in length"}
+	}
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		k, err := bl.string(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := bl.string(vals[i])
+		if err != nil {
+			return nil, err
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (d *data) readPrimitiveBlock(buf []byte) error {
+	bl := block{granularity: 100}
+	var groups [][]byte
+	err := fields(buf, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			return fields(b, func(num int, wire int, v uint64, b []byte) error {
+				if num == 1 {
+					bl.strings = append(bl.strings, string(b))
+				}
+				return nil
+			})
+		case 2:
+			groups = append(groups, b)
+		case 17:
+			bl.granularity = int64(v)
+		case 19:
+			bl.latOffset = int64(v)
+		case 20:
+			bl.lonOffset = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		err := fields(group, func(num int, wire int, v uint64, b []byte) error {
+			switch num {
+			case 1:
+				return d.readNode(&bl, b)
+			case 2:
+				return d.readDenseNodes(&bl, b)
+			case 3:
+				return d.readWay(&bl, b)
+			case 4:
+				return d.readRelation(&bl, b)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *data) readNode(bl *block, buf []byte) error {
+	var id, lat, lon int64
+	var keys, vals []uint64
+	err := fields(buf, func(num int, wire int, v uint64, b []byte) (err error) {
+		switch num {
+		case 1:
+			id = zigzag(v)
+		case 2:
+			keys, err = appendVarints(keys, wire, v, b)
+		case 3:
+			vals, err = appendVarints(vals, wire, v, b)
+		case 8:
+			lat = zigzag(v)
+		case 9:
+			lon = zigzag(v)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := bl.tags(keys, vals)
+	if err != nil {
+		return err
+	}
+	d.nodes = append(d.nodes, node{id, bl.point(lat, lon), tags})
+	return nil
+}
+
+func (d *data) readDenseNodes(bl *block, buf []byte) error {
+	var ids, lats, lons, keysVals []uint64
+	err := fields(buf, func(num int, wire int, v uint64, b []byte) (err error) {
+		switch num {
+		case 1:
+			ids, err = appendVarints(ids, wire, v, b)
+		case 8:
+			lats, err = appendVarints(lats, wire, v, b)
+		case 9:
+			lons, err = appendVarints(lons, wire, v, b)
+		case 10:
+			keysVals, err = appendVarints(keysVals, wire, v, b)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return FormatError{"dense node ids and coordinates differ in length"}
+	}
+
+	idDeltas, latDeltas, lonDeltas := deltas(ids), deltas(lats), deltas(lons)
+	for i := range ids {
+		tags := map[string]string{}
+		// keys_vals holds each node's key and value indexes, ending with
+		// a zero. It is empty when no node in the block has tags.
+		for len(keysVals) != 0 {
+			if keysVals[0] == 0 {
+				keysVals = keysVals[1:]
+				break
+			}
+			if len(keysVals) < 2 {
+				return FormatError{"dense node tag has no value"}
+			}
+			k, err := bl.string(keysVals[0])
+			if err != nil {
+				return err
+			}
+			v, err := bl.string(keysVals[1])
+			if err != nil {
+				return err
+			}
+			tags[k] = v
+			keysVals = keysVals[2:]
+		}
+		d.nodes = append(d.nodes, node{idDeltas[i], bl.point(latDeltas[i], lonDeltas[i]), tags})
+	}
+	return nil
+}
+
+func (d *data) readWay(bl *block, buf []byte) error {
+	var id int64
+	var keys, vals, refs []uint64
+	err := fields(buf, func(num int, wire int, v uint64, b []byte) (err error) {
+		switch num {
+		case 1:
+			id = int64(v)
+		case 2:
+			keys, err = appendVarints(keys, wire, v, b)
+		case 3:
+			vals, err = appendVarints(vals, wire, v, b)
+		case 8:
+			refs, err = appendVarints(refs, wire, v, b)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := bl.tags(keys, vals)
+	if err != nil {
+		return err
+	}
+	d.ways = append(d.ways, way{id, deltas(refs), tags})
+	return nil
+}
+
+func (d *data) readRelation(bl *block, buf []byte) error {
+	var id int64
+	var keys, vals, roles, memids, types []uint64
+	err := fields(buf, func(num int, wire int, v uint64, b []byte) (err error) {
+		switch num {
+		case 1:
+			id = int64(v)
+		case 2:
+			keys, err = appendVarints(keys, wire, v, b)
+		case 3:
+			vals, err = appendVarints(vals, wire, v, b)
+		case 8:
+			roles, err = appendVarints(roles, wire, v, b)
+		case 9:
+			memids, err = appendVarints(memids, wire, v, b)
+		case 10:
+			types, err = appendVarints(types, wire, v, b)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := bl.tags(keys, vals)
+	if err != nil {
+		return err
+	}
+	if len(memids) != len(roles) || len(types) != len(roles) {
+		return FormatError{"relation member fields differ in length"}
+	}
+
+	refs := deltas(memids)
+	members := make([]member, len(refs))
+	for i, ref := range refs {
+		role, err := bl.string(roles[i])
+		if err != nil {
+			return err
+		}
+		if types[i] > uint64(relationMember) {
+			return FormatError{fmt.Sprintf("unknown member type %d", types[i])}
+		}
+		members[i] = member{memberType(types[i]), ref, role}
+	}
+	d.relations = append(d.relations, relation{id, members, tags})
+	return nil
+}
@@ -0,0 +1,108 @@
+package osm
+
+import (
+	"encoding/binary"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = FormatError{"truncated protocol buffer message"}
+
+func varint(buf []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, 0, errTruncated
+	}
+	return v, n, nil
+}
+
+// fields calls f for each field of a protocol buffer message. Scalars are
+// passed in v and length-delimited fields in b. Groups are not supported,
+// as no OSM message uses them.
+func fields(buf []byte, f func(num int, wire int, v uint64, b []byte) error) error {
+	for len(buf) != 0 {
+		key, n, err := varint(buf)
+		if err != nil {
+			return err
+		}
+		buf = buf[n:]
+		num, wire := int(key>>3), int(key&7)
+		var v uint64
+		var b []byte
+		switch wire {
+		case wireVarint:
+			if v, n, err = varint(buf); err != nil {
+				return err
+			}
+		case wireFixed64:
+			if len(buf) < 8 {
+				return errTruncated
+			}
+			v, n = binary.LittleEndian.Uint64(buf), 8
+		case wireBytes:
+			var length uint64
+			if length, n, err = varint(buf); err != nil {
+				return err
+			}
+			if length > uint64(len(buf)-n) {
+				return errTruncated
+			}
+			b = buf[n : n+int(length)]
+			n += int(length)
+		case wireFixed32:
+			if len(buf) < 4 {
+				return errTruncated
+			}
+			v, n = uint64(binary.LittleEndian.Uint32(buf)), 4
+		default:
+			return FormatError{"unsupported protocol buffer wire type"}
+		}
+		buf = buf[n:]
+		if err := f(num, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendVarints appends the values of a repeated varint field, which may
+// arrive packed or one value per field.
+func appendVarints(dst []uint64, wire int, v uint64, b []byte) ([]uint64, error) {
+	switch wire {
+	case wireVarint:
+		return append(dst, v), nil
+	case wireBytes:
+		for len(b) != 0 {
+			v, n, err := varint(b)
+			if err != nil {
+				return nil, err
+			}
+			dst = append(dst, v)
+			b = b[n:]
+		}
+		return dst, nil
+	}
+	return nil, FormatError{"unexpected wire type for repeated integer"}
+}
+
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// deltas decodes a packed sint64 field whose values are each stored as the
+// difference from the previous one.
+func deltas(vs []uint64) []int64 {
+	out := make([]int64, len(vs))
+	var last int64
+	for i, v := range vs {
+		last += zigzag(v)
+		out[i] = last
+	}
+	return out
+}
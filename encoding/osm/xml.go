@@ -0,0 +1,141 @@
+package osm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+
+	"github.com/foobaz/geom"
+)
+
+type xmlTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type xmlNode struct {
+	ID      int64    `xml:"id,attr"`
+	Lat     float64  `xml:"lat,attr"`
+	Lon     float64  `xml:"lon,attr"`
+	Visible string   `xml:"visible,attr"`
+	Tags    []xmlTag `xml:"tag"`
+}
+
+type xmlNodeRef struct {
+	Ref int64 `xml:"ref,attr"`
+}
+
+type xmlWay struct {
+	ID      int64        `xml:"id,attr"`
+	Visible string       `xml:"visible,attr"`
+	Refs    []xmlNodeRef `xml:"nd"`
+	Tags    []xmlTag     `xml:"tag"`
+}
+
+type xmlMember struct {
+	Type string `xml:"type,attr"`
+	Ref  int64  `xml:"ref,attr"`
+	Role string `xml:"role,attr"`
+}
+
+type xmlRelation struct {
+	ID      int64       `xml:"id,attr"`
+	Visible string      `xml:"visible,attr"`
+	Members []xmlMember `xml:"member"`
+	Tags    []xmlTag    `xml:"tag"`
+}
+
+func xmlTags(tags []xmlTag) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		m[t.Key] = t.Value
+	}
+	return m
+}
+
+// ReadXML reads an .osm XML file. Elements marked visible="false", as left
+// behind by editors, are ignored.
+func ReadXML(r io.Reader, filter Filter) (geom.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	var osm data
+	sawRoot := false
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !sawRoot {
+			if start.Name.Local != "osm" {
+				return geom.FeatureCollection{}, FormatError{"root element is " + start.Name.Local + ", not osm"}
+			}
+			sawRoot = true
+			continue
+		}
+
+		switch start.Name.Local {
+		case "node":
+			var n xmlNode
+			if err := d.DecodeElement(&n, &start); err != nil {
+				return geom.FeatureCollection{}, err
+			}
+			if n.Visible != "false" {
+				osm.nodes = append(osm.nodes, node{n.ID, geom.Point{n.Lon, n.Lat}, xmlTags(n.Tags)})
+			}
+		case "way":
+			var w xmlWay
+			if err := d.DecodeElement(&w, &start); err != nil {
+				return geom.FeatureCollection{}, err
+			}
+			if w.Visible == "false" {
+				continue
+			}
+			refs := make([]int64, len(w.Refs))
+			for i, nd := range w.Refs {
+				refs[i] = nd.Ref
+			}
+			osm.ways = append(osm.ways, way{w.ID, refs, xmlTags(w.Tags)})
+		case "relation":
+			var rel xmlRelation
+			if err := d.DecodeElement(&rel, &start); err != nil {
+				return geom.FeatureCollection{}, err
+			}
+			if rel.Visible == "false" {
+				continue
+			}
+			members := make([]member, 0, len(rel.Members))
+			for _, m := range rel.Members {
+				var typ memberType
+				switch m.Type {
+				case "node":
+					typ = nodeMember
+				case "way":
+					typ = wayMember
+				case "relation":
+					typ = relationMember
+				default:
+					return geom.FeatureCollection{}, FormatError{"unknown member type " + m.Type}
+				}
+				members = append(members, member{typ, m.Ref, m.Role})
+			}
+			osm.relations = append(osm.relations, relation{rel.ID, members, xmlTags(rel.Tags)})
+		default:
+			if err := d.Skip(); err != nil {
+				return geom.FeatureCollection{}, err
+			}
+		}
+	}
+	if !sawRoot {
+		return geom.FeatureCollection{}, FormatError{"missing osm element"}
+	}
+	return osm.features(filter), nil
+}
+
+func DecodeXML(buf []byte, filter Filter) (geom.FeatureCollection, error) {
+	return ReadXML(bytes.NewReader(buf), filter)
+}
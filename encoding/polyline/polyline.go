@@ -0,0 +1,182 @@
+// Package polyline implements the Encoded Polyline Algorithm Format used by
+// Google Maps and many routing services.
+package polyline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/foobaz/geom"
+)
+
+// Options configures encoding and decoding. The zero value matches Google's
+// format: five decimal digits, with latitude before longitude.
+type Options struct {
+	// Precision is the number of decimal digits kept, usually 5 or 6. Zero
+	// means 5 unless ExplicitPrecision is set.
+	Precision int
+	// ExplicitPrecision means Precision is used as given, so that a
+	// precision of zero can be selected.
+	ExplicitPrecision bool
+	// LonLat means the encoded pairs have longitude first, in the same
+	// order as geom.Point's X and Y. Otherwise the pairs have latitude
+	// first and are swapped.
+	LonLat bool
+}
+
+type InvalidPrecisionError struct {
+	Precision int
+}
+
+func (e InvalidPrecisionError) Error() string {
+	return fmt.Sprintf("polyline: invalid precision %d", e.Precision)
+}
+
+type InsufficientElementsError struct {
+	ElementCount int
+}
+
+func (e InsufficientElementsError) Error() string {
+	return fmt.Sprintf("polyline: need at least two elements in point, got %d", e.ElementCount)
+}
+
+// SyntaxError reports malformed input. Offset is the byte offset in the
+// encoded string where the problem was found.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("polyline: syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+func (o Options) scale() (float64, error) {
+	precision := o.Precision
+	if precision == 0 && !o.ExplicitPrecision {
+		precision = 5
+	}
+	if precision < 0 || precision > 10 {
+		return 0, InvalidPrecisionError{o.Precision}
+	}
+	return math.Pow10(precision), nil
+}
+
+func appendValue(dst []byte, v int64) []byte {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		dst = append(dst, byte(0x20|u&0x1f)+63)
+		u >>= 5
+	}
+	return append(dst, byte(u)+63)
+}
+
+func appendLineString(dst []byte, lineString geom.LineString, o Options) ([]byte, error) {
+	scale, err := o.scale()
+	if err != nil {
+		return nil, err
+	}
+
+	var prev [2]int64
+	for _, point := range lineString {
+		if len(point) < 2 {
+			return nil, InsufficientElementsError{len(point)}
+		}
+
+		pair := [2]float64{point[geom.Y], point[geom.X]}
+		if o.LonLat {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		for i, c := range pair {
+			v := int64(math.Round(c * scale))
+			dst = appendValue(dst, v-prev[i])
+			prev[i] = v
+		}
+	}
+	return dst, nil
+}
+
+func EncodeLineString(lineString geom.LineString, o Options) (string, error) {
+	dst, err := appendLineString(nil, lineString, o)
+	if err != nil {
+		return "", err
+	}
+	return string(dst), nil
+}
+
+// EncodeMultiLineString encodes each LineString as a separate polyline.
+func EncodeMultiLineString(multiLineString geom.MultiLineString, o Options) ([]string, error) {
+	encoded := make([]string, len(multiLineString))
+	for i, lineString := range multiLineString {
+		var err error
+		if encoded[i], err = EncodeLineString(lineString, o); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+func DecodeLineString(s string, o Options) (geom.LineString, error) {
+	scale, err := o.scale()
+	if err != nil {
+		return nil, err
+	}
+
+	lineString := geom.LineString{}
+	var prev [2]int64
+	var pair [2]float64
+	i := 0
+	for i < len(s) {
+		for axis := range pair {
+			if i == len(s) {
+				return nil, SyntaxError{i, "odd number of values"}
+			}
+
+			var u uint64
+			for shift := uint(0); ; shift += 5 {
+				if i == len(s) {
+					return nil, SyntaxError{i, "truncated value"}
+				}
+				c := s[i]
+				if c < 63 || c > 126 || shift > 60 {
+					return nil, SyntaxError{i, fmt.Sprintf("invalid character %q", c)}
+				}
+				i++
+
+				b := uint64(c - 63)
+				u |= (b & 0x1f) << shift
+				if b < 0x20 {
+					break
+				}
+			}
+
+			v := int64(u >> 1)
+			if u&1 != 0 {
+				v = ^v
+			}
+			prev[axis] += v
+			pair[axis] = float64(prev[axis]) / scale
+		}
+
+		if o.LonLat {
+			lineString = append(lineString, geom.Point{pair[0], pair[1]})
+		} else {
+			lineString = append(lineString, geom.Point{pair[1], pair[0]})
+		}
+	}
+	return lineString, nil
+}
+
+func DecodeMultiLineString(encoded []string, o Options) (geom.MultiLineString, error) {
+	multiLineString := make(geom.MultiLineString, len(encoded))
+	for i, s := range encoded {
+		var err error
+		if multiLineString[i], err = DecodeLineString(s, o); err != nil {
+			return nil, err
+		}
+	}
+	return multiLineString, nil
+}
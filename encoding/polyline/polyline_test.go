@@ -0,0 +1,86 @@
+package polyline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestPolyline(t *testing.T) {
+	var testCases = []struct {
+		g        geom.LineString
+		options  Options
+		polyline string
+	}{
+		{
+			geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+			Options{},
+			"_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		{
+			geom.LineString{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}},
+			Options{LonLat: true},
+			"_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		{
+			geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+			Options{Precision: 6},
+			"_izlhA~rlgdF_{geC~ywl@_kwzCn`{nI",
+		},
+		{
+			geom.LineString{{-120, 38}, {-121, 41}},
+			Options{ExplicitPrecision: true},
+			"kAnFE@",
+		},
+		{
+			geom.LineString{},
+			Options{},
+			"",
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := EncodeLineString(tc.g, tc.options); err != nil || got != tc.polyline {
+			t.Errorf("EncodeLineString(%#v, %#v) == %#v, %v, want %#v, nil", tc.g, tc.options, got, err, tc.polyline)
+		}
+		if got, err := DecodeLineString(tc.polyline, tc.options); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("DecodeLineString(%#v, %#v) == %#v, %v, want %#v, nil", tc.polyline, tc.options, got, err, tc.g)
+		}
+	}
+}
+
+func TestPolylineMultiLineString(t *testing.T) {
+	g := geom.MultiLineString{{{1, 2, 3}, {3, 4, 5}}, {{-0.5, 0.25}}}
+	want := geom.MultiLineString{{{1, 2}, {3, 4}}, {{-0.5, 0.25}}}
+	encoded, err := EncodeMultiLineString(g, Options{})
+	if err != nil || len(encoded) != 2 {
+		t.Fatalf("EncodeMultiLineString(%#v) == %#v, %v", g, encoded, err)
+	}
+	if got, err := DecodeMultiLineString(encoded, Options{}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeMultiLineString(%#v) == %#v, %v, want %#v, nil", encoded, got, err, want)
+	}
+}
+
+func TestPolylineError(t *testing.T) {
+	if _, err := EncodeLineString(geom.LineString{{1}}, Options{}); !reflect.DeepEqual(err, InsufficientElementsError{1}) {
+		t.Errorf("EncodeLineString error == %#v, want InsufficientElementsError{1}", err)
+	}
+	if _, err := EncodeLineString(geom.LineString{{1, 2}}, Options{Precision: -1}); !reflect.DeepEqual(err, InvalidPrecisionError{-1}) {
+		t.Errorf("EncodeLineString error == %#v, want InvalidPrecisionError{-1}", err)
+	}
+
+	var testCases = []struct {
+		polyline string
+		err      error
+	}{
+		{"_p~iF", SyntaxError{5, "odd number of values"}},
+		{"_p~iF~ps|", SyntaxError{9, "truncated value"}},
+		{"_p~iF ps|U", SyntaxError{5, `invalid character ' '`}},
+		{"_p~iF\x7fps|U", SyntaxError{5, `invalid character '\x7f'`}},
+	}
+	for _, tc := range testCases {
+		if _, err := DecodeLineString(tc.polyline, Options{}); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("DecodeLineString(%#v) == _, %#v, want %#v", tc.polyline, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package shapefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/foobaz/geom"
+)
+
+const (
+	dbfVersion          = 0x03
+	dbfHeaderTerminator = 0x0d
+	dbfEOF              = 0x1a
+	dbfDeleted          = '*'
+	dbfMaxFieldLength   = 254
+	dbfMaxNumberLength  = 20
+	dbfMaxNameLength    = 10
+	dbfDateLayout       = "20060102"
+)
+
+// field describes a column of a dBase table. When writing, property is the
+// name of the property it holds, which may be longer than name.
+type field struct {
+	name     string
+	property string
+	kind     byte
+	length   int
+	decimals int
+}
+
+// readDBF reads the records of a dBase table. Character fields decode to
+// strings, numeric fields to float64, logical fields to bool and date fields
+// to time.Time. Blank values decode to nil, and deleted records to a nil
+// map.
+func readDBF(r io.Reader) ([]map[string]interface{}, error) {
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	recordCount := int(binary.LittleEndian.Uint32(header[4:]))
+	headerLen := int(binary.LittleEndian.Uint16(header[8:]))
+	recordLen := int(binary.LittleEndian.Uint16(header[10:]))
+	if headerLen < 33 {
+		return nil, FormatError{"invalid .dbf header length"}
+	}
+
+	descriptors := make([]byte, headerLen-32)
+	if _, err := io.ReadFull(r, descriptors); err != nil {
+		return nil, err
+	}
+	fields := []field{}
+	length := 1
+	for i := 0; i+32 <= len(descriptors) && descriptors[i] != dbfHeaderTerminator; i += 32 {
+		d := descriptors[i : i+32]
+		name := string(d[:11])
+		if n := strings.IndexByte(name, 0); n >= 0 {
+			name = name[:n]
+		}
+		f := field{
+			name:     strings.TrimSpace(name),
+			kind:     d[11],
+			length:   int(d[16]),
+			decimals: int(d[17]),
+		}
+		fields = append(fields, f)
+		length += f.length
+	}
+	if length > recordLen {
+		return nil, FormatError{"fields longer than .dbf record"}
+	}
+
+	records := []map[string]interface{}{}
+	record := make([]byte, recordLen)
+	for i := 0; i < recordCount; i++ {
+		if _, err := io.ReadFull(r, record); err != nil {
+			return nil, err
+		}
+		if record[0] == dbfDeleted {
+			records = append(records, nil)
+			continue
+		}
+
+		properties := make(map[string]interface{}, len(fields))
+		offset := 1
+		for _, f := range fields {
+			value, err := f.decode(record[offset : offset+f.length])
+			if err != nil {
+				return nil, err
+			}
+			properties[f.name] = value
+			offset += f.length
+		}
+		records = append(records, properties)
+	}
+	return records, nil
+}
+
+func (f field) decode(b []byte) (interface{}, error) {
+	s := strings.Trim(string(b), " \x00")
+	switch f.kind {
+	case 'C':
+		return strings.TrimRight(string(b), " \x00"), nil
+	case 'N', 'F':
+		if s == "" || strings.Trim(s, "*") == "" {
+			return nil, nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, FormatError{fmt.Sprintf("invalid number %q in field %s", s, f.name)}
+		}
+		return v, nil
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true, nil
+		case "F", "f", "N", "n":
+			return false, nil
+		default:
+			return nil, nil
+		}
+	case 'D':
+		if s == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(dbfDateLayout, s)
+		if err != nil {
+			return nil, FormatError{fmt.Sprintf("invalid date %q in field %s", s, f.name)}
+		}
+		return t, nil
+	default:
+		return s, nil
+	}
+}
+
+func featureProperties(t geom.T) (map[string]interface{}, error) {
+	f, ok := t.(geom.Feature)
+	if !ok || f.Properties == nil {
+		return nil, nil
+	}
+	properties, ok := f.Properties.(map[string]interface{})
+	if !ok {
+		return nil, UnsupportedPropertiesError{reflect.TypeOf(f.Properties)}
+	}
+	return properties, nil
+}
+
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func valueKind(v interface{}) byte {
+	switch v.(type) {
+	case bool:
+		return 'L'
+	case time.Time:
+		return 'D'
+	}
+	if _, ok := numberValue(v); ok {
+		return 'N'
+	}
+	return 'C'
+}
+
+// newFields infers a column for every property name. A column whose values
+// have different types is written as text.
+func newFields(records []map[string]interface{}) ([]field, error) {
+	kinds := make(map[string]byte)
+	for _, properties := range records {
+		for name, v := range properties {
+			if v == nil {
+				if _, ok := kinds[name]; !ok {
+					kinds[name] = 0
+				}
+				continue
+			}
+			kind := valueKind(v)
+			if k, ok := kinds[name]; ok && k != 0 && k != kind {
+				kind = 'C'
+			}
+			kinds[name] = kind
+		}
+	}
+
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]field, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		short := name
+		if len(short) > dbfMaxNameLength {
+			short = short[:dbfMaxNameLength]
+		}
+		if seen[short] {
+			return nil, FieldNameError{short}
+		}
+		seen[short] = true
+
+		f := field{name: short, property: name, kind: kinds[name]}
+		switch f.kind {
+		case 0:
+			f.kind = 'C'
+		case 'N':
+			for _, properties := range records {
+				if n, ok := numberValue(properties[name]); ok && !math.IsNaN(n) && !math.IsInf(n, 0) {
+					s := strconv.FormatFloat(n, 'f', -1, 64)
+					if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > f.decimals {
+						f.decimals = len(s) - i - 1
+					}
+				}
+			}
+			if f.decimals > 15 {
+				f.decimals = 15
+			}
+		}
+		f.length = f.maxLength(records)
+		if f.kind == 'N' && f.length > dbfMaxNumberLength {
+			// Numbers too wide for dBase are written as text rather than
+			// cut short.
+			f.kind, f.decimals = 'C', 0
+			f.length = f.maxLength(records)
+		}
+		if f.length > dbfMaxFieldLength {
+			f.length = dbfMaxFieldLength
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// maxLength returns the length of the longest value of the field in records,
+// and at least one.
+func (f field) maxLength(records []map[string]interface{}) int {
+	length := 1
+	for _, properties := range records {
+		if v, ok := properties[f.property]; ok {
+			if n := len(f.format(v)); n > length {
+				length = n
+			}
+		}
+	}
+	return length
+}
+
+// format returns the text of a value, which may be longer than the field.
+func (f field) format(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	switch f.kind {
+	case 'L':
+		if v.(bool) {
+			return "T"
+		}
+		return "F"
+	case 'D':
+		return v.(time.Time).Format(dbfDateLayout)
+	case 'N':
+		n, _ := numberValue(v)
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return ""
+		}
+		return strconv.FormatFloat(n, 'f', f.decimals, 64)
+	default:
+		if s, ok := v.(string); ok {
+			return s
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// writeDBF writes the properties of features as a dBase table. If there are
+// no properties at all, it writes a single FID column holding the record
+// number, since many readers reject tables without columns. modified is
+// written as the date of last update.
+func writeDBF(w io.Writer, features []geom.T, modified time.Time) error {
+	records := make([]map[string]interface{}, len(features))
+	for i, t := range features {
+		var err error
+		if records[i], err = featureProperties(t); err != nil {
+			return err
+		}
+	}
+
+	fields, err := newFields(records)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		fields = []field{{name: "FID", property: "FID", kind: 'N', length: len(strconv.Itoa(len(records)))}}
+		for i := range records {
+			records[i] = map[string]interface{}{"FID": i}
+		}
+	}
+
+	recordLen := 1
+	for _, f := range fields {
+		recordLen += f.length
+	}
+	headerLen := 32 + 32*len(fields) + 1
+
+	header := make([]byte, headerLen)
+	header[0] = dbfVersion
+	header[1] = byte(modified.Year() - 1900)
+	header[2] = byte(modified.Month())
+	header[3] = byte(modified.Day())
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(header[8:], uint16(headerLen))
+	binary.LittleEndian.PutUint16(header[10:], uint16(recordLen))
+	for i, f := range fields {
+		d := header[32+32*i:]
+		copy(d[:dbfMaxNameLength], f.name)
+		d[11] = f.kind
+		d[16] = byte(f.length)
+		d[17] = byte(f.decimals)
+	}
+	header[headerLen-1] = dbfHeaderTerminator
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]byte, recordLen)
+	for _, properties := range records {
+		record[0] = ' '
+		offset := 1
+		for _, f := range fields {
+			s := f.format(properties[f.property])
+			if len(s) > f.length {
+				s = s[:f.length]
+			}
+			var padded string
+			if f.kind == 'N' {
+				padded = strings.Repeat(" ", f.length-len(s)) + s
+			} else {
+				padded = s + strings.Repeat(" ", f.length-len(s))
+			}
+			copy(record[offset:], padded)
+			offset += f.length
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write([]byte{dbfEOF})
+	return err
+}
@@ -0,0 +1,210 @@
+// Package shapefile reads and writes ESRI Shapefiles: the main .shp file,
+// the .shx index and the .dbf attribute table.
+package shapefile
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+const (
+	shapeNull        = 0
+	shapePoint       = 1
+	shapePolyLine    = 3
+	shapePolygon     = 5
+	shapeMultiPoint  = 8
+	shapePointZ      = 11
+	shapePolyLineZ   = 13
+	shapePolygonZ    = 15
+	shapeMultiPointZ = 18
+	shapePointM      = 21
+	shapePolyLineM   = 23
+	shapePolygonM    = 25
+	shapeMultiPointM = 28
+)
+
+const (
+	fileCode     = 9994
+	fileVersion  = 1000
+	headerLength = 100
+)
+
+// Measures less than noData are "no data". Such measures are decoded as NaN,
+// and NaN measures are encoded as noDataValue.
+const (
+	noData      = -1e38
+	noDataValue = -1e39
+)
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "shapefile: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("shapefile: unsupported axes %d", e.Axes)
+}
+
+type UnsupportedShapeTypeError struct {
+	ShapeType int
+}
+
+func (e UnsupportedShapeTypeError) Error() string {
+	return fmt.Sprintf("shapefile: unsupported shape type %d", e.ShapeType)
+}
+
+// MixedShapeTypeError reports geometries that cannot share a file, since
+// every shape in a shapefile has the same type.
+type MixedShapeTypeError struct {
+	Want int
+	Got  int
+}
+
+func (e MixedShapeTypeError) Error() string {
+	return fmt.Sprintf("shapefile: shape type %d in file of shape type %d", e.Got, e.Want)
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "shapefile: " + e.Msg
+}
+
+type UnsupportedPropertiesError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedPropertiesError) Error() string {
+	return "shapefile: unsupported properties type: " + e.Type.String()
+}
+
+// FieldNameError reports property names that are the same once shortened
+// to the ten bytes a dBase field name can hold.
+type FieldNameError struct {
+	Name string
+}
+
+func (e FieldNameError) Error() string {
+	return fmt.Sprintf("shapefile: duplicate field name %q", e.Name)
+}
+
+// shapeAxes returns the base shape type (Point, PolyLine, Polygon or
+// MultiPoint) and the axes of a shape type. Z shapes may also have measures,
+// which are found record by record.
+func shapeAxes(shapeType int) (int, uint32, error) {
+	switch shapeType {
+	case shapeNull, shapePoint, shapePolyLine, shapePolygon, shapeMultiPoint:
+		return shapeType, geom.TwoD, nil
+	case shapePointZ, shapePolyLineZ, shapePolygonZ, shapeMultiPointZ:
+		return shapeType - 10, geom.Z, nil
+	case shapePointM, shapePolyLineM, shapePolygonM, shapeMultiPointM:
+		return shapeType - 20, geom.M, nil
+	default:
+		return 0, 0, UnsupportedShapeTypeError{shapeType}
+	}
+}
+
+// ReadFile reads name.shp and, if it exists, name.dbf. A .shp extension on
+// name is ignored.
+func ReadFile(name string) (geom.FeatureCollection, error) {
+	name = strings.TrimSuffix(name, ".shp")
+	shp, err := os.Open(name + ".shp")
+	if err != nil {
+		return geom.FeatureCollection{}, err
+	}
+	defer shp.Close()
+
+	dbf, err := os.Open(name + ".dbf")
+	if os.IsNotExist(err) {
+		return Read(shp, nil)
+	} else if err != nil {
+		return geom.FeatureCollection{}, err
+	}
+	defer dbf.Close()
+
+	return Read(shp, dbf)
+}
+
+// WriteFile writes name.shp, name.shx and name.dbf. A .shp extension on
+// name is ignored.
+func WriteFile(name string, fc geom.FeatureCollection, axes uint32) error {
+	name = strings.TrimSuffix(name, ".shp")
+	var files [3]*os.File
+	for i, ext := range []string{".shp", ".shx", ".dbf"} {
+		f, err := os.Create(name + ext)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		files[i] = f
+	}
+
+	if err := Write(files[0], files[1], files[2], fc, axes); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupRings builds polygons from the rings of a Polygon shape, where
+// exterior rings are clockwise and holes are counter-clockwise. Each hole
+// goes to the smallest exterior containing it; a hole outside every
+// exterior becomes a polygon of its own.
+func groupRings(rings []geom.Ring) geom.T {
+	polygons := geom.MultiPolygon{}
+	holes := []geom.Ring{}
+	for _, ring := range rings {
+		if planar.SignedArea(ring) <= 0 {
+			polygons = append(polygons, geom.Polygon{ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	for _, hole := range holes {
+		best, bestArea := -1, math.Inf(1)
+		for i, polygon := range polygons {
+			area := -planar.SignedArea(polygon[0])
+			if len(hole) != 0 && area < bestArea && planar.RingContains(polygon[0], hole[0]) {
+				best, bestArea = i, area
+			}
+		}
+		if best < 0 {
+			polygons = append(polygons, geom.Polygon{hole})
+		} else {
+			polygons[best] = append(polygons[best], hole)
+		}
+	}
+
+	switch len(polygons) {
+	case 0:
+		return geom.Polygon{}
+	case 1:
+		return polygons[0]
+	default:
+		return polygons
+	}
+}
@@ -0,0 +1,217 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+func TestShapefile(t *testing.T) {
+	var testCases = []struct {
+		geometries []geom.T
+		axes       uint32
+	}{
+		{[]geom.T{geom.Point{1, 2}, nil, geom.Point{3, 4}}, geom.TwoD},
+		{[]geom.T{geom.Point{1, 2, 3}}, geom.Z},
+		{[]geom.T{geom.Point{1, 2, 3}}, geom.M},
+		{[]geom.T{geom.Point{1, 2, 3, 4}}, geom.ZM},
+		{[]geom.T{geom.MultiPoint{{1, 2}, {3, 4}}, geom.MultiPoint{}}, geom.TwoD},
+		{[]geom.T{geom.MultiPoint{{1, 2, 5, 6}, {3, 4, 7, 8}}}, geom.ZM},
+		{[]geom.T{geom.LineString{{1, 2}, {3, 4}}, geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}}, geom.TwoD},
+		{[]geom.T{geom.LineString{{1, 2, 3}, {3, 4, 5}}}, geom.Z},
+		{[]geom.T{geom.LineString{{1, 2, 3}, {3, 4, 5}}}, geom.M},
+		{
+			[]geom.T{
+				geom.Polygon{
+					{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}},
+					{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+				},
+				geom.MultiPolygon{
+					{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+					{{{5, 5}, {5, 6}, {6, 6}, {6, 5}, {5, 5}}},
+				},
+			},
+			geom.TwoD,
+		},
+	}
+	for _, tc := range testCases {
+		fc := geom.FeatureCollection{}
+		for i, g := range tc.geometries {
+			fc = fc.AppendGeometry(g, map[string]interface{}{"n": float64(i)})
+		}
+
+		var shp, shx, dbf bytes.Buffer
+		if err := Write(&shp, &shx, &dbf, fc, tc.axes); err != nil {
+			t.Errorf("Write(%#v, %d) == %v, want nil", tc.geometries, tc.axes, err)
+			continue
+		}
+		if got := 2 * int(binary.BigEndian.Uint32(shp.Bytes()[24:])); got != shp.Len() {
+			t.Errorf("Write(%#v, %d) file length %d, want %d", tc.geometries, tc.axes, got, shp.Len())
+		}
+		if want := headerLength + 8*len(tc.geometries); shx.Len() != want {
+			t.Errorf("Write(%#v, %d) index length %d, want %d", tc.geometries, tc.axes, shx.Len(), want)
+		}
+
+		got, err := Read(&shp, &dbf)
+		if err != nil || !reflect.DeepEqual(got, fc) {
+			t.Errorf("Read(Write(%#v, %d)) == %#v, %v, want %#v, nil", tc.geometries, tc.axes, got, err, fc)
+		}
+	}
+}
+
+func TestShapefileIndex(t *testing.T) {
+	fc := geom.FeatureCollection{Features: []geom.T{geom.Point{1, 2}, geom.Point{3, 4}}}
+	var shp, shx bytes.Buffer
+	if err := Write(&shp, &shx, nil, fc, geom.TwoD); err != nil {
+		t.Fatal(err)
+	}
+	index := shx.Bytes()[headerLength:]
+	want := []uint32{50, 10, 64, 10}
+	for i, w := range want {
+		if got := binary.BigEndian.Uint32(index[4*i:]); got != w {
+			t.Errorf("index word %d == %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestShapefileRings(t *testing.T) {
+	exterior := geom.Ring{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}
+	island := geom.Ring{{20, 0}, {20, 1}, {21, 1}, {21, 0}, {20, 0}}
+	hole := geom.Ring{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}
+	var testCases = []struct {
+		rings []geom.Ring
+		want  geom.T
+	}{
+		{[]geom.Ring{}, geom.Polygon{}},
+		{[]geom.Ring{exterior}, geom.Polygon{exterior}},
+		{[]geom.Ring{hole, island, exterior}, geom.MultiPolygon{{island}, {exterior, hole}}},
+		{[]geom.Ring{island, hole}, geom.MultiPolygon{{island}, {hole}}},
+	}
+	for _, tc := range testCases {
+		if got := groupRings(tc.rings); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("groupRings(%#v) == %#v, want %#v", tc.rings, got, tc.want)
+		}
+	}
+
+	// counter-clockwise exteriors are reversed when written
+	fc := geom.FeatureCollection{Features: []geom.T{geom.Polygon{planar.Reversed(exterior)}}}
+	var shp bytes.Buffer
+	if err := Write(&shp, nil, nil, fc, geom.TwoD); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&shp, nil)
+	want := geom.FeatureCollection{Features: []geom.T{geom.NewFeature(geom.Polygon{exterior}, nil)}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Read == %#v, %v, want %#v, nil", got, err, want)
+	}
+}
+
+func TestShapefileMeasures(t *testing.T) {
+	// SRIDs and Layouts are dropped.
+	measured := geom.NewLayoutGeometry(geom.LineString{{1, 2, math.NaN()}, {3, 4, 5}}, geom.XYM)
+	fc := geom.FeatureCollection{Features: []geom.T{geom.NewFeature(geom.NewSRIDGeometry(measured, 4326), nil)}}
+	var shp bytes.Buffer
+	if err := Write(&shp, nil, nil, fc, geom.M); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&shp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineString := got.Features[0].(geom.Feature).T.(geom.LineString)
+	if !math.IsNaN(lineString[0][2]) || lineString[1][2] != 5 {
+		t.Errorf("Read == %#v, want measures NaN, 5", lineString)
+	}
+}
+
+func TestDBF(t *testing.T) {
+	date := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)
+	fc := geom.FeatureCollection{}
+	fc = fc.AppendGeometry(geom.Point{1, 2}, map[string]interface{}{
+		"name":             "first",
+		"count":            3,
+		"ratio":            1.25,
+		"valid":            true,
+		"date":             date,
+		"a_very_long_name": "x",
+		"mixed":            1,
+		"huge":             1e300,
+	})
+	fc = fc.AppendGeometry(geom.Point{3, 4}, map[string]interface{}{
+		"name":  "second",
+		"count": -12,
+		"mixed": "one",
+	})
+	fc = fc.AppendGeometry(geom.Point{5, 6}, nil)
+
+	var shp, dbf bytes.Buffer
+	if err := Write(&shp, nil, &dbf, fc, geom.TwoD); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&shp, &dbf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]interface{}{
+		{"name": "first", "count": 3.0, "ratio": 1.25, "valid": true, "date": date, "a_very_lon": "x", "mixed": "1", "huge": "1e+300"},
+		{"name": "second", "count": -12.0, "ratio": nil, "valid": nil, "date": nil, "a_very_lon": "", "mixed": "one", "huge": ""},
+		{"name": "", "count": nil, "ratio": nil, "valid": nil, "date": nil, "a_very_lon": "", "mixed": "", "huge": ""},
+	}
+	for i, w := range want {
+		if p := got.Features[i].(geom.Feature).Properties; !reflect.DeepEqual(p, w) {
+			t.Errorf("properties %d == %#v, want %#v", i, p, w)
+		}
+	}
+
+	// a table without properties gets a record number column
+	dbf.Reset()
+	if err := writeDBF(&dbf, []geom.T{geom.Point{1, 2}}, date); err != nil {
+		t.Fatal(err)
+	}
+	if got := dbf.Bytes()[1:4]; !bytes.Equal(got, []byte{120, 2, 29}) {
+		t.Errorf("writeDBF date == %v, want [120 2 29]", got)
+	}
+	if records, err := readDBF(&dbf); err != nil || !reflect.DeepEqual(records, []map[string]interface{}{{"FID": 0.0}}) {
+		t.Errorf("readDBF == %#v, %v", records, err)
+	}
+}
+
+func TestShapefileError(t *testing.T) {
+	var testCases = []struct {
+		geometries []geom.T
+		axes       uint32
+		err        error
+	}{
+		{[]geom.T{geom.Point{1, 2}, geom.LineString{{1, 2}}}, geom.TwoD, MixedShapeTypeError{shapePoint, shapePolyLine}},
+		{[]geom.T{geom.GeometryCollection{}}, geom.TwoD, UnsupportedGeometryError{reflect.TypeOf(geom.GeometryCollection{})}},
+		{[]geom.T{geom.Point{1, 2}}, geom.Z, DimensionError{Dimension: 3, ElementCount: 2}},
+		{[]geom.T{geom.Point{1, 2}}, 7, UnsupportedAxesError{7}},
+		{[]geom.T{geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)}, geom.Z, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XYZ}},
+		{[]geom.T{geom.NewFeature(geom.Point{1, 2}, "name")}, geom.TwoD, UnsupportedPropertiesError{reflect.TypeOf("")}},
+		{
+			[]geom.T{
+				geom.NewFeature(geom.Point{1, 2}, map[string]interface{}{"population1": 1, "population2": 2}),
+			},
+			geom.TwoD,
+			FieldNameError{"population"},
+		},
+	}
+	for _, tc := range testCases {
+		var shp, dbf bytes.Buffer
+		fc := geom.FeatureCollection{Features: tc.geometries}
+		if err := Write(&shp, nil, &dbf, fc, tc.axes); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Write(%#v, %d) == %#v, want %#v", tc.geometries, tc.axes, err, tc.err)
+		}
+	}
+
+	if _, err := Read(bytes.NewReader(make([]byte, headerLength)), nil); !reflect.DeepEqual(err, FormatError{"bad file code"}) {
+		t.Errorf("Read(zeros) == %#v, want bad file code", err)
+	}
+}
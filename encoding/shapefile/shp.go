@@ -0,0 +1,625 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/internal/planar"
+)
+
+// Read reads a shapefile into a FeatureCollection of geom.Features, one per
+// record. dbf may be nil; otherwise each record's attributes become its
+// feature's properties, as a map[string]interface{}. Null shapes become
+// features with a nil geometry.
+//
+// Polygon shapes are decoded to a Polygon, or to a MultiPolygon if they
+// have more than one exterior ring. PolyLine shapes with one part are
+// decoded to a LineString, otherwise to a MultiLineString. Z shapes decode
+// to points with three components, or four if the record has measures. M
+// shapes decode to points with three components, the third being the
+// measure.
+func Read(shp, dbf io.Reader) (geom.FeatureCollection, error) {
+	shapes, err := readShapes(shp)
+	if err != nil {
+		return geom.FeatureCollection{}, err
+	}
+
+	var records []map[string]interface{}
+	if dbf != nil {
+		if records, err = readDBF(dbf); err != nil {
+			return geom.FeatureCollection{}, err
+		}
+		if len(records) != len(shapes) {
+			return geom.FeatureCollection{}, FormatError{"record count in .dbf does not match .shp"}
+		}
+	}
+
+	features := make([]geom.T, len(shapes))
+	for i, shape := range shapes {
+		feature := geom.NewFeature(shape, nil)
+		if records != nil && records[i] != nil {
+			feature.Properties = records[i]
+		}
+		features[i] = feature
+	}
+	return geom.FeatureCollection{Features: features}, nil
+}
+
+func readShapes(r io.Reader) ([]geom.T, error) {
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(header[0:]) != fileCode {
+		return nil, FormatError{"bad file code"}
+	}
+	fileLength := 2 * int64(binary.BigEndian.Uint32(header[24:]))
+	shapeType := int(int32(binary.LittleEndian.Uint32(header[32:])))
+	if _, _, err := shapeAxes(shapeType); err != nil {
+		return nil, err
+	}
+
+	shapes := []geom.T{}
+	offset := int64(headerLength)
+	recordHeader := make([]byte, 8)
+	for offset < fileLength {
+		if _, err := io.ReadFull(r, recordHeader); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		contentLength := 2 * int64(binary.BigEndian.Uint32(recordHeader[4:]))
+		var content bytes.Buffer
+		if n, err := content.ReadFrom(io.LimitReader(r, contentLength)); err != nil {
+			return nil, err
+		} else if n != contentLength {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		shape, err := decodeShape(content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, shape)
+		offset += 8 + contentLength
+	}
+	return shapes, nil
+}
+
+// decoder reads little-endian values from a record, remembering the first
+// error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) int32() int {
+	if b := d.next(4); b != nil {
+		return int(int32(binary.LittleEndian.Uint32(b)))
+	}
+	return 0
+}
+
+func (d *decoder) float64() float64 {
+	if b := d.next(8); b != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+// count reads a count of items of the given size, checking that the record
+// is long enough to hold them.
+func (d *decoder) count(size int) int {
+	n := d.int32()
+	if d.err == nil && (n < 0 || n > len(d.data)/size) {
+		d.err = FormatError{"invalid count"}
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) measure() float64 {
+	m := d.float64()
+	if m < noData {
+		return math.NaN()
+	}
+	return m
+}
+
+func (d *decoder) points(n int) []geom.Point {
+	points := make([]geom.Point, n)
+	for i := range points {
+		points[i] = make(geom.Point, 2, 4)
+		points[i][geom.X] = d.float64()
+		points[i][geom.Y] = d.float64()
+	}
+	return points
+}
+
+// extraAxes reads the Z and M arrays that follow the points of a
+// multi-point shape. The M array is optional in Z shapes; it is kept only
+// if it holds a measure.
+func (d *decoder) extraAxes(points []geom.Point, axes uint32) {
+	if axes == geom.Z {
+		d.next(16)
+		for i := range points {
+			points[i] = append(points[i], d.float64())
+		}
+		if len(d.data) < 16+8*len(points) {
+			return
+		}
+	}
+	if axes != geom.TwoD {
+		d.next(16)
+		measures := make([]float64, len(points))
+		hasMeasure := false
+		for i := range measures {
+			measures[i] = d.measure()
+			hasMeasure = hasMeasure || !math.IsNaN(measures[i])
+		}
+		if axes == geom.M || hasMeasure {
+			for i, m := range measures {
+				points[i] = append(points[i], m)
+			}
+		}
+	}
+}
+
+func decodeShape(data []byte) (geom.T, error) {
+	d := &decoder{data: data}
+	shapeType := d.int32()
+	baseType, axes, err := shapeAxes(shapeType)
+	if err != nil {
+		return nil, err
+	}
+
+	var g geom.T
+	switch baseType {
+	case shapeNull:
+		return nil, d.err
+	case shapePoint:
+		point := d.points(1)[0]
+		if axes == geom.Z {
+			point = append(point, d.float64())
+			if len(d.data) >= 8 {
+				if m := d.measure(); !math.IsNaN(m) {
+					point = append(point, m)
+				}
+			}
+		} else if axes == geom.M {
+			point = append(point, d.measure())
+		}
+		g = point
+	case shapeMultiPoint:
+		d.next(32)
+		points := d.points(d.count(16))
+		d.extraAxes(points, axes)
+		g = geom.MultiPoint(points)
+	case shapePolyLine, shapePolygon:
+		d.next(32)
+		numParts := d.int32()
+		numPoints := d.int32()
+		if d.err == nil && (numParts < 0 || numPoints < 0 || numParts > len(d.data)/4 || numPoints > len(d.data)/16) {
+			return nil, FormatError{"invalid count"}
+		}
+		starts := make([]int, numParts)
+		for i := range starts {
+			starts[i] = d.int32()
+		}
+		points := d.points(numPoints)
+		d.extraAxes(points, axes)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		parts := make([][]geom.Point, numParts)
+		for i, start := range starts {
+			end := numPoints
+			if i+1 < numParts {
+				end = starts[i+1]
+			}
+			if start < 0 || start > end || end > numPoints {
+				return nil, FormatError{"invalid part index"}
+			}
+			parts[i] = points[start:end:end]
+		}
+
+		if baseType == shapePolygon {
+			rings := make([]geom.Ring, len(parts))
+			for i, part := range parts {
+				rings[i] = part
+			}
+			g = groupRings(rings)
+		} else if len(parts) == 1 {
+			g = geom.LineString(parts[0])
+		} else {
+			lineStrings := make(geom.MultiLineString, len(parts))
+			for i, part := range parts {
+				lineStrings[i] = part
+			}
+			g = lineStrings
+		}
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	return g, nil
+}
+
+// Write writes features as a shapefile, with the geometries to shp, the
+// index to shx and the attributes to dbf. shx and dbf may be nil to skip
+// those files. Each element of fc.Features may be a geom.Feature, whose
+// Properties must be nil or a map[string]interface{}, or a bare geometry.
+//
+// All geometries must map to the same shape type: Point, MultiPoint,
+// LineString and MultiLineString (PolyLine), or Polygon and MultiPolygon.
+// Polygon rings are reoriented so that exteriors are clockwise and holes
+// counter-clockwise. Axes geom.Z and geom.ZM produce Z shapes, with
+// measures only for geom.ZM; geom.M produces M shapes. NaN measures are
+// written as "no data". SRIDs are dropped, and a Layout attached with
+// geom.NewLayoutGeometry must match axes. The .dbf table is dated today.
+func Write(shp, shx, dbf io.Writer, fc geom.FeatureCollection, axes uint32) error {
+	if geom.Layout(axes).Stride() == 0 {
+		return UnsupportedAxesError{axes}
+	}
+
+	geometries := make([]geom.T, len(fc.Features))
+	shapeType := shapeNull
+	for i, t := range fc.Features {
+		g := t
+		if f, ok := g.(geom.Feature); ok {
+			g = f.T
+		}
+		if err := geom.MatchLayout(g, geom.Layout(axes)); err != nil {
+			return err
+		}
+		g = geom.Unwrap(g)
+		geometries[i] = g
+		if g == nil {
+			continue
+		}
+
+		baseType, err := baseShapeType(g)
+		if err != nil {
+			return err
+		}
+		if shapeType == shapeNull {
+			shapeType = baseType
+		} else if shapeType != baseType {
+			return MixedShapeTypeError{shapeType, baseType}
+		}
+	}
+	if shapeType != shapeNull {
+		switch axes {
+		case geom.Z, geom.ZM:
+			shapeType += 10
+		case geom.M:
+			shapeType += 20
+		}
+	}
+
+	e := &encoder{axes: axes, bounds: newExtent(), z: newRange(), m: newRange()}
+	var records bytes.Buffer
+	var index bytes.Buffer
+	for i, g := range geometries {
+		e.buf.Reset()
+		if err := e.shape(g, shapeType); err != nil {
+			return err
+		}
+
+		var recordHeader [8]byte
+		binary.BigEndian.PutUint32(recordHeader[0:], uint32(i+1))
+		binary.BigEndian.PutUint32(recordHeader[4:], uint32(e.buf.Len()/2))
+		index.Write(indexRecord(headerLength+records.Len(), e.buf.Len()))
+		records.Write(recordHeader[:])
+		records.Write(e.buf.Bytes())
+	}
+
+	if shx != nil {
+		if _, err := shx.Write(e.header(shapeType, headerLength+index.Len())); err != nil {
+			return err
+		}
+		if _, err := shx.Write(index.Bytes()); err != nil {
+			return err
+		}
+	}
+	if _, err := shp.Write(e.header(shapeType, headerLength+records.Len())); err != nil {
+		return err
+	}
+	if _, err := shp.Write(records.Bytes()); err != nil {
+		return err
+	}
+	if dbf != nil {
+		return writeDBF(dbf, fc.Features, time.Now())
+	}
+	return nil
+}
+
+// indexRecord returns the .shx entry for a shape record: its offset and
+// content length, in 16-bit words.
+func indexRecord(offset, contentLength int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[0:], uint32(offset/2))
+	binary.BigEndian.PutUint32(b[4:], uint32(contentLength/2))
+	return b
+}
+
+func baseShapeType(g geom.T) (int, error) {
+	switch g.(type) {
+	case geom.Point:
+		return shapePoint, nil
+	case geom.MultiPoint:
+		return shapeMultiPoint, nil
+	case geom.LineString, geom.MultiLineString:
+		return shapePolyLine, nil
+	case geom.Polygon, geom.MultiPolygon:
+		return shapePolygon, nil
+	default:
+		return 0, UnsupportedGeometryError{reflect.TypeOf(g)}
+	}
+}
+
+// valueRange is the range of Z or M values in a shape or file.
+type valueRange struct {
+	min, max float64
+}
+
+func newRange() valueRange {
+	return valueRange{math.Inf(1), math.Inf(-1)}
+}
+
+func (r *valueRange) extend(v float64) {
+	if !math.IsNaN(v) {
+		r.min = math.Min(r.min, v)
+		r.max = math.Max(r.max, v)
+	}
+}
+
+func (r *valueRange) union(other valueRange) {
+	if other.min <= other.max {
+		r.min = math.Min(r.min, other.min)
+		r.max = math.Max(r.max, other.max)
+	}
+}
+
+func (r valueRange) values() (float64, float64) {
+	if r.min > r.max {
+		return 0, 0
+	}
+	return r.min, r.max
+}
+
+// extent is the X and Y range of a shape or file.
+type extent struct {
+	x, y valueRange
+}
+
+func newExtent() extent {
+	return extent{newRange(), newRange()}
+}
+
+func (e *extent) union(other extent) {
+	e.x.union(other.x)
+	e.y.union(other.y)
+}
+
+type encoder struct {
+	axes   uint32
+	buf    bytes.Buffer
+	bounds extent
+	z, m   valueRange
+}
+
+func (e *encoder) int32(v int) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(v))
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) float64(v float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) measure(m float64) {
+	if math.IsNaN(m) {
+		m = noDataValue
+	}
+	e.float64(m)
+}
+
+func (e *encoder) header(shapeType, length int) []byte {
+	b := make([]byte, headerLength)
+	binary.BigEndian.PutUint32(b[0:], fileCode)
+	binary.BigEndian.PutUint32(b[24:], uint32(length/2))
+	binary.LittleEndian.PutUint32(b[28:], fileVersion)
+	binary.LittleEndian.PutUint32(b[32:], uint32(shapeType))
+
+	xMin, xMax := e.bounds.x.values()
+	yMin, yMax := e.bounds.y.values()
+	zMin, zMax := e.z.values()
+	mMin, mMax := e.m.values()
+	for i, v := range []float64{xMin, yMin, xMax, yMax, zMin, zMax, mMin, mMax} {
+		binary.LittleEndian.PutUint64(b[36+8*i:], math.Float64bits(v))
+	}
+	return b
+}
+
+// zIndex and mIndex return the index of Z and M in a point, or -1.
+func (e *encoder) zIndex() int {
+	if e.axes&geom.Z != 0 {
+		return 2
+	}
+	return -1
+}
+
+func (e *encoder) mIndex() int {
+	switch e.axes {
+	case geom.M:
+		return 2
+	case geom.ZM:
+		return 3
+	default:
+		return -1
+	}
+}
+
+func (e *encoder) shape(g geom.T, shapeType int) error {
+	if g == nil {
+		e.int32(shapeNull)
+		return nil
+	}
+
+	var parts [][]geom.Point
+	switch g := g.(type) {
+	case geom.Point:
+		return e.point(g, shapeType)
+	case geom.MultiPoint:
+		return e.multiPart([][]geom.Point{g}, shapeType, false)
+	case geom.LineString:
+		parts = [][]geom.Point{g}
+	case geom.MultiLineString:
+		for _, lineString := range g {
+			parts = append(parts, lineString)
+		}
+	case geom.Polygon:
+		parts = orientedRings(g, nil)
+	case geom.MultiPolygon:
+		for _, polygon := range g {
+			parts = orientedRings(polygon, parts)
+		}
+	}
+	return e.multiPart(parts, shapeType, true)
+}
+
+func (e *encoder) checkPoint(point geom.Point) error {
+	if dimension := geom.Layout(e.axes).Stride(); len(point) < dimension {
+		return DimensionError{Dimension: dimension, ElementCount: len(point)}
+	}
+	return nil
+}
+
+func (e *encoder) point(point geom.Point, shapeType int) error {
+	if err := e.checkPoint(point); err != nil {
+		return err
+	}
+
+	e.int32(shapeType)
+	e.float64(point[geom.X])
+	e.float64(point[geom.Y])
+	e.bounds.x.extend(point[geom.X])
+	e.bounds.y.extend(point[geom.Y])
+	if i := e.zIndex(); i >= 0 {
+		e.float64(point[i])
+		e.z.extend(point[i])
+	}
+	if i := e.mIndex(); i >= 0 {
+		e.measure(point[i])
+		e.m.extend(point[i])
+	} else if e.axes == geom.Z {
+		e.measure(math.NaN())
+	}
+	return nil
+}
+
+// orientedRings appends the rings of polygon to parts, with the exterior
+// clockwise and the holes counter-clockwise.
+func orientedRings(polygon geom.Polygon, parts [][]geom.Point) [][]geom.Point {
+	for i, ring := range polygon {
+		a := planar.SignedArea(ring)
+		if (i == 0 && a > 0) || (i != 0 && a < 0) {
+			ring = planar.Reversed(ring)
+		}
+		parts = append(parts, ring)
+	}
+	return parts
+}
+
+// multiPart encodes MultiPoint, PolyLine and Polygon shapes, which differ
+// only in whether they have a parts array.
+func (e *encoder) multiPart(parts [][]geom.Point, shapeType int, hasParts bool) error {
+	points := []geom.Point{}
+	shapeBounds := newExtent()
+	z, m := newRange(), newRange()
+	for _, part := range parts {
+		for _, point := range part {
+			if err := e.checkPoint(point); err != nil {
+				return err
+			}
+			shapeBounds.x.extend(point[geom.X])
+			shapeBounds.y.extend(point[geom.Y])
+			if i := e.zIndex(); i >= 0 {
+				z.extend(point[i])
+			}
+			if i := e.mIndex(); i >= 0 {
+				m.extend(point[i])
+			}
+			points = append(points, point)
+		}
+	}
+	e.bounds.union(shapeBounds)
+	e.z.union(z)
+	e.m.union(m)
+
+	e.int32(shapeType)
+	xMin, xMax := shapeBounds.x.values()
+	yMin, yMax := shapeBounds.y.values()
+	e.float64(xMin)
+	e.float64(yMin)
+	e.float64(xMax)
+	e.float64(yMax)
+	if hasParts {
+		e.int32(len(parts))
+	}
+	e.int32(len(points))
+	if hasParts {
+		start := 0
+		for _, part := range parts {
+			e.int32(start)
+			start += len(part)
+		}
+	}
+	for _, point := range points {
+		e.float64(point[geom.X])
+		e.float64(point[geom.Y])
+	}
+
+	if i := e.zIndex(); i >= 0 {
+		zMin, zMax := z.values()
+		e.float64(zMin)
+		e.float64(zMax)
+		for _, point := range points {
+			e.float64(point[i])
+		}
+	}
+	if i := e.mIndex(); i >= 0 {
+		mMin, mMax := m.values()
+		e.float64(mMin)
+		e.float64(mMax)
+		for _, point := range points {
+			e.measure(point[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,413 @@
+// Package spatialite implements the SpatiaLite geometry blob: a header with
+// the SRID and minimum bounding rectangle, followed by a WKB-like body in
+// which each member of a collection is introduced by a marker byte.
+package spatialite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+const (
+	blobStart  = 0x00
+	blobMBREnd = 0x7c
+	blobEntity = 0x69
+	blobEnd    = 0xfe
+
+	blobXDR = 0x00
+	blobNDR = 0x01
+
+	// headerLength covers the start byte, byte order, SRID, MBR, MBR end
+	// marker and class type.
+	headerLength = 43
+)
+
+const (
+	classPoint              = 1
+	classLineString         = 2
+	classPolygon            = 3
+	classMultiPoint         = 4
+	classMultiLineString    = 5
+	classMultiPolygon       = 6
+	classGeometryCollection = 7
+)
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "spatialite: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("spatialite: unsupported axes %d", e.Axes)
+}
+
+// UnsupportedClassError reports a class type this package cannot decode,
+// including SpatiaLite's compressed geometries.
+type UnsupportedClassError struct {
+	Class uint32
+}
+
+func (e UnsupportedClassError) Error() string {
+	return fmt.Sprintf("spatialite: unsupported class type %d", e.Class)
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "spatialite: " + e.Msg
+}
+
+// MBR returns the minimum bounding rectangle stored in the header of a
+// blob, without decoding the geometry.
+func MBR(data []byte) (geom.Bounds, error) {
+	byteOrder, err := header(data)
+	if err != nil {
+		return geom.Bounds{}, err
+	}
+	f := func(i int) float64 {
+		return math.Float64frombits(byteOrder.Uint64(data[6+8*i:]))
+	}
+	return geom.Bounds{Min: geom.Point{f(0), f(1)}, Max: geom.Point{f(2), f(3)}}, nil
+}
+
+// header checks the fixed header and returns the byte order.
+func header(data []byte) (binary.ByteOrder, error) {
+	if len(data) < headerLength+1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if data[0] != blobStart || data[38] != blobMBREnd || data[len(data)-1] != blobEnd {
+		return nil, FormatError{"missing marker"}
+	}
+	switch data[1] {
+	case blobXDR:
+		return binary.BigEndian, nil
+	case blobNDR:
+		return binary.LittleEndian, nil
+	default:
+		return nil, FormatError{fmt.Sprintf("invalid byte order %d", data[1])}
+	}
+}
+
+// Decode decodes a SpatiaLite blob. A nonzero SRID is returned as a
+// geom.SRIDGeometry.
+func Decode(data []byte) (geom.T, error) {
+	byteOrder, err := header(data)
+	if err != nil {
+		return nil, err
+	}
+	srid := byteOrder.Uint32(data[2:])
+
+	d := &decoder{data: data[39 : len(data)-1], byteOrder: byteOrder}
+	g, err := d.geometry(d.uint32(), 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) != 0 {
+		return nil, FormatError{"unexpected data after geometry"}
+	}
+	if srid != 0 {
+		g = geom.NewSRIDGeometry(g, srid)
+	}
+	return g, nil
+}
+
+type decoder struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+	err       error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return d.byteOrder.Uint32(b)
+	}
+	return 0
+}
+
+// count reads a count of items at least size bytes long, checking that
+// there is room for them.
+func (d *decoder) count(size int) int {
+	n := d.uint32()
+	if d.err == nil && uint64(n)*uint64(size) > uint64(len(d.data)) {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) points(n, dimension int) []geom.Point {
+	points := make([]geom.Point, n)
+	for i := range points {
+		b := d.next(8 * dimension)
+		if b == nil {
+			return nil
+		}
+		point := make(geom.Point, dimension)
+		for j := range point {
+			point[j] = math.Float64frombits(d.byteOrder.Uint64(b[8*j:]))
+		}
+		points[i] = point
+	}
+	return points
+}
+
+func (d *decoder) pointss(dimension int) [][]geom.Point {
+	pointss := make([][]geom.Point, d.count(4))
+	for i := range pointss {
+		pointss[i] = d.points(d.count(8*dimension), dimension)
+	}
+	return pointss
+}
+
+// maxDepth limits the nesting of geometry collections.
+const maxDepth = 64
+
+func (d *decoder) geometry(class uint32, depth int) (geom.T, error) {
+	if depth > maxDepth {
+		return nil, FormatError{"geometry nested too deeply"}
+	}
+	axes, base := class/1000, class%1000
+	dimension := geom.Layout(axes).Stride()
+	if dimension == 0 || base < classPoint || base > classGeometryCollection {
+		return nil, UnsupportedClassError{class}
+	}
+
+	var g geom.T
+	switch base {
+	case classPoint:
+		if points := d.points(1, dimension); points != nil {
+			g = points[0]
+		}
+	case classLineString:
+		g = geom.LineString(d.points(d.count(8*dimension), dimension))
+	case classPolygon:
+		pointss := d.pointss(dimension)
+		polygon := make(geom.Polygon, len(pointss))
+		for i, ring := range pointss {
+			polygon[i] = ring
+		}
+		g = polygon
+	default:
+		members := make([]geom.T, d.count(5))
+		for i := range members {
+			marker := d.next(1)
+			if marker != nil && marker[0] != blobEntity {
+				return nil, FormatError{"missing entity marker"}
+			}
+			memberClass := d.uint32()
+			if d.err != nil {
+				break
+			}
+			if base != classGeometryCollection && memberClass != axes*1000+base-3 {
+				return nil, FormatError{fmt.Sprintf("class type %d in class type %d", memberClass, class)}
+			}
+			var err error
+			if members[i], err = d.geometry(memberClass, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		g = collect(base, members)
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	return g, nil
+}
+
+func collect(base uint32, members []geom.T) geom.T {
+	switch base {
+	case classMultiPoint:
+		multiPoint := make(geom.MultiPoint, len(members))
+		for i, member := range members {
+			multiPoint[i], _ = member.(geom.Point)
+		}
+		return multiPoint
+	case classMultiLineString:
+		multiLineString := make(geom.MultiLineString, len(members))
+		for i, member := range members {
+			multiLineString[i], _ = member.(geom.LineString)
+		}
+		return multiLineString
+	case classMultiPolygon:
+		multiPolygon := make(geom.MultiPolygon, len(members))
+		for i, member := range members {
+			multiPolygon[i], _ = member.(geom.Polygon)
+		}
+		return multiPolygon
+	default:
+		return geom.GeometryCollection(members)
+	}
+}
+
+// Encode encodes g as a SpatiaLite blob, with the SRID of a
+// geom.SRIDGeometry and the MBR computed from geom.Bounds. The MBR of an empty
+// geometry is all zeros, and an empty point has NaN coordinates. The SRIDs
+// of collection members must agree, and any Layout attached to g must match
+// axes.
+func Encode(g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	srid, _, err := geom.SRIDOf(g)
+	if err != nil {
+		return nil, err
+	}
+	dimension := geom.Layout(axes).Stride()
+	if dimension == 0 {
+		return nil, UnsupportedAxesError{axes}
+	}
+	if err := geom.MatchLayout(g, geom.Layout(axes)); err != nil {
+		return nil, err
+	}
+
+	e := &encoder{byteOrder: byteOrder, axes: axes, dimension: dimension}
+	e.data = make([]byte, headerLength-4, 128)
+	e.data[0] = blobStart
+	if byteOrder == binary.LittleEndian {
+		e.data[1] = blobNDR
+	} else {
+		e.data[1] = blobXDR
+	}
+	byteOrder.PutUint32(e.data[2:], srid)
+
+	b := g.Bounds(geom.NewBounds())
+	mbr := []float64{0, 0, 0, 0}
+	if !b.IsZero() && !b.Empty() {
+		mbr = []float64{b.Min[geom.X], b.Min[geom.Y], b.Max[geom.X], b.Max[geom.Y]}
+	}
+	for i, v := range mbr {
+		byteOrder.PutUint64(e.data[6+8*i:], math.Float64bits(v))
+	}
+	e.data[38] = blobMBREnd
+
+	if err := e.geometry(g); err != nil {
+		return nil, err
+	}
+	return append(e.data, blobEnd), nil
+}
+
+type encoder struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+	axes      uint32
+	dimension int
+}
+
+func (e *encoder) uint32(v uint32) {
+	var b [4]byte
+	e.byteOrder.PutUint32(b[:], v)
+	e.data = append(e.data, b[:]...)
+}
+
+func (e *encoder) points(points []geom.Point, withCount bool) error {
+	if withCount {
+		e.uint32(uint32(len(points)))
+	}
+	var b [8]byte
+	for _, point := range points {
+		if len(point) < e.dimension {
+			return DimensionError{Dimension: e.dimension, ElementCount: len(point)}
+		}
+		for _, c := range point[:e.dimension] {
+			e.byteOrder.PutUint64(b[:], math.Float64bits(c))
+			e.data = append(e.data, b[:]...)
+		}
+	}
+	return nil
+}
+
+// geometry writes the class type and body of g.
+func (e *encoder) geometry(g geom.T) error {
+	var class uint32
+	var members []geom.T
+	g = geom.Unwrap(g)
+	switch g := g.(type) {
+	case geom.Point:
+		class = classPoint
+	case geom.LineString:
+		class = classLineString
+	case geom.Polygon:
+		class = classPolygon
+	case geom.MultiPoint:
+		class = classMultiPoint
+		for _, point := range g {
+			members = append(members, point)
+		}
+	case geom.MultiLineString:
+		class = classMultiLineString
+		for _, lineString := range g {
+			members = append(members, lineString)
+		}
+	case geom.MultiPolygon:
+		class = classMultiPolygon
+		for _, polygon := range g {
+			members = append(members, polygon)
+		}
+	case geom.GeometryCollection:
+		class = classGeometryCollection
+		members = g
+	default:
+		return UnsupportedGeometryError{reflect.TypeOf(g)}
+	}
+	e.uint32(e.axes*1000 + class)
+
+	switch g := g.(type) {
+	case geom.Point:
+		if len(g) == 0 {
+			// an empty point is written with NaN coordinates, as in WKB
+			g = make(geom.Point, e.dimension)
+			for i := range g {
+				g[i] = math.NaN()
+			}
+		}
+		return e.points([]geom.Point{g}, false)
+	case geom.LineString:
+		return e.points(g, true)
+	case geom.Polygon:
+		e.uint32(uint32(len(g)))
+		for _, ring := range g {
+			if err := e.points(ring, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	e.uint32(uint32(len(members)))
+	for _, member := range members {
+		e.data = append(e.data, blobEntity)
+		if err := e.geometry(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
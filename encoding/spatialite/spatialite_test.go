@@ -0,0 +1,120 @@
+package spatialite
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+const pointBlob = "0001e6100000" +
+	"000000000000f03f0000000000000040000000000000f03f0000000000000040" +
+	"7c01000000000000000000f03f0000000000000040fe"
+
+func TestSpatiaLite(t *testing.T) {
+	g := geom.NewSRIDGeometry(geom.Point{1, 2}, 4326)
+	got, err := Encode(g, binary.LittleEndian, geom.TwoD)
+	if err != nil || hex.EncodeToString(got) != pointBlob {
+		t.Errorf("Encode(%#v) == %x, %v, want %s, nil", g, got, err, pointBlob)
+	}
+
+	multiPoint := "0001e6100000" +
+		"000000000000f03f000000000000004000000000000008400000000000001040" +
+		"7c0400000002000000" +
+		"6901000000000000000000f03f0000000000000040" +
+		"69010000000000000000000840" + "0000000000001040fe"
+	data, _ := hex.DecodeString(multiPoint)
+	want := geom.NewSRIDGeometry(geom.MultiPoint{{1, 2}, {3, 4}}, 4326)
+	if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", multiPoint, got, err, want)
+	}
+	if b, err := MBR(data); err != nil || !reflect.DeepEqual(b, geom.Bounds{Min: geom.Point{1, 2}, Max: geom.Point{3, 4}}) {
+		t.Errorf("MBR(%s) == %#v, %v", multiPoint, b, err)
+	}
+
+	var testCases = []struct {
+		g    geom.T
+		axes uint32
+	}{
+		{geom.Point{1, 2}, geom.TwoD},
+		{geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.Z},
+		{geom.Polygon{{{0, 0, 1}, {1, 0, 2}, {1, 1, 3}, {0, 0, 1}}, {}}, geom.M},
+		{geom.MultiLineString{{{1, 2, 3, 4}, {5, 6, 7, 8}}, {}}, geom.ZM},
+		{geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, geom.TwoD},
+		{geom.GeometryCollection{geom.Point{1, 2}, geom.MultiPoint{{3, 4}}, geom.GeometryCollection{}}, geom.TwoD},
+		{geom.NewSRIDGeometry(geom.LineString{{1, 2}, {3, 4}}, 3857), geom.TwoD},
+	}
+	// SRIDs of members are written in the header, and Layouts are dropped.
+	wrapped := geom.GeometryCollection{geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM), 4326)}
+	want = geom.NewSRIDGeometry(geom.GeometryCollection{geom.Point{1, 2, 3}}, 4326)
+	if data, err := Encode(wrapped, binary.LittleEndian, geom.M); err != nil {
+		t.Errorf("Encode(%#v) == %v", wrapped, err)
+	} else if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode(Encode(%#v)) == %#v, %v, want %#v, nil", wrapped, got, err, want)
+	}
+
+	empty, err := Encode(geom.Point{}, binary.LittleEndian, geom.TwoD)
+	if err != nil {
+		t.Errorf("Encode(geom.Point{}) == %v", err)
+	} else if b, err := MBR(empty); err != nil || !reflect.DeepEqual(b, geom.Bounds{Min: geom.Point{0, 0}, Max: geom.Point{0, 0}}) {
+		t.Errorf("MBR(Encode(geom.Point{})) == %#v, %v", b, err)
+	}
+
+	for _, tc := range testCases {
+		for _, byteOrder := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			data, err := Encode(tc.g, byteOrder, tc.axes)
+			if err != nil {
+				t.Errorf("Encode(%#v, %v, %d) == %v", tc.g, byteOrder, tc.axes, err)
+				continue
+			}
+			if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Decode(Encode(%#v, %v, %d)) == %#v, %v", tc.g, byteOrder, tc.axes, got, err)
+			}
+		}
+	}
+}
+
+func TestSpatiaLiteError(t *testing.T) {
+	if _, err := Encode(geom.Point{1, 2}, binary.LittleEndian, geom.Z); !reflect.DeepEqual(err, DimensionError{Dimension: 3, ElementCount: 2}) {
+		t.Errorf("Encode(Point{1, 2}, Z) == %#v", err)
+	}
+	if _, err := Encode(geom.Point{1, 2}, binary.LittleEndian, 4); !reflect.DeepEqual(err, UnsupportedAxesError{4}) {
+		t.Errorf("Encode(Point{1, 2}, 4) == %#v", err)
+	}
+	measured := geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)
+	if _, err := Encode(measured, binary.LittleEndian, geom.Z); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XYZ}) {
+		t.Errorf("Encode(%#v, Z) == %#v", measured, err)
+	}
+	mixed := geom.GeometryCollection{geom.NewSRIDGeometry(geom.Point{1, 2}, 4326), geom.NewSRIDGeometry(geom.Point{3, 4}, 3857)}
+	if _, err := Encode(mixed, binary.LittleEndian, geom.TwoD); !reflect.DeepEqual(err, geom.SRIDError{SRID: 4326, Other: 3857}) {
+		t.Errorf("Encode(%#v) == %#v", mixed, err)
+	}
+
+	data, _ := hex.DecodeString(pointBlob)
+	if _, err := Decode(data[:10]); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode(short) == %#v", err)
+	}
+	corrupt := append([]byte(nil), data...)
+	corrupt[38] = 0
+	if _, err := Decode(corrupt); !reflect.DeepEqual(err, FormatError{"missing marker"}) {
+		t.Errorf("Decode(no MBR end) == %#v", err)
+	}
+	corrupt = append([]byte(nil), data...)
+	corrupt[39] = 9
+	if _, err := Decode(corrupt); !reflect.DeepEqual(err, UnsupportedClassError{9}) {
+		t.Errorf("Decode(class 9) == %#v", err)
+	}
+	corrupt = append(append([]byte(nil), data[:len(data)-1]...), 0, 0xfe)
+	if _, err := Decode(corrupt); !reflect.DeepEqual(err, FormatError{"unexpected data after geometry"}) {
+		t.Errorf("Decode(trailing) == %#v", err)
+	}
+	for i := headerLength; i < len(data)-1; i++ {
+		short := append(append([]byte(nil), data[:i]...), 0xfe)
+		if _, err := Decode(short); err == nil {
+			t.Errorf("Decode(truncated at %d) succeeded", i)
+		}
+	}
+}
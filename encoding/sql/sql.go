@@ -0,0 +1,168 @@
+// Package sql lets geometries be scanned from and written to database
+// columns, implementing sql.Scanner and driver.Valuer.
+//
+//	var g sql.Geometry
+//	err := db.QueryRow("SELECT geom FROM parcels WHERE id = $1", id).Scan(&g)
+//	...
+//	_, err = db.Exec("UPDATE parcels SET geom = $1 WHERE id = $2", g, id)
+package sql
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/foobaz/geom"
+	"github.com/foobaz/geom/encoding/hex"
+	"github.com/foobaz/geom/encoding/wkb"
+)
+
+// Format selects how a Geometry is encoded in a column.
+type Format int
+
+const (
+	// HexEWKB is PostGIS extended WKB as a hexadecimal string, the text
+	// form of a PostGIS geometry. It is the zero value.
+	HexEWKB Format = iota
+	// EWKB is PostGIS extended WKB as raw bytes, the binary form of a
+	// PostGIS geometry.
+	EWKB
+	// MySQL is MySQL's internal geometry format: a little-endian 4-byte
+	// SRID followed by WKB. MySQL only supports geom.TwoD.
+	MySQL
+)
+
+// Geometry wraps a geometry for use as a query argument or scan target. A
+// nil T is NULL.
+//
+// Scanning a HexEWKB or EWKB Geometry accepts either form, as a string or
+// []byte, and returns a geom.SRIDGeometry if the value has an SRID.
+// Scanning a MySQL Geometry returns a geom.SRIDGeometry unless the SRID is
+// zero. Values are written in the little-endian byte order both databases
+// use, with the SRID of a geom.SRIDGeometry.
+type Geometry struct {
+	geom.T
+	Format Format
+	// Axes must be geom.TwoD, geom.Z, geom.M, or geom.ZM. Scan sets it to
+	// the axes of the value read, so that writing a scanned Geometry keeps
+	// its Z and M.
+	Axes uint32
+}
+
+type UnsupportedSourceError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedSourceError) Error() string {
+	return "sql: cannot scan geometry from " + fmt.Sprint(e.Type)
+}
+
+type UnsupportedFormatError struct {
+	Format Format
+}
+
+func (e UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("sql: unsupported format %d", e.Format)
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("sql: unsupported axes %d", e.Axes)
+}
+
+// Scan implements sql.Scanner.
+func (g *Geometry) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		g.T = nil
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return UnsupportedSourceError{reflect.TypeOf(src)}
+	}
+
+	var t geom.T
+	var err error
+	switch g.Format {
+	case HexEWKB, EWKB:
+		t, err = decodeEWKB(data)
+	case MySQL:
+		t, err = decodeMySQL(data)
+	default:
+		err = UnsupportedFormatError{g.Format}
+	}
+	if err != nil {
+		return err
+	}
+	layout, err := geom.LayoutOf(t)
+	if err != nil {
+		return err
+	}
+	g.T, g.Axes = t, uint32(layout)
+	return nil
+}
+
+// decodeEWKB decodes raw or hex EWKB. Raw WKB starts with a byte order
+// marker of 0 or 1, which is never a hex digit.
+func decodeEWKB(data []byte) (geom.T, error) {
+	if len(data) != 0 && data[0] > 1 {
+		return hex.Decode(string(data))
+	}
+	return wkb.Decode(data)
+}
+
+func decodeMySQL(data []byte) (geom.T, error) {
+	if len(data) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	srid := binary.LittleEndian.Uint32(data)
+	t, err := wkb.Decode(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	if srid != 0 {
+		t = geom.NewSRIDGeometry(t, srid)
+	}
+	return t, nil
+}
+
+// Value implements driver.Valuer. HexEWKB values are strings; EWKB and
+// MySQL values are []byte. Writing a MySQL value with Axes other than
+// geom.TwoD returns an UnsupportedAxesError.
+func (g Geometry) Value() (driver.Value, error) {
+	if g.T == nil {
+		return nil, nil
+	}
+
+	switch g.Format {
+	case HexEWKB:
+		return hex.EncodeEWKB(g.T, wkb.NDR, g.Axes)
+	case EWKB:
+		return wkb.EncodeEWKB(g.T, wkb.NDR, g.Axes)
+	case MySQL:
+		if g.Axes != geom.TwoD {
+			return nil, UnsupportedAxesError{g.Axes}
+		}
+		t, srid := g.T, uint32(0)
+		if s, ok := t.(geom.SRIDGeometry); ok {
+			t, srid = s.T, s.SRID
+		}
+		data, err := wkb.Encode(t, wkb.NDR, g.Axes)
+		if err != nil {
+			return nil, err
+		}
+		dst := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(data)), srid)
+		return append(dst, data...), nil
+	default:
+		return nil, UnsupportedFormatError{g.Format}
+	}
+}
@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+// The stub driver returns stubValue from every query and records the
+// arguments of every statement in stubArgs.
+var (
+	stubValue driver.Value
+	stubArgs  []driver.Value
+)
+
+type stubDriver struct{}
+type stubConn struct{}
+type stubStmt struct{}
+type stubRows struct{ done bool }
+
+func (stubDriver) Open(string) (driver.Conn, error)  { return stubConn{}, nil }
+func (stubConn) Prepare(string) (driver.Stmt, error) { return stubStmt{}, nil }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (stubStmt) Close() error                        { return nil }
+func (stubStmt) NumInput() int                       { return -1 }
+func (stubRows) Columns() []string                   { return []string{"geom"} }
+func (*stubRows) Close() error                       { return nil }
+func (stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	stubArgs = args
+	return driver.RowsAffected(1), nil
+}
+func (stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	stubArgs = args
+	return &stubRows{}, nil
+}
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = stubValue
+	return nil
+}
+
+func init() {
+	sql.Register("geomstub", stubDriver{})
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+const (
+	pointWKB  = "0101000000000000000000f03f0000000000000040"
+	pointEWKB = "0101000020e6100000000000000000f03f0000000000000040"
+	pointXDR  = "00000000013ff00000000000004000000000000000"
+	// PostGIS PointZ and PointM with SRID 4326 and coordinates 1, 2, 3
+	pointZEWKB = "01010000a0e6100000000000000000f03f00000000000000400000000000000840"
+	pointMEWKB = "0101000060e6100000000000000000f03f00000000000000400000000000000840"
+)
+
+func TestGeometry(t *testing.T) {
+	db, err := sql.Open("geomstub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	point := geom.Point{1, 2}
+	withSRID := geom.NewSRIDGeometry(point, 4326)
+	var testCases = []struct {
+		column driver.Value
+		format Format
+		want   geom.T
+		value  driver.Value
+	}{
+		{pointEWKB, HexEWKB, withSRID, pointEWKB},
+		{[]byte(pointEWKB), HexEWKB, withSRID, pointEWKB},
+		{mustHex(pointEWKB), EWKB, withSRID, mustHex(pointEWKB)},
+		{mustHex(pointWKB), EWKB, point, mustHex(pointWKB)},
+		{mustHex(pointXDR), HexEWKB, point, pointWKB},
+		{mustHex("e6100000" + pointWKB), MySQL, withSRID, mustHex("e6100000" + pointWKB)},
+		{mustHex("00000000" + pointWKB), MySQL, point, mustHex("00000000" + pointWKB)},
+		{pointZEWKB, HexEWKB, geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326), pointZEWKB},
+		{pointMEWKB, HexEWKB, geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM), 4326), pointMEWKB},
+		{mustHex(pointZEWKB), EWKB, geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326), mustHex(pointZEWKB)},
+		{nil, EWKB, nil, nil},
+	}
+	for _, tc := range testCases {
+		stubValue = tc.column
+		g := Geometry{Format: tc.format}
+		if err := db.QueryRow("SELECT geom").Scan(&g); err != nil || !reflect.DeepEqual(g.T, tc.want) {
+			t.Errorf("Scan(%#v) with format %d == %#v, %v, want %#v, nil", tc.column, tc.format, g.T, err, tc.want)
+			continue
+		}
+
+		if _, err := db.Exec("INSERT geom", g); err != nil || len(stubArgs) != 1 || !reflect.DeepEqual(stubArgs[0], tc.value) {
+			t.Errorf("Exec(%#v) passed %#v, %v, want %#v", g, stubArgs, err, tc.value)
+		}
+	}
+}
+
+func TestGeometryError(t *testing.T) {
+	var g Geometry
+	if err := g.Scan(1); !reflect.DeepEqual(err, UnsupportedSourceError{reflect.TypeOf(1)}) {
+		t.Errorf("Scan(1) == %#v", err)
+	}
+	if err := g.Scan("zz"); err == nil {
+		t.Errorf("Scan(\"zz\") succeeded")
+	}
+	g.Format = MySQL
+	if err := g.Scan([]byte{1, 2}); err != io.ErrUnexpectedEOF {
+		t.Errorf("Scan(short MySQL) == %#v", err)
+	}
+	g = Geometry{T: geom.Point{1, 2}, Format: 7}
+	if _, err := g.Value(); !reflect.DeepEqual(err, UnsupportedFormatError{7}) {
+		t.Errorf("Value() with format 7 == _, %#v", err)
+	}
+	if err := g.Scan([]byte{}); !reflect.DeepEqual(err, UnsupportedFormatError{7}) {
+		t.Errorf("Scan with format 7 == %#v", err)
+	}
+	g = Geometry{T: geom.Point{1, 2, 3}, Format: MySQL, Axes: geom.Z}
+	if _, err := g.Value(); !reflect.DeepEqual(err, UnsupportedAxesError{geom.Z}) {
+		t.Errorf("Value() with MySQL and Z == _, %#v", err)
+	}
+}
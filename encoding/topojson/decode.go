@@ -0,0 +1,261 @@
+package topojson
+
+import (
+	"encoding/json"
+
+	"github.com/foobaz/geom"
+)
+
+type decoder struct {
+	transform *Transform
+	arcs      [][]geom.Point
+}
+
+func (d *decoder) point(v interface{}, delta geom.Point) (geom.Point, error) {
+	c, ok := v.([]interface{})
+	if !ok || len(c) < 2 {
+		return nil, FormatError{"invalid position"}
+	}
+	p := make(geom.Point, 2)
+	for axis := range p {
+		f, ok := c[axis].(float64)
+		if !ok {
+			return nil, FormatError{"invalid position"}
+		}
+		p[axis] = f
+		if delta != nil {
+			p[axis] += delta[axis]
+		}
+	}
+	return p, nil
+}
+
+func (d *decoder) untransform(p geom.Point) geom.Point {
+	if d.transform == nil {
+		return p
+	}
+	return geom.Point{
+		p[geom.X]*d.transform.Scale[0] + d.transform.Translate[0],
+		p[geom.Y]*d.transform.Scale[1] + d.transform.Translate[1],
+	}
+}
+
+func (d *decoder) decodeArcs(arcs [][][]float64) {
+	d.arcs = make([][]geom.Point, len(arcs))
+	for i, arc := range arcs {
+		ps := make([]geom.Point, 0, len(arc))
+		var x, y float64
+		for _, c := range arc {
+			if len(c) < 2 {
+				continue
+			}
+			if d.transform != nil {
+				x, y = x+c[0], y+c[1]
+			} else {
+				x, y = c[0], c[1]
+			}
+			ps = append(ps, d.untransform(geom.Point{x, y}))
+		}
+		d.arcs[i] = ps
+	}
+}
+
+func ints(v interface{}) ([]int, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, FormatError{"invalid arc indexes"}
+	}
+	out := make([]int, len(list))
+	for i, e := range list {
+		f, ok := e.(float64)
+		if !ok || f != float64(int(f)) {
+			return nil, FormatError{"invalid arc indexes"}
+		}
+		out[i] = int(f)
+	}
+	return out, nil
+}
+
+func list(v interface{}) ([]interface{}, error) {
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, FormatError{"invalid arc indexes"}
+	}
+	return l, nil
+}
+
+// line joins the arcs with the given indexes. Each arc after the first
+// starts where the last one ended, so its first point is dropped.
+func (d *decoder) line(v interface{}) ([]geom.Point, error) {
+	indexes, err := ints(v)
+	if err != nil {
+		return nil, err
+	}
+	var ps []geom.Point
+	for _, index := range indexes {
+		i := index
+		if i < 0 {
+			i = ^i
+		}
+		if i >= len(d.arcs) {
+			return nil, InvalidArcError{index}
+		}
+		arc := d.arcs[i]
+		for j := range arc {
+			k := j
+			if index < 0 {
+				k = len(arc) - 1 - j
+			}
+			if j == 0 && len(ps) != 0 {
+				continue
+			}
+			ps = append(ps, arc[k])
+		}
+	}
+	return ps, nil
+}
+
+func (d *decoder) lines(v interface{}) ([][]geom.Point, error) {
+	l, err := list(v)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]geom.Point, len(l))
+	for i, e := range l {
+		if out[i], err = d.line(e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (d *decoder) polygon(v interface{}) (geom.Polygon, error) {
+	rings, err := d.lines(v)
+	if err != nil {
+		return nil, err
+	}
+	polygon := make(geom.Polygon, len(rings))
+	for i, ring := range rings {
+		polygon[i] = ring
+	}
+	return polygon, nil
+}
+
+func (d *decoder) geometry(g Geometry) (geom.T, error) {
+	switch g.Type {
+	case "":
+		return nil, nil
+	case "Point":
+		p, err := d.point(g.Coordinates, nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.untransform(p), nil
+	case "MultiPoint":
+		l, err := list(g.Coordinates)
+		if err != nil {
+			return nil, FormatError{"invalid position"}
+		}
+		mp := make(geom.MultiPoint, len(l))
+		for i, c := range l {
+			p, err := d.point(c, nil)
+			if err != nil {
+				return nil, err
+			}
+			mp[i] = d.untransform(p)
+		}
+		return mp, nil
+	case "LineString":
+		l, err := d.line(g.Arcs)
+		return geom.LineString(l), err
+	case "MultiLineString":
+		ls, err := d.lines(g.Arcs)
+		if err != nil {
+			return nil, err
+		}
+		mls := make(geom.MultiLineString, len(ls))
+		for i, l := range ls {
+			mls[i] = l
+		}
+		return mls, nil
+	case "Polygon":
+		return d.polygon(g.Arcs)
+	case "MultiPolygon":
+		l, err := list(g.Arcs)
+		if err != nil {
+			return nil, err
+		}
+		mp := make(geom.MultiPolygon, len(l))
+		for i, e := range l {
+			if mp[i], err = d.polygon(e); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	case "GeometryCollection":
+		gc := make(geom.GeometryCollection, len(g.Geometries))
+		for i, member := range g.Geometries {
+			t, err := d.feature(member)
+			if err != nil {
+				return nil, err
+			}
+			gc[i] = t
+		}
+		return gc, nil
+	}
+	return nil, UnsupportedGeometryError{g.Type}
+}
+
+// feature decodes g, wrapping it in a geom.Feature if it has properties or
+// an ID.
+func (d *decoder) feature(g Geometry) (geom.T, error) {
+	t, err := d.geometry(g)
+	if err != nil {
+		return nil, err
+	}
+	if g.Properties == nil && g.ID == nil {
+		return t, nil
+	}
+	f := geom.NewFeature(t, g.Properties)
+	f.ID = g.ID
+	return f, nil
+}
+
+// FromTopology decodes each object of a topology into a feature
+// collection. The members of an object that is a GeometryCollection become
+// features; any other object becomes a collection of one feature.
+func FromTopology(topology Topology) (map[string]geom.FeatureCollection, error) {
+	if topology.Type != "Topology" {
+		return nil, FormatError{"type is " + topology.Type + ", not Topology"}
+	}
+	d := decoder{transform: topology.Transform}
+	d.decodeArcs(topology.Arcs)
+
+	collections := make(map[string]geom.FeatureCollection, len(topology.Objects))
+	for name, object := range topology.Objects {
+		members := []Geometry{object}
+		if object.Type == "GeometryCollection" {
+			members = object.Geometries
+		}
+		fc := geom.FeatureCollection{Features: make([]geom.T, len(members))}
+		for i, member := range members {
+			t, err := d.geometry(member)
+			if err != nil {
+				return nil, err
+			}
+			f := geom.NewFeature(t, member.Properties)
+			f.ID = member.ID
+			fc.Features[i] = f
+		}
+		collections[name] = fc
+	}
+	return collections, nil
+}
+
+func Decode(data []byte) (map[string]geom.FeatureCollection, error) {
+	var topology Topology
+	if err := json.Unmarshal(data, &topology); err != nil {
+		return nil, err
+	}
+	return FromTopology(topology)
+}
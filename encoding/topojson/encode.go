@@ -0,0 +1,386 @@
+package topojson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+// position is a point in the topology, quantized or not.
+type position [2]float64
+
+// pending is a geometry whose lines have been collected but not yet cut
+// into arcs. Lines holds line indexes nested like the eventual arc
+// indexes, and Coordinates the points of a Point or MultiPoint.
+type pending struct {
+	typ         string
+	id          interface{}
+	properties  interface{}
+	lines       interface{}
+	coordinates interface{}
+	members     []pending
+}
+
+type builder struct {
+	bounds    geom.Bounds
+	lines     [][]geom.Point
+	rings     []bool
+	quantized bool
+	translate position
+	scale     position
+
+	arcs      [][]position
+	arcIndex  map[[2]position][]int
+	lineArcs  [][]int
+	junctions map[position]bool
+}
+
+func (b *builder) validate(ps []geom.Point) error {
+	for _, p := range ps {
+		if len(p) < 2 {
+			return InsufficientElementsError{len(p)}
+		}
+	}
+	b.bounds = b.bounds.ExtendPoints(ps)
+	return nil
+}
+
+func (b *builder) line(ps []geom.Point, ring bool) (int, error) {
+	if err := b.validate(ps); err != nil {
+		return 0, err
+	}
+	b.lines = append(b.lines, ps)
+	b.rings = append(b.rings, ring)
+	return len(b.lines) - 1, nil
+}
+
+func (b *builder) collect(t geom.T) (pending, error) {
+	var p pending
+	var err error
+	switch g := t.(type) {
+	case geom.Point:
+		p.typ, p.coordinates, err = "Point", g, b.validate([]geom.Point{g})
+	case geom.MultiPoint:
+		p.typ, p.coordinates, err = "MultiPoint", []geom.Point(g), b.validate(g)
+	case geom.LineString:
+		p.typ = "LineString"
+		p.lines, err = b.line(g, false)
+	case geom.MultiLineString:
+		p.typ = "MultiLineString"
+		lines := make([]int, len(g))
+		for i := 0; i < len(g) && err == nil; i++ {
+			lines[i], err = b.line(g[i], false)
+		}
+		p.lines = lines
+	case geom.Polygon:
+		p.typ = "Polygon"
+		p.lines, err = b.polygon(g)
+	case geom.MultiPolygon:
+		p.typ = "MultiPolygon"
+		lines := make([][]int, len(g))
+		for i := 0; i < len(g) && err == nil; i++ {
+			lines[i], err = b.polygon(g[i])
+		}
+		p.lines = lines
+	case geom.GeometryCollection:
+		p.typ = "GeometryCollection"
+		p.members = make([]pending, len(g))
+		for i := 0; i < len(g) && err == nil; i++ {
+			p.members[i], err = b.collect(g[i])
+		}
+	case geom.SRIDGeometry, geom.LayoutGeometry:
+		return b.collect(geom.Unwrap(g))
+	case nil:
+		return p, UnsupportedGeometryError{"null"}
+	default:
+		return p, UnsupportedGeometryError{reflect.TypeOf(t).String()}
+	}
+	return p, err
+}
+
+func (b *builder) polygon(polygon geom.Polygon) ([]int, error) {
+	rings := make([]int, len(polygon))
+	for i, ring := range polygon {
+		var err error
+		if rings[i], err = b.line(ring, true); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+func (b *builder) position(p geom.Point) position {
+	if !b.quantized {
+		return position{p[geom.X], p[geom.Y]}
+	}
+	return position{
+		math.Round((p[geom.X] - b.translate[0]) / b.scale[0]),
+		math.Round((p[geom.Y] - b.translate[1]) / b.scale[1]),
+	}
+}
+
+// positions converts a line to positions, dropping points that repeat
+// once quantized. A ring's closing point is dropped too.
+func (b *builder) positions(ps []geom.Point, ring bool) []position {
+	out := make([]position, 0, len(ps))
+	for _, p := range ps {
+		q := b.position(p)
+		if len(out) == 0 || out[len(out)-1] != q {
+			out = append(out, q)
+		}
+	}
+	if ring && len(out) > 1 && out[0] == out[len(out)-1] {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// findJunctions marks the positions where lines end, or where they meet
+// with different neighbours on either side.
+func (b *builder) findJunctions(lines [][]position) {
+	type neighbours struct {
+		prev, next position
+	}
+	visited := map[position]neighbours{}
+	b.junctions = map[position]bool{}
+	visit := func(p, prev, next position) {
+		n, ok := visited[p]
+		if !ok {
+			visited[p] = neighbours{prev, next}
+		} else if !(n.prev == prev && n.next == next) && !(n.prev == next && n.next == prev) {
+			b.junctions[p] = true
+		}
+	}
+
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		if b.rings[i] {
+			for j, p := range line {
+				visit(p, line[(j+len(line)-1)%len(line)], line[(j+1)%len(line)])
+			}
+			continue
+		}
+		b.junctions[line[0]] = true
+		b.junctions[line[len(line)-1]] = true
+		for j := 1; j+1 < len(line); j++ {
+			visit(line[j], line[j-1], line[j+1])
+		}
+	}
+}
+
+// arc returns the index of the arc through ps, adding it if no arc
+// already runs through the same positions in either direction. A reversed
+// arc has index ^i.
+func (b *builder) arc(ps []position) int {
+	first, last := ps[0], ps[len(ps)-1]
+	for _, i := range b.arcIndex[[2]position{first, last}] {
+		if equal(b.arcs[i], ps, false) {
+			return i
+		}
+	}
+	for _, i := range b.arcIndex[[2]position{last, first}] {
+		if equal(b.arcs[i], ps, true) {
+			return ^i
+		}
+	}
+	i := len(b.arcs)
+	b.arcs = append(b.arcs, ps)
+	b.arcIndex[[2]position{first, last}] = append(b.arcIndex[[2]position{first, last}], i)
+	return i
+}
+
+func equal(a, b []position, reverse bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		j := i
+		if reverse {
+			j = len(b) - 1 - i
+		}
+		if a[i] != b[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func less(a, b position) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+// cut splits a line at junctions and returns the indexes of its arcs.
+func (b *builder) cut(line []position, ring bool) []int {
+	if len(line) == 0 {
+		return []int{}
+	}
+	if ring {
+		// Start the ring at a junction, or failing that at its least
+		// position so that equal rings are found whatever their start.
+		start := -1
+		for i, p := range line {
+			if b.junctions[p] {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			start = 0
+			for i, p := range line {
+				if less(p, line[start]) {
+					start = i
+				}
+			}
+		}
+		rotated := make([]position, 0, len(line)+1)
+		rotated = append(rotated, line[start:]...)
+		rotated = append(rotated, line[:start]...)
+		line = append(rotated, line[start])
+	}
+
+	var arcs []int
+	begin := 0
+	for i := 1; i < len(line); i++ {
+		if i == len(line)-1 || b.junctions[line[i]] {
+			arcs = append(arcs, b.arc(line[begin:i+1]))
+			begin = i
+		}
+	}
+	if arcs == nil {
+		// A line of a single point.
+		arcs = append(arcs, b.arc(line))
+	}
+	return arcs
+}
+
+func (b *builder) topology() {
+	lines := make([][]position, len(b.lines))
+	for i, line := range b.lines {
+		lines[i] = b.positions(line, b.rings[i])
+	}
+	b.findJunctions(lines)
+	b.arcIndex = map[[2]position][]int{}
+	b.lineArcs = make([][]int, len(lines))
+	for i, line := range lines {
+		b.lineArcs[i] = b.cut(line, b.rings[i])
+	}
+}
+
+func (b *builder) coordinates(p geom.Point) []float64 {
+	q := b.position(p)
+	return []float64{q[0], q[1]}
+}
+
+func (b *builder) geometry(p pending) Geometry {
+	g := Geometry{Type: p.typ, ID: p.id, Properties: p.properties}
+	switch lines := p.lines.(type) {
+	case int:
+		g.Arcs = b.lineArcs[lines]
+	case []int:
+		arcs := make([][]int, len(lines))
+		for i, line := range lines {
+			arcs[i] = b.lineArcs[line]
+		}
+		g.Arcs = arcs
+	case [][]int:
+		arcs := make([][][]int, len(lines))
+		for i, polygon := range lines {
+			arcs[i] = make([][]int, len(polygon))
+			for j, line := range polygon {
+				arcs[i][j] = b.lineArcs[line]
+			}
+		}
+		g.Arcs = arcs
+	}
+	switch c := p.coordinates.(type) {
+	case geom.Point:
+		g.Coordinates = b.coordinates(c)
+	case []geom.Point:
+		coordinates := make([][]float64, len(c))
+		for i, point := range c {
+			coordinates[i] = b.coordinates(point)
+		}
+		g.Coordinates = coordinates
+	}
+	if p.members != nil {
+		g.Geometries = make([]Geometry, len(p.members))
+		for i, member := range p.members {
+			g.Geometries[i] = b.geometry(member)
+		}
+	}
+	return g
+}
+
+// ToTopology builds a topology with one object, a GeometryCollection
+// holding the features of fc. Feature properties and IDs are kept, and
+// SRIDs and Layouts are dropped.
+func ToTopology(fc geom.FeatureCollection, options Options) (Topology, error) {
+	if options.Quantization < 0 || options.Quantization == 1 {
+		return Topology{}, InvalidQuantizationError{options.Quantization}
+	}
+	b := builder{bounds: geom.NewBounds()}
+	members := make([]pending, len(fc.Features))
+	for i, t := range fc.Features {
+		var err error
+		if f, ok := t.(geom.Feature); ok {
+			members[i], err = b.collect(f.T)
+			members[i].id, members[i].properties = f.ID, f.Properties
+		} else {
+			members[i], err = b.collect(t)
+		}
+		if err != nil {
+			return Topology{}, err
+		}
+	}
+
+	topology := Topology{Type: "Topology", Arcs: [][][]float64{}}
+	if !b.bounds.Empty() {
+		topology.BBox = []float64{b.bounds.Min[geom.X], b.bounds.Min[geom.Y], b.bounds.Max[geom.X], b.bounds.Max[geom.Y]}
+		if options.Quantization != 0 {
+			b.quantized = true
+			b.translate = position{b.bounds.Min[geom.X], b.bounds.Min[geom.Y]}
+			for axis := range b.scale {
+				b.scale[axis] = (b.bounds.Max[axis] - b.bounds.Min[axis]) / float64(options.Quantization-1)
+				if b.scale[axis] == 0 {
+					b.scale[axis] = 1
+				}
+			}
+			topology.Transform = &Transform{Scale: b.scale, Translate: b.translate}
+		}
+	}
+	b.topology()
+
+	for _, arc := range b.arcs {
+		coordinates := make([][]float64, len(arc))
+		var prev position
+		for i, p := range arc {
+			if b.quantized {
+				coordinates[i] = []float64{p[0] - prev[0], p[1] - prev[1]}
+				prev = p
+			} else {
+				coordinates[i] = []float64{p[0], p[1]}
+			}
+		}
+		topology.Arcs = append(topology.Arcs, coordinates)
+	}
+
+	name := options.Name
+	if name == "" {
+		name = DefaultObjectName
+	}
+	collection := b.geometry(pending{typ: "GeometryCollection", members: members})
+	topology.Objects = map[string]Geometry{name: collection}
+	return topology, nil
+}
+
+func Encode(fc geom.FeatureCollection, options Options) ([]byte, error) {
+	topology, err := ToTopology(fc, options)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(topology)
+}
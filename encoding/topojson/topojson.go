@@ -0,0 +1,97 @@
+// Package topojson encodes and decodes TopoJSON topologies.
+//
+// Encoding finds the boundaries that geometries share and stores each
+// once, as an arc that every geometry using it refers to. Shared arcs are
+// found by junction detection: a point is a junction where lines meet,
+// diverge or end, and lines are cut into arcs at junctions. Coordinates
+// can be quantized to a grid, in which case arcs are delta encoded.
+//
+// Only the X and Y components of each point are encoded.
+package topojson
+
+import (
+	"fmt"
+)
+
+// DefaultObjectName names the object that holds the encoded features when
+// Options.Name is empty.
+const DefaultObjectName = "collection"
+
+type Topology struct {
+	Type      string              `json:"type"`
+	BBox      []float64           `json:"bbox,omitempty"`
+	Transform *Transform          `json:"transform,omitempty"`
+	Objects   map[string]Geometry `json:"objects"`
+	Arcs      [][][]float64       `json:"arcs"`
+}
+
+// A Transform maps quantized positions back to coordinates.
+type Transform struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// Arcs holds arc indexes, nested as deeply as the coordinates of the
+// equivalent GeoJSON geometry would be less one. Coordinates holds the
+// positions of a Point or MultiPoint and Geometries the members of a
+// GeometryCollection. When decoding, Arcs and Coordinates hold decoded
+// JSON.
+type Geometry struct {
+	Type        string      `json:"type"`
+	ID          interface{} `json:"id,omitempty"`
+	Properties  interface{} `json:"properties,omitempty"`
+	Arcs        interface{} `json:"arcs,omitempty"`
+	Coordinates interface{} `json:"coordinates,omitempty"`
+	Geometries  []Geometry  `json:"geometries,omitempty"`
+}
+
+type Options struct {
+	// Name is the name of the object holding the features.
+	Name string
+	// Quantization is the number of positions along each axis of the
+	// grid that coordinates are rounded to. Zero leaves coordinates
+	// unquantized.
+	Quantization int
+}
+
+type UnsupportedGeometryError struct {
+	Type string
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "topojson: unsupported geometry type " + e.Type
+}
+
+type InsufficientElementsError struct {
+	ElementCount int
+}
+
+func (e InsufficientElementsError) Error() string {
+	return fmt.Sprintf("topojson: need at least two elements in point, got %d", e.ElementCount)
+}
+
+type InvalidQuantizationError struct {
+	Quantization int
+}
+
+func (e InvalidQuantizationError) Error() string {
+	return fmt.Sprintf("topojson: invalid quantization %d", e.Quantization)
+}
+
+// InvalidArcError reports a reference to an arc that does not exist.
+type InvalidArcError struct {
+	Index int
+}
+
+func (e InvalidArcError) Error() string {
+	return fmt.Sprintf("topojson: invalid arc index %d", e.Index)
+}
+
+// FormatError reports malformed input.
+type FormatError struct {
+	Msg string
+}
+
+func (e FormatError) Error() string {
+	return "topojson: " + e.Msg
+}
@@ -0,0 +1,141 @@
+package topojson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestTopoJSON(t *testing.T) {
+	a := geom.NewFeature(geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}, map[string]interface{}{"name": "A"})
+	a.ID = "a"
+	b := geom.Polygon{{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}}
+	fc := geom.FeatureCollection{Features: []geom.T{a, b}}
+
+	// Ring A starts at its first junction when decoded.
+	decodedA := geom.NewFeature(geom.Polygon{{{1, 0}, {1, 1}, {0, 1}, {0, 0}, {1, 0}}}, map[string]interface{}{"name": "A"})
+	decodedA.ID = "a"
+	want := map[string]geom.FeatureCollection{
+		"collection": {Features: []geom.T{decodedA, geom.NewFeature(b, nil)}},
+	}
+
+	var testCases = []struct {
+		quantization int
+		json         string
+	}{
+		{
+			0,
+			`{"type":"Topology","bbox":[0,0,2,1],"objects":{"collection":{"type":"GeometryCollection","geometries":[` +
+				`{"type":"Polygon","id":"a","properties":{"name":"A"},"arcs":[[0,1]]},{"type":"Polygon","arcs":[[2,-1]]}]}},` +
+				`"arcs":[[[1,0],[1,1]],[[1,1],[0,1],[0,0],[1,0]],[[1,0],[2,0],[2,1],[1,1]]]}`,
+		},
+		{
+			3,
+			`{"type":"Topology","bbox":[0,0,2,1],"transform":{"scale":[1,0.5],"translate":[0,0]},` +
+				`"objects":{"collection":{"type":"GeometryCollection","geometries":[` +
+				`{"type":"Polygon","id":"a","properties":{"name":"A"},"arcs":[[0,1]]},{"type":"Polygon","arcs":[[2,-1]]}]}},` +
+				`"arcs":[[[1,0],[0,2]],[[1,2],[-1,0],[0,-2],[1,0]],[[1,0],[1,0],[0,2],[-1,0]]]}`,
+		},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(fc, Options{Quantization: tc.quantization})
+		if err != nil || string(data) != tc.json {
+			t.Errorf("Encode(fc, %d) == %s, %v, want %s, nil", tc.quantization, data, err, tc.json)
+		}
+		if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", data, got, err, want)
+		}
+	}
+
+	// SRIDs and Layouts are dropped.
+	wrapped := geom.FeatureCollection{Features: []geom.T{a, geom.NewSRIDGeometry(geom.NewLayoutGeometry(b, geom.XY), 4326)}}
+	if data, err := Encode(wrapped, Options{}); err != nil || string(data) != testCases[0].json {
+		t.Errorf("Encode(%#v) == %s, %v, want %s, nil", wrapped, data, err, testCases[0].json)
+	}
+}
+
+func TestTopoJSONSharedRing(t *testing.T) {
+	// An island in a lake: the lake's hole and the island's exterior are
+	// the same ring, with a different start and direction.
+	lake := geom.Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {2, 8}, {8, 8}, {8, 2}, {2, 2}},
+	}
+	island := geom.Polygon{{{8, 8}, {2, 8}, {2, 2}, {8, 2}, {8, 8}}}
+	topology, err := ToTopology(geom.FeatureCollection{Features: []geom.T{lake, island}}, Options{Name: "water"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topology.Arcs) != 2 {
+		t.Errorf("got %d arcs, want 2", len(topology.Arcs))
+	}
+	geometries := topology.Objects["water"].Geometries
+	if len(geometries) != 2 || !reflect.DeepEqual(geometries[1].Arcs, [][]int{{-2}}) {
+		t.Errorf("ToTopology gave geometries %#v, want island arcs [[-2]]", geometries)
+	}
+}
+
+func TestTopoJSONRoundTrip(t *testing.T) {
+	var testCases = []geom.T{
+		geom.Point{1, 2},
+		geom.MultiPoint{{1, 2}, {3, 4}},
+		geom.LineString{{0, 0}, {1, 1}, {2, 0}},
+		geom.MultiLineString{{{0, 0}, {1, 1}}, {{1, 1}, {2, 0}, {3, 3}}},
+		geom.MultiPolygon{
+			{{{4, 0}, {4, 4}, {0, 0}, {4, 0}}},
+			{{{4, 0}, {8, 0}, {4, 4}, {4, 0}}},
+		},
+		geom.GeometryCollection{geom.Point{1, 2}, geom.LineString{{0, 0}, {4, 4}}},
+	}
+	// The second feature sets the bounds so that quantization keeps
+	// integer coordinates.
+	bounds := geom.MultiPoint{{0, 0}, {8, 8}}
+	for _, g := range testCases {
+		for _, quantization := range []int{0, 9} {
+			fc := geom.FeatureCollection{Features: []geom.T{g, bounds}}
+			data, err := Encode(fc, Options{Quantization: quantization})
+			if err != nil {
+				t.Errorf("Encode(%#v) == %v", g, err)
+				continue
+			}
+			got, err := Decode(data)
+			want := geom.NewFeature(g, nil)
+			if err != nil || len(got["collection"].Features) != 2 || !reflect.DeepEqual(got["collection"].Features[0], want) {
+				t.Errorf("Decode(%s) == %#v, %v, want %#v", data, got, err, want)
+			}
+		}
+	}
+}
+
+func TestTopoJSONError(t *testing.T) {
+	fc := geom.FeatureCollection{Features: []geom.T{geom.Point{1, 2}}}
+	if _, err := Encode(fc, Options{Quantization: 1}); !reflect.DeepEqual(err, InvalidQuantizationError{1}) {
+		t.Errorf("Encode with quantization 1 == %#v", err)
+	}
+	fc = geom.FeatureCollection{Features: []geom.T{geom.NewFeature(nil, nil)}}
+	if _, err := Encode(fc, Options{}); !reflect.DeepEqual(err, UnsupportedGeometryError{"null"}) {
+		t.Errorf("Encode(null geometry) == %#v", err)
+	}
+	fc = geom.FeatureCollection{Features: []geom.T{geom.LineString{{1}, {2, 3}}}}
+	if _, err := Encode(fc, Options{}); !reflect.DeepEqual(err, InsufficientElementsError{1}) {
+		t.Errorf("Encode(LineString{{1}, {2, 3}}) == %#v", err)
+	}
+
+	var testCases = []struct {
+		json string
+		err  error
+	}{
+		{`{"type":"FeatureCollection"}`, FormatError{"type is FeatureCollection, not Topology"}},
+		{`{"type":"Topology","objects":{"a":{"type":"LineString","arcs":[1]}},"arcs":[[[0,0],[1,1]]]}`, InvalidArcError{1}},
+		{`{"type":"Topology","objects":{"a":{"type":"LineString","arcs":[-2]}},"arcs":[[[0,0],[1,1]]]}`, InvalidArcError{-2}},
+		{`{"type":"Topology","objects":{"a":{"type":"Polygon","arcs":[0]}},"arcs":[[[0,0],[1,1]]]}`, FormatError{"invalid arc indexes"}},
+		{`{"type":"Topology","objects":{"a":{"type":"Point","coordinates":[1]}},"arcs":[]}`, FormatError{"invalid position"}},
+		{`{"type":"Topology","objects":{"a":{"type":"Circle"}},"arcs":[]}`, UnsupportedGeometryError{"Circle"}},
+	}
+	for _, tc := range testCases {
+		if _, err := Decode([]byte(tc.json)); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%s) == %#v, want %#v", tc.json, err, tc.err)
+		}
+	}
+}
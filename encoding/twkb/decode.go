@@ -0,0 +1,288 @@
+package twkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/foobaz/geom"
+)
+
+type decoder struct {
+	r          io.ByteReader
+	precisions []int
+	prev       []int64
+}
+
+// unscale converts a stored integer back to a coordinate. Dividing by an
+// exact power of ten keeps values like 0.1 identical to their literals.
+func unscale(v int64, precision int) float64 {
+	if precision < 0 {
+		return float64(v) * math.Pow10(-precision)
+	}
+	return float64(v) / math.Pow10(precision)
+}
+
+func (d *decoder) readPoint() (geom.Point, error) {
+	point := make(geom.Point, len(d.prev))
+	for i := range point {
+		delta, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return nil, err
+		}
+		d.prev[i] += delta
+		point[i] = unscale(d.prev[i], d.precisions[i])
+	}
+	return point, nil
+}
+
+func (d *decoder) readCount() (int, error) {
+	count, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	if count > math.MaxInt32 {
+		return 0, fmt.Errorf("twkb: invalid count %d", count)
+	}
+	return int(count), nil
+}
+
+// capacity limits preallocation, since counts come from untrusted input.
+func capacity(count int) int {
+	if count > 1024 {
+		return 1024
+	}
+	return count
+}
+
+func (d *decoder) readPoints() ([]geom.Point, error) {
+	count, err := d.readCount()
+	if err != nil {
+		return nil, err
+	}
+	points := make([]geom.Point, 0, capacity(count))
+	for i := 0; i < count; i++ {
+		point, err := d.readPoint()
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+func (d *decoder) readPointss() (geom.Polygon, error) {
+	count, err := d.readCount()
+	if err != nil {
+		return nil, err
+	}
+	pointss := make(geom.Polygon, 0, capacity(count))
+	for i := 0; i < count; i++ {
+		points, err := d.readPoints()
+		if err != nil {
+			return nil, err
+		}
+		pointss = append(pointss, points)
+	}
+	return pointss, nil
+}
+
+func (d *decoder) readIDs(count int) ([]int64, error) {
+	ids := make([]int64, 0, capacity(count))
+	for i := 0; i < count; i++ {
+		id, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// maxDepth limits the nesting of geometry collections.
+const maxDepth = 64
+
+func read(r io.ByteReader, depth int) (geom.T, []int64, error) {
+	if depth > maxDepth {
+		return nil, nil, fmt.Errorf("twkb: geometry nested too deeply")
+	}
+	typeAndPrecision, err := r.ReadByte()
+	if err != nil {
+		return nil, nil, err
+	}
+	twkbType := typeAndPrecision & 0x0f
+	zigzag := int(typeAndPrecision >> 4)
+	precision := (zigzag >> 1) ^ -(zigzag & 1)
+
+	metadata, err := r.ReadByte()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := &decoder{r: r, precisions: []int{precision, precision}}
+	if metadata&twkbExtendedDims != 0 {
+		extended, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		if extended&0x01 != 0 {
+			d.precisions = append(d.precisions, int(extended>>2)&0x07)
+		}
+		if extended&0x02 != 0 {
+			d.precisions = append(d.precisions, int(extended>>5)&0x07)
+		}
+	}
+	d.prev = make([]int64, len(d.precisions))
+
+	if metadata&twkbSize != 0 {
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return nil, nil, err
+		}
+	}
+	if metadata&twkbBBox != 0 {
+		for i := 0; i < 2*len(d.precisions); i++ {
+			if _, err := binary.ReadVarint(r); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	empty := metadata&twkbEmpty != 0
+	switch twkbType {
+	case twkbPoint:
+		if empty {
+			return geom.Point{}, nil, nil
+		}
+		point, err := d.readPoint()
+		if err != nil {
+			return nil, nil, err
+		}
+		return point, nil, nil
+	case twkbLineString:
+		if empty {
+			return geom.LineString{}, nil, nil
+		}
+		points, err := d.readPoints()
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.LineString(points), nil, nil
+	case twkbPolygon:
+		if empty {
+			return geom.Polygon{}, nil, nil
+		}
+		pointss, err := d.readPointss()
+		if err != nil {
+			return nil, nil, err
+		}
+		return pointss, nil, nil
+	}
+
+	var count int
+	var ids []int64
+	if !empty {
+		if count, err = d.readCount(); err != nil {
+			return nil, nil, err
+		}
+		if metadata&twkbIDList != 0 {
+			if ids, err = d.readIDs(count); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	switch twkbType {
+	case twkbMultiPoint:
+		multiPoint := make(geom.MultiPoint, 0, capacity(count))
+		for i := 0; i < count; i++ {
+			point, err := d.readPoint()
+			if err != nil {
+				return nil, nil, err
+			}
+			multiPoint = append(multiPoint, point)
+		}
+		return multiPoint, ids, nil
+	case twkbMultiLineString:
+		multiLineString := make(geom.MultiLineString, 0, capacity(count))
+		for i := 0; i < count; i++ {
+			points, err := d.readPoints()
+			if err != nil {
+				return nil, nil, err
+			}
+			multiLineString = append(multiLineString, points)
+		}
+		return multiLineString, ids, nil
+	case twkbMultiPolygon:
+		multiPolygon := make(geom.MultiPolygon, 0, capacity(count))
+		for i := 0; i < count; i++ {
+			pointss, err := d.readPointss()
+			if err != nil {
+				return nil, nil, err
+			}
+			multiPolygon = append(multiPolygon, pointss)
+		}
+		return multiPolygon, ids, nil
+	case twkbGeometryCollection:
+		geometryCollection := make(geom.GeometryCollection, 0, capacity(count))
+		for i := 0; i < count; i++ {
+			g, _, err := read(r, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			geometryCollection = append(geometryCollection, g)
+		}
+		return geometryCollection, ids, nil
+	default:
+		return nil, nil, fmt.Errorf("twkb: unsupported geometry type %d", twkbType)
+	}
+}
+
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+func toByteReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return byteReader{r}
+}
+
+// Read decodes one geometry, reading no further than its end. Any ID list
+// is discarded; use ReadWithIDs to keep it.
+func Read(r io.Reader) (geom.T, error) {
+	g, _, err := read(toByteReader(r), 1)
+	return g, err
+}
+
+// ReadWithIDs also returns the ID list of a multi-geometry or geometry
+// collection, or nil if it has none.
+func ReadWithIDs(r io.Reader) (geom.T, []int64, error) {
+	return read(toByteReader(r), 1)
+}
+
+// Decode decodes a single geometry, which must fill buf.
+func Decode(buf []byte) (geom.T, error) {
+	g, _, err := DecodeWithIDs(buf)
+	return g, err
+}
+
+func DecodeWithIDs(buf []byte) (geom.T, []int64, error) {
+	r := bytes.NewReader(buf)
+	g, ids, err := read(r, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Len() != 0 {
+		return nil, nil, fmt.Errorf("twkb: %d bytes of trailing data", r.Len())
+	}
+	return g, ids, nil
+}
@@ -0,0 +1,304 @@
+package twkb
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+type encoder struct {
+	dimension int
+	scales    []float64
+	prev      []int64
+	min, max  []int64
+	empty     bool
+}
+
+func newEncoder(options Options) *encoder {
+	dimension := geom.Layout(options.Axes).Stride()
+	return &encoder{
+		dimension: dimension,
+		scales:    scales(options.Axes, options.Precision, options.ZPrecision, options.MPrecision),
+		prev:      make([]int64, dimension),
+		empty:     true,
+	}
+}
+
+func appendUvarint(dst []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(dst, buf[:n]...)
+}
+
+// appendVarint writes x zigzag encoded, as TWKB requires.
+func appendVarint(dst []byte, x int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], x)
+	return append(dst, buf[:n]...)
+}
+
+func (e *encoder) appendPoint(dst []byte, point geom.Point) ([]byte, error) {
+	if len(point) < e.dimension {
+		return nil, DimensionError{Dimension: e.dimension, ElementCount: len(point)}
+	}
+
+	if e.empty {
+		e.min = make([]int64, e.dimension)
+		e.max = make([]int64, e.dimension)
+	}
+	for i := 0; i < e.dimension; i++ {
+		v := int64(math.Round(point[i] * e.scales[i]))
+		dst = appendVarint(dst, v-e.prev[i])
+		e.prev[i] = v
+
+		if e.empty || v < e.min[i] {
+			e.min[i] = v
+		}
+		if e.empty || v > e.max[i] {
+			e.max[i] = v
+		}
+	}
+	e.empty = false
+	return dst, nil
+}
+
+func (e *encoder) appendPoints(dst []byte, points []geom.Point) ([]byte, error) {
+	dst = appendUvarint(dst, uint64(len(points)))
+	for _, point := range points {
+		var err error
+		if dst, err = e.appendPoint(dst, point); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func (e *encoder) appendPointss(dst []byte, pointss geom.Polygon) ([]byte, error) {
+	dst = appendUvarint(dst, uint64(len(pointss)))
+	for _, points := range pointss {
+		var err error
+		if dst, err = e.appendPoints(dst, points); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func appendIDs(dst []byte, ids []int64, count int) ([]byte, error) {
+	if len(ids) == 0 {
+		return dst, nil
+	}
+	if len(ids) != count {
+		return nil, IDCountError{len(ids), count}
+	}
+	for _, id := range ids {
+		dst = appendVarint(dst, id)
+	}
+	return dst, nil
+}
+
+func appendGeometry(dst []byte, g geom.T, options Options) ([]byte, error) {
+	if options.Precision < -8 || options.Precision > 7 {
+		return nil, InvalidPrecisionError{options.Precision}
+	}
+	if options.ZPrecision < 0 || options.ZPrecision > 7 {
+		return nil, InvalidPrecisionError{options.ZPrecision}
+	}
+	if options.MPrecision < 0 || options.MPrecision > 7 {
+		return nil, InvalidPrecisionError{options.MPrecision}
+	}
+	if geom.Layout(options.Axes).Stride() == 0 {
+		return nil, UnsupportedAxesError{options.Axes}
+	}
+
+	// count is zero for empty geometries
+	e := newEncoder(options)
+	var twkbType byte
+	var count int
+	var body []byte
+	var err error
+	switch g := g.(type) {
+	case geom.Point:
+		twkbType, count = twkbPoint, len(g)
+		if count != 0 {
+			body, err = e.appendPoint(nil, g)
+		}
+	case geom.LineString:
+		twkbType, count = twkbLineString, len(g)
+		if count != 0 {
+			body, err = e.appendPoints(nil, g)
+		}
+	case geom.Polygon:
+		twkbType, count = twkbPolygon, len(g)
+		if count != 0 {
+			body, err = e.appendPointss(nil, g)
+		}
+	case geom.MultiPoint:
+		twkbType, count = twkbMultiPoint, len(g)
+		if count != 0 {
+			body = appendUvarint(nil, uint64(count))
+			if body, err = appendIDs(body, options.IDs, count); err == nil {
+				for _, point := range g {
+					if body, err = e.appendPoint(body, point); err != nil {
+						break
+					}
+				}
+			}
+		}
+	case geom.MultiLineString:
+		twkbType, count = twkbMultiLineString, len(g)
+		if count != 0 {
+			body = appendUvarint(nil, uint64(count))
+			if body, err = appendIDs(body, options.IDs, count); err == nil {
+				for _, lineString := range g {
+					if body, err = e.appendPoints(body, lineString); err != nil {
+						break
+					}
+				}
+			}
+		}
+	case geom.MultiPolygon:
+		twkbType, count = twkbMultiPolygon, len(g)
+		if count != 0 {
+			body = appendUvarint(nil, uint64(count))
+			if body, err = appendIDs(body, options.IDs, count); err == nil {
+				for _, polygon := range g {
+					if body, err = e.appendPointss(body, polygon); err != nil {
+						break
+					}
+				}
+			}
+		}
+	case geom.GeometryCollection:
+		twkbType, count = twkbGeometryCollection, len(g)
+		if count != 0 {
+			body = appendUvarint(nil, uint64(count))
+			if body, err = appendIDs(body, options.IDs, count); err == nil {
+				member := options
+				member.IDs = nil
+				for _, t := range g {
+					if body, err = appendGeometry(body, t, member); err != nil {
+						break
+					}
+				}
+				e.min, e.max, e.empty = collectionBounds(g, member)
+			}
+		}
+	default:
+		return nil, &UnsupportedGeometryError{reflect.TypeOf(g)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// only non-empty multi-geometries and collections carry IDs
+	simple := twkbType == twkbPoint || twkbType == twkbLineString || twkbType == twkbPolygon
+	if len(options.IDs) != 0 && (simple || count == 0) {
+		return nil, IDCountError{len(options.IDs), 0}
+	}
+
+	dst = append(dst, twkbType|zigzag4(options.Precision)<<4)
+
+	var metadata byte
+	if options.BBox && !e.empty {
+		metadata |= twkbBBox
+	}
+	if options.Size {
+		metadata |= twkbSize
+	}
+	if len(options.IDs) != 0 {
+		metadata |= twkbIDList
+	}
+	if options.Axes != geom.TwoD {
+		metadata |= twkbExtendedDims
+	}
+	if count == 0 {
+		metadata |= twkbEmpty
+	}
+	dst = append(dst, metadata)
+
+	if options.Axes != geom.TwoD {
+		var extended byte
+		if options.Axes&geom.Z != 0 {
+			extended |= 0x01
+		}
+		if options.Axes&geom.M != 0 {
+			extended |= 0x02
+		}
+		extended |= byte(options.ZPrecision) << 2
+		extended |= byte(options.MPrecision) << 5
+		dst = append(dst, extended)
+	}
+
+	var bbox []byte
+	if metadata&twkbBBox != 0 {
+		for i := range e.min {
+			bbox = appendVarint(bbox, e.min[i])
+			bbox = appendVarint(bbox, e.max[i]-e.min[i])
+		}
+	}
+	if options.Size {
+		dst = appendUvarint(dst, uint64(len(bbox)+len(body)))
+	}
+	dst = append(dst, bbox...)
+	dst = append(dst, body...)
+	return dst, nil
+}
+
+// zigzag4 encodes a precision from -8 to 7 in four bits.
+func zigzag4(precision int) byte {
+	if precision < 0 {
+		return byte(-2*precision - 1)
+	}
+	return byte(2 * precision)
+}
+
+// collectionBounds finds the bounds of every member of a collection in the
+// scaled integer coordinates.
+func collectionBounds(g geom.GeometryCollection, options Options) ([]int64, []int64, bool) {
+	e := newEncoder(options)
+	var visit func(t geom.T)
+	visit = func(t geom.T) {
+		switch g := t.(type) {
+		case geom.Point:
+			if len(g) != 0 {
+				e.appendPoint(nil, g)
+			}
+		case geom.LineString:
+			e.appendPoints(nil, g)
+		case geom.Polygon:
+			e.appendPointss(nil, g)
+		case geom.MultiPoint:
+			e.appendPoints(nil, g)
+		case geom.MultiLineString:
+			for _, lineString := range g {
+				e.appendPoints(nil, lineString)
+			}
+		case geom.MultiPolygon:
+			for _, polygon := range g {
+				e.appendPointss(nil, polygon)
+			}
+		case geom.GeometryCollection:
+			for _, t := range g {
+				visit(t)
+			}
+		}
+	}
+	visit(g)
+	return e.min, e.max, e.empty
+}
+
+func Encode(g geom.T, options Options) ([]byte, error) {
+	return appendGeometry(nil, g, options)
+}
+
+func Write(w io.Writer, g geom.T, options Options) error {
+	data, err := Encode(g, options)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
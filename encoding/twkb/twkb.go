@@ -0,0 +1,102 @@
+// Package twkb implements Tiny Well-Known Binary, a compact encoding that
+// stores coordinates as variable-length, delta-encoded integers.
+package twkb
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/foobaz/geom"
+)
+
+const (
+	twkbPoint              = 1
+	twkbLineString         = 2
+	twkbPolygon            = 3
+	twkbMultiPoint         = 4
+	twkbMultiLineString    = 5
+	twkbMultiPolygon       = 6
+	twkbGeometryCollection = 7
+)
+
+// Flags in the metadata header byte.
+const (
+	twkbBBox         = 0x01
+	twkbSize         = 0x02
+	twkbIDList       = 0x04
+	twkbExtendedDims = 0x08
+	twkbEmpty        = 0x10
+)
+
+// Options configures encoding. The zero value encodes two-dimensional
+// geometries rounded to whole units.
+type Options struct {
+	// Axes must be geom.TwoD, geom.Z, geom.M, or geom.ZM.
+	Axes uint32
+	// Precision is the number of decimal digits kept for X and Y, from -8
+	// to 7. Negative values round to tens, hundreds and so on.
+	Precision int
+	// ZPrecision and MPrecision are the number of decimal digits kept for
+	// Z and M, from 0 to 7.
+	ZPrecision int
+	MPrecision int
+	// BBox adds a bounding box to the header.
+	BBox bool
+	// Size adds the length of the remaining geometry to the header, so
+	// readers can skip it.
+	Size bool
+	// IDs labels the members of a multi-geometry or geometry collection.
+	// It must be empty or have one ID per member.
+	IDs []int64
+}
+
+type UnsupportedGeometryError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedGeometryError) Error() string {
+	return "twkb: unsupported type: " + e.Type.String()
+}
+
+type UnsupportedAxesError struct {
+	Axes uint32
+}
+
+func (e UnsupportedAxesError) Error() string {
+	return fmt.Sprintf("twkb: unsupported axes %d", e.Axes)
+}
+
+type InvalidPrecisionError struct {
+	Precision int
+}
+
+func (e InvalidPrecisionError) Error() string {
+	return fmt.Sprintf("twkb: invalid precision %d", e.Precision)
+}
+
+// IDCountError reports an ID list whose length does not match the number of
+// members. GeometryCount is 0 for geometries that cannot carry IDs.
+type IDCountError struct {
+	IDCount       int
+	GeometryCount int
+}
+
+func (e IDCountError) Error() string {
+	return fmt.Sprintf("twkb: %d IDs for %d geometries", e.IDCount, e.GeometryCount)
+}
+
+// DimensionError reports a point with fewer components than the axes need.
+type DimensionError = geom.DimensionError
+
+// scales returns the factor applied to each axis before rounding.
+func scales(axes uint32, precision, zPrecision, mPrecision int) []float64 {
+	s := []float64{math.Pow10(precision), math.Pow10(precision)}
+	if axes&geom.Z != 0 {
+		s = append(s, math.Pow10(zPrecision))
+	}
+	if axes&geom.M != 0 {
+		s = append(s, math.Pow10(mPrecision))
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package twkb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestTWKB(t *testing.T) {
+	var testCases = []struct {
+		g       geom.T
+		options Options
+		twkb    []byte
+	}{
+		{
+			g:    geom.Point{1, 2},
+			twkb: []byte("\x01\x00\x02\x04"),
+		},
+		{
+			g:    geom.LineString{{1, 1}, {5, 5}},
+			twkb: []byte("\x02\x00\x02\x02\x02\x08\x08"),
+		},
+		{
+			g:       geom.LineString{{1, 1}, {5, 5}},
+			options: Options{BBox: true},
+			twkb:    []byte("\x02\x01\x02\x08\x02\x08\x02\x02\x02\x08\x08"),
+		},
+		{
+			g:       geom.LineString{{1, 1}, {5, 5}},
+			options: Options{Size: true},
+			twkb:    []byte("\x02\x02\x05\x02\x02\x02\x08\x08"),
+		},
+		{
+			g:       geom.Point{1.5, 2.5},
+			options: Options{Precision: 1},
+			twkb:    []byte("\x21\x00\x1e\x32"),
+		},
+		{
+			g:       geom.Point{120, -30},
+			options: Options{Precision: -1},
+			twkb:    []byte("\x11\x00\x18\x05"),
+		},
+		{
+			g:       geom.Point{1, 2, 3},
+			options: Options{Axes: geom.Z},
+			twkb:    []byte("\x01\x08\x01\x02\x04\x06"),
+		},
+		{
+			g:       geom.Point{1, 2, 0.25},
+			options: Options{Axes: geom.M, MPrecision: 2},
+			twkb:    []byte("\x01\x08\x42\x02\x04\x32"),
+		},
+		{
+			g:       geom.MultiPoint{{1, 2}, {3, 4}},
+			options: Options{IDs: []int64{10, 20}},
+			twkb:    []byte("\x04\x04\x02\x14\x28\x02\x04\x04\x04"),
+		},
+		{
+			g:    geom.Point{},
+			twkb: []byte("\x01\x10"),
+		},
+		{
+			g:       geom.MultiPolygon{},
+			options: Options{BBox: true},
+			twkb:    []byte("\x06\x10"),
+		},
+		{
+			g:    geom.GeometryCollection{geom.Point{1, 2}, geom.LineString{{1, 1}, {5, 5}}},
+			twkb: []byte("\x07\x00\x02\x01\x00\x02\x04\x02\x00\x02\x02\x02\x08\x08"),
+		},
+		{
+			g:       geom.GeometryCollection{geom.Point{1, 2}, geom.Point{3, 0}},
+			options: Options{BBox: true},
+			twkb:    []byte("\x07\x01\x02\x04\x00\x04\x02\x01\x01\x02\x00\x04\x00\x02\x04\x01\x01\x06\x00\x00\x00\x06\x00"),
+		},
+	}
+
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g, tc.options); err != nil || !reflect.DeepEqual(got, tc.twkb) {
+			t.Errorf("Encode(%#v, %#v) == %#v, %v, want %#v, nil", tc.g, tc.options, got, err, tc.twkb)
+		}
+		if got, ids, err := DecodeWithIDs(tc.twkb); err != nil || !reflect.DeepEqual(got, tc.g) || !reflect.DeepEqual(ids, tc.options.IDs) {
+			t.Errorf("DecodeWithIDs(%#v) == %#v, %#v, %v, want %#v, %#v, nil", tc.twkb, got, ids, err, tc.g, tc.options.IDs)
+		}
+	}
+}
+
+func TestTWKBRoundTrip(t *testing.T) {
+	var testCases = []struct {
+		g       geom.T
+		options Options
+	}{
+		{
+			geom.LineString{{0.1, 0.2, 3, 4}, {-5.5, 6.25, 7, 8}},
+			Options{Axes: geom.ZM, Precision: 2, ZPrecision: 1, MPrecision: 3, BBox: true, Size: true},
+		},
+		{
+			geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}},
+			Options{BBox: true},
+		},
+		{
+			geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+			Options{IDs: []int64{-1, 1 << 40}},
+		},
+		{
+			geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}},
+			Options{Size: true},
+		},
+		{
+			geom.GeometryCollection{geom.MultiPoint{{1, 2, 3}}, geom.GeometryCollection{geom.Polygon{}}},
+			Options{Axes: geom.Z, IDs: []int64{1, 2}, BBox: true, Size: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		var w bytes.Buffer
+		if err := Write(&w, tc.g, tc.options); err != nil {
+			t.Errorf("Write(%#v, %#v) == %v, want nil", tc.g, tc.options, err)
+			continue
+		}
+		// a trailing geometry must be left unread
+		w.Write([]byte("\x01\x10"))
+		if got, ids, err := ReadWithIDs(&w); err != nil || !reflect.DeepEqual(got, tc.g) || !reflect.DeepEqual(ids, tc.options.IDs) {
+			t.Errorf("ReadWithIDs(Write(%#v)) == %#v, %#v, %v, want %#v, %#v, nil", tc.g, got, ids, err, tc.g, tc.options.IDs)
+		}
+		if got, err := Read(&w); err != nil || !reflect.DeepEqual(got, geom.Point{}) {
+			t.Errorf("Read(trailing) == %#v, %v, want %#v, nil", got, err, geom.Point{})
+		}
+	}
+}
+
+func TestTWKBError(t *testing.T) {
+	var testCases = []struct {
+		g       geom.T
+		options Options
+		err     error
+	}{
+		{geom.Point{1, 2}, Options{Precision: 8}, InvalidPrecisionError{8}},
+		{geom.Point{1, 2}, Options{Axes: geom.Z, ZPrecision: -1}, InvalidPrecisionError{-1}},
+		{geom.Point{1, 2}, Options{Axes: 4}, UnsupportedAxesError{4}},
+		{geom.Point{1, 2}, Options{Axes: geom.Z}, DimensionError{Dimension: 3, ElementCount: 2}},
+		{geom.Point{1, 2}, Options{IDs: []int64{1}}, IDCountError{1, 0}},
+		{geom.MultiPoint{{1, 2}}, Options{IDs: []int64{1, 2}}, IDCountError{2, 1}},
+	}
+	for _, tc := range testCases {
+		if _, err := Encode(tc.g, tc.options); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Encode(%#v, %#v) == _, %#v, want %#v", tc.g, tc.options, err, tc.err)
+		}
+	}
+
+	// 65 nested geometry collections exceed the depth limit
+	nested := bytes.Repeat([]byte{0x07, 0x00, 0x01}, 65)
+	nested = append(nested, 0x01, 0x10)
+	for _, data := range [][]byte{{}, {0x01}, {0x01, 0x00, 0x02}, {0x09, 0x00}, {0x01, 0x00, 0x02, 0x04, 0x00}, nested} {
+		if got, err := Decode(data); err == nil {
+			t.Errorf("Decode(%#v) == %#v, nil, want err != nil", data, got)
+		}
+	}
+}
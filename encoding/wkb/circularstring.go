@@ -0,0 +1,19 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func circularStringReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	points, err := readPoints(d, byteOrder, dimension)
+	if err != nil {
+		return nil, err
+	}
+	return geom.CircularString(points), nil
+}
+
+func appendCircularString(dst []byte, order binary.AppendByteOrder, dimension int, circularString geom.CircularString) []byte {
+	return appendPoints(dst, order, dimension, circularString)
+}
@@ -0,0 +1,33 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func compoundCurveReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numCurves, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	curves := make(geom.CompoundCurve, 0, d.capacity(numCurves))
+	for i := 0; i < numCurves; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		switch g.(type) {
+		case geom.LineString, geom.CircularString:
+		default:
+			return nil, &UnexpectedGeometryError{g}
+		}
+		curves = append(curves, g)
+	}
+	return curves, nil
+}
+
+func appendCompoundCurve(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, compoundCurve geom.CompoundCurve) ([]byte, error) {
+	return appendGeometryCollection(dst, order, axes, extended, geom.GeometryCollection(compoundCurve))
+}
@@ -0,0 +1,33 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func curvePolygonReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numRings, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	rings := make(geom.CurvePolygon, 0, d.capacity(numRings))
+	for i := 0; i < numRings; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		switch g.(type) {
+		case geom.LineString, geom.CircularString, geom.CompoundCurve:
+		default:
+			return nil, &UnexpectedGeometryError{g}
+		}
+		rings = append(rings, g)
+	}
+	return rings, nil
+}
+
+func appendCurvePolygon(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, curvePolygon geom.CurvePolygon) ([]byte, error) {
+	return appendGeometryCollection(dst, order, axes, extended, geom.GeometryCollection(curvePolygon))
+}
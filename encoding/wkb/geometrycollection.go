@@ -2,38 +2,33 @@ package wkb
 
 import (
 	"encoding/binary"
+
 	"github.com/foobaz/geom"
-	"io"
 )
 
-func geometryCollectionReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	var numGeometries uint32
-	if err := binary.Read(r, byteOrder, &numGeometries); err != nil {
+func geometryCollectionReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numGeometries, err := d.count(byteOrder, geometrySize)
+	if err != nil {
 		return nil, err
 	}
-	geoms := make(geom.GeometryCollection, numGeometries)
-	for i := range geoms {
-		if g, err := Read(r); err == nil {
-			var ok bool
-			geoms[i], ok = g.(geom.T)
-			if !ok {
-				return nil, &UnexpectedGeometryError{g}
-			}
-		} else {
+	geoms := make(geom.GeometryCollection, 0, d.capacity(numGeometries))
+	for i := 0; i < numGeometries; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
 			return nil, err
 		}
+		geoms = append(geoms, g)
 	}
 	return geoms, nil
 }
 
-func writeGeometryCollection(w io.Writer, byteOrder binary.ByteOrder, axes uint32, geometryCollection geom.GeometryCollection) error {
-	if err := binary.Write(w, byteOrder, uint32(len(geometryCollection))); err != nil {
-		return err
-	}
-	for _, geom := range geometryCollection {
-		if err := Write(w, byteOrder, axes, geom); err != nil {
-			return err
+func appendGeometryCollection(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, geometryCollection geom.GeometryCollection) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(geometryCollection)))
+	for _, g := range geometryCollection {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return dst, nil
 }
@@ -2,18 +2,18 @@ package wkb
 
 import (
 	"encoding/binary"
+
 	"github.com/foobaz/geom"
-	"io"
 )
 
-func lineStringReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	points, err := readPoints(r, byteOrder, dimension)
+func lineStringReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	points, err := readPoints(d, byteOrder, dimension)
 	if err != nil {
 		return nil, err
 	}
 	return geom.LineString(points), nil
 }
 
-func writeLineString(w io.Writer, byteOrder binary.ByteOrder, dimension int, lineString geom.LineString) error {
-	return writePoints(w, byteOrder, dimension, lineString)
+func appendLineString(dst []byte, order binary.AppendByteOrder, dimension int, lineString geom.LineString) []byte {
+	return appendPoints(dst, order, dimension, lineString)
 }
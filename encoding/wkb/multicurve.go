@@ -0,0 +1,33 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func multiCurveReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numCurves, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	curves := make(geom.MultiCurve, 0, d.capacity(numCurves))
+	for i := 0; i < numCurves; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		switch g.(type) {
+		case geom.LineString, geom.CircularString, geom.CompoundCurve:
+		default:
+			return nil, &UnexpectedGeometryError{g}
+		}
+		curves = append(curves, g)
+	}
+	return curves, nil
+}
+
+func appendMultiCurve(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, multiCurve geom.MultiCurve) ([]byte, error) {
+	return appendGeometryCollection(dst, order, axes, extended, geom.GeometryCollection(multiCurve))
+}
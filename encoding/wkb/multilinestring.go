@@ -2,39 +2,38 @@ package wkb
 
 import (
 	"encoding/binary"
+
 	"github.com/foobaz/geom"
-	"io"
 )
 
-func multiLineStringReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	var numLineStrings uint32
-	if err := binary.Read(r, byteOrder, &numLineStrings); err != nil {
+func multiLineStringReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numLineStrings, err := d.count(byteOrder, geometrySize)
+	if err != nil {
 		return nil, err
 	}
-	lineStrings := make([]geom.LineString, numLineStrings)
-	for i := range lineStrings {
-		g, err := Read(r)
+	lineStrings := make([]geom.LineString, 0, d.capacity(numLineStrings))
+	for i := 0; i < numLineStrings; i++ {
+		g, _, _, err := d.read()
 		if err != nil {
 			return nil, err
 		}
 
-		var ok bool
-		lineStrings[i], ok = g.(geom.LineString)
+		member, ok := g.(geom.LineString)
 		if !ok {
 			return nil, &UnexpectedGeometryError{g}
 		}
+		lineStrings = append(lineStrings, member)
 	}
 	return geom.MultiLineString(lineStrings), nil
 }
 
-func writeMultiLineString(w io.Writer, byteOrder binary.ByteOrder, axes uint32, multiLineString geom.MultiLineString) error {
-	if err := binary.Write(w, byteOrder, uint32(len(multiLineString))); err != nil {
-		return err
-	}
-	for _, lineString := range multiLineString {
-		if err := Write(w, byteOrder, axes, lineString); err != nil {
-			return err
+func appendMultiLineString(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, multiLineString geom.MultiLineString) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(multiLineString)))
+	for _, g := range multiLineString {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return dst, nil
 }
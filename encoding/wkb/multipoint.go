@@ -2,38 +2,38 @@ package wkb
 
 import (
 	"encoding/binary"
+
 	"github.com/foobaz/geom"
-	"io"
 )
 
-func multiPointReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	var numPoints uint32
-	if err := binary.Read(r, byteOrder, &numPoints); err != nil {
+func multiPointReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numPoints, err := d.count(byteOrder, geometrySize)
+	if err != nil {
 		return nil, err
 	}
-	points := make([]geom.Point, numPoints)
-	for i := range points {
-		if g, err := Read(r); err == nil {
-			var ok bool
-			points[i], ok = g.(geom.Point)
-			if !ok {
-				return nil, &UnexpectedGeometryError{g}
-			}
-		} else {
+	points := make([]geom.Point, 0, d.capacity(numPoints))
+	for i := 0; i < numPoints; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
 			return nil, err
 		}
+
+		member, ok := g.(geom.Point)
+		if !ok {
+			return nil, &UnexpectedGeometryError{g}
+		}
+		points = append(points, member)
 	}
 	return geom.MultiPoint(points), nil
 }
 
-func writeMultiPoint(w io.Writer, byteOrder binary.ByteOrder, axes uint32, multiPoint geom.MultiPoint) error {
-	if err := binary.Write(w, byteOrder, uint32(len(multiPoint))); err != nil {
-		return err
-	}
-	for _, point := range multiPoint {
-		if err := Write(w, byteOrder, axes, point); err != nil {
-			return err
+func appendMultiPoint(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, multiPoint geom.MultiPoint) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(multiPoint)))
+	for _, g := range multiPoint {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return dst, nil
 }
@@ -2,38 +2,38 @@ package wkb
 
 import (
 	"encoding/binary"
+
 	"github.com/foobaz/geom"
-	"io"
 )
 
-func multiPolygonReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	var numPolygons uint32
-	if err := binary.Read(r, byteOrder, &numPolygons); err != nil {
+func multiPolygonReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numPolygons, err := d.count(byteOrder, geometrySize)
+	if err != nil {
 		return nil, err
 	}
-	polygons := make([]geom.Polygon, numPolygons)
-	for i := range polygons {
-		if g, err := Read(r); err == nil {
-			var ok bool
-			polygons[i], ok = g.(geom.Polygon)
-			if !ok {
-				return nil, &UnexpectedGeometryError{g}
-			}
-		} else {
+	polygons := make([]geom.Polygon, 0, d.capacity(numPolygons))
+	for i := 0; i < numPolygons; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
 			return nil, err
 		}
+
+		member, ok := g.(geom.Polygon)
+		if !ok {
+			return nil, &UnexpectedGeometryError{g}
+		}
+		polygons = append(polygons, member)
 	}
 	return geom.MultiPolygon(polygons), nil
 }
 
-func writeMultiPolygon(w io.Writer, byteOrder binary.ByteOrder, axes uint32, multiPolygon geom.MultiPolygon) error {
-	if err := binary.Write(w, byteOrder, uint32(len(multiPolygon))); err != nil {
-		return err
-	}
-	for _, polygon := range multiPolygon {
-		if err := Write(w, byteOrder, axes, polygon); err != nil {
-			return err
+func appendMultiPolygon(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, multiPolygon geom.MultiPolygon) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(multiPolygon)))
+	for _, g := range multiPolygon {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return dst, nil
 }
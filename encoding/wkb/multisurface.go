@@ -0,0 +1,33 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func multiSurfaceReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numSurfaces, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	surfaces := make(geom.MultiSurface, 0, d.capacity(numSurfaces))
+	for i := 0; i < numSurfaces; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		switch g.(type) {
+		case geom.Polygon, geom.CurvePolygon:
+		default:
+			return nil, &UnexpectedGeometryError{g}
+		}
+		surfaces = append(surfaces, g)
+	}
+	return surfaces, nil
+}
+
+func appendMultiSurface(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, multiSurface geom.MultiSurface) ([]byte, error) {
+	return appendGeometryCollection(dst, order, axes, extended, geom.GeometryCollection(multiSurface))
+}
@@ -2,87 +2,52 @@ package wkb
 
 import (
 	"encoding/binary"
-	"io"
 	"math"
 
 	"github.com/foobaz/geom"
 )
 
-func pointReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+func pointReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
 	point := make(geom.Point, dimension)
-	if err := binary.Read(r, byteOrder, point); err != nil {
+	if err := d.readPoint(byteOrder, point); err != nil {
 		return nil, err
 	}
 	return point, nil
 }
 
-func readPoints(r io.Reader, byteOrder binary.ByteOrder, dimension int) ([]geom.Point, error) {
-	var numPoints uint32
-	if err := binary.Read(r, byteOrder, &numPoints); err != nil {
+// readPoints reads a count followed by that many points.
+func readPoints(d *decoder, byteOrder binary.ByteOrder, dimension int) ([]geom.Point, error) {
+	numPoints, err := d.count(byteOrder, 8*dimension)
+	if err != nil {
 		return nil, err
 	}
-
-	components := make([]float64, int(numPoints)*dimension)
-	if err := binary.Read(r, byteOrder, components); err != nil {
-		return nil, err
-	}
-
-	points := make([]geom.Point, numPoints)
-	for i := range points {
-		j := i + 1
-		points[i] = geom.Point(components[i*dimension : j*dimension])
-	}
-
-	return points, nil
+	return d.readPoints(byteOrder, dimension, numPoints)
 }
 
-func writePoint(w io.Writer, byteOrder binary.ByteOrder, dimension int, point geom.Point) error {
-	clipped := make(geom.Point, dimension)
-	nan := math.NaN()
-	for i := range clipped {
-		clipped[i] = nan
+// appendPoint appends dimension components of point, padding with NaN.
+func appendPoint(dst []byte, order binary.AppendByteOrder, dimension int, point geom.Point) []byte {
+	for i := 0; i < dimension; i++ {
+		c := math.NaN()
+		if i < len(point) {
+			c = point[i]
+		}
+		dst = order.AppendUint64(dst, math.Float64bits(c))
 	}
-	copy(clipped, point)
-	return binary.Write(w, byteOrder, clipped)
+	return dst
 }
 
-func writePoints(w io.Writer, byteOrder binary.ByteOrder, dimension int, points []geom.Point) error {
-	pointCount := len(points)
-	countErr := binary.Write(w, byteOrder, uint32(pointCount))
-	if countErr != nil {
-		return countErr
-	}
-
-	nans := make(geom.Point, dimension)
-	nan := math.NaN()
-	for i := range nans {
-		nans[i] = nan
-	}
-	clipped := make(geom.Point, dimension)
+func appendPoints(dst []byte, order binary.AppendByteOrder, dimension int, points []geom.Point) []byte {
+	dst = order.AppendUint32(dst, uint32(len(points)))
 	for _, p := range points {
-		copy(clipped, nans)
-		copy(clipped, p)
-		pointErr := binary.Write(w, byteOrder, clipped)
-		if pointErr != nil {
-			return pointErr
-		}
+		dst = appendPoint(dst, order, dimension, p)
 	}
-
-	return nil
+	return dst
 }
 
-func writePointss(w io.Writer, byteOrder binary.ByteOrder, dimension int, pointss geom.Polygon) error {
-	countErr := binary.Write(w, byteOrder, uint32(len(pointss)))
-	if countErr != nil {
-		return countErr
-	}
-
+func appendPointss(dst []byte, order binary.AppendByteOrder, dimension int, pointss geom.Polygon) []byte {
+	dst = order.AppendUint32(dst, uint32(len(pointss)))
 	for _, points := range pointss {
-		pointsErr := writePoints(w, byteOrder, dimension, points)
-		if pointsErr != nil {
-			return pointsErr
-		}
+		dst = appendPoints(dst, order, dimension, points)
 	}
-	return nil
-
+	return dst
 }
@@ -2,27 +2,26 @@ package wkb
 
 import (
 	"encoding/binary"
-	"io"
 
 	"github.com/foobaz/geom"
 )
 
-func polygonReader(r io.Reader, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
-	var numRings uint32
-	if err := binary.Read(r, byteOrder, &numRings); err != nil {
+func polygonReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numRings, err := d.count(byteOrder, countSize)
+	if err != nil {
 		return nil, err
 	}
-	rings := make(geom.Polygon, numRings)
-	for i := uint32(0); i < numRings; i++ {
-		if points, err := readPoints(r, byteOrder, dimension); err != nil {
+	rings := make(geom.Polygon, 0, d.capacity(numRings))
+	for i := 0; i < numRings; i++ {
+		if points, err := readPoints(d, byteOrder, dimension); err != nil {
 			return nil, err
 		} else {
-			rings[i] = points
+			rings = append(rings, points)
 		}
 	}
 	return rings, nil
 }
 
-func writePolygon(w io.Writer, byteOrder binary.ByteOrder, dimension int, polygon geom.Polygon) error {
-	return writePointss(w, byteOrder, dimension, polygon)
+func appendPolygon(dst []byte, order binary.AppendByteOrder, dimension int, polygon geom.Polygon) []byte {
+	return appendPointss(dst, order, dimension, polygon)
 }
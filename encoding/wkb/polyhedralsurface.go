@@ -0,0 +1,39 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func polyhedralSurfaceReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numPolygons, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	polygons := make([]geom.Polygon, 0, d.capacity(numPolygons))
+	for i := 0; i < numPolygons; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		member, ok := g.(geom.Polygon)
+		if !ok {
+			return nil, &UnexpectedGeometryError{g}
+		}
+		polygons = append(polygons, member)
+	}
+	return geom.PolyhedralSurface(polygons), nil
+}
+
+func appendPolyhedralSurface(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, polyhedralSurface geom.PolyhedralSurface) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(polyhedralSurface)))
+	for _, g := range polyhedralSurface {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,39 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func tinReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	numTriangles, err := d.count(byteOrder, geometrySize)
+	if err != nil {
+		return nil, err
+	}
+	triangles := make([]geom.Triangle, 0, d.capacity(numTriangles))
+	for i := 0; i < numTriangles; i++ {
+		g, _, _, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+
+		member, ok := g.(geom.Triangle)
+		if !ok {
+			return nil, &UnexpectedGeometryError{g}
+		}
+		triangles = append(triangles, member)
+	}
+	return geom.TIN(triangles), nil
+}
+
+func appendTIN(dst []byte, order binary.AppendByteOrder, axes uint32, extended bool, tin geom.TIN) ([]byte, error) {
+	dst = order.AppendUint32(dst, uint32(len(tin)))
+	for _, g := range tin {
+		var err error
+		if dst, err = appendGeometry(dst, order, axes, g, extended, nil); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
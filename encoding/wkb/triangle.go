@@ -0,0 +1,26 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/foobaz/geom"
+)
+
+func triangleReader(d *decoder, byteOrder binary.ByteOrder, dimension int) (geom.T, error) {
+	polygon, err := polygonReader(d, byteOrder, dimension)
+	if err != nil {
+		return nil, err
+	}
+	triangle := geom.Triangle(polygon.(geom.Polygon))
+	if err := geom.ValidateTriangle(triangle); err != nil {
+		return nil, err
+	}
+	return triangle, nil
+}
+
+func appendTriangle(dst []byte, order binary.AppendByteOrder, dimension int, triangle geom.Triangle) ([]byte, error) {
+	if err := geom.ValidateTriangle(triangle); err != nil {
+		return nil, err
+	}
+	return appendPointss(dst, order, dimension, geom.Polygon(triangle)), nil
+}
@@ -1,10 +1,27 @@
+// Package wkb encodes and decodes Well-Known Binary, in both its ISO and
+// PostGIS extended forms.
+//
+// The encoders take the axes to write: geom.TwoD, geom.Z, geom.M or
+// geom.ZM, to which points are cut or padded with NaN, or geom.LayoutAxes
+// to write the axes of the geometry's own geom.Layout, as returned by
+// geom.LayoutOf. A Layout attached with geom.NewLayoutGeometry is
+// authoritative: any axes other than geom.LayoutAxes must match it, or a
+// geom.LayoutMismatchError is returned. Whatever the axes, the points of
+// the geometry must all have the same number of components, or a
+// geom.DimensionError is returned. EncodeLayout needs no axes.
+//
+// Callers that pass fixed axes migrate by attaching a Layout to geometries
+// with M values and calling EncodeLayout, or passing geom.LayoutAxes to the
+// other encoders, so that M values are never written as Z. The decoders
+// attach the Layout of XYM and XYZM input, so decoded geometries round trip
+// through EncodeLayout.
 package wkb
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 
 	"github.com/foobaz/geom"
@@ -28,11 +45,23 @@ const (
 	wkbMultiLineString    = 5
 	wkbMultiPolygon       = 6
 	wkbGeometryCollection = 7
+	wkbCircularString     = 8
+	wkbCompoundCurve      = 9
+	wkbCurvePolygon       = 10
+	wkbMultiCurve         = 11
+	wkbMultiSurface       = 12
 	wkbPolyhedralSurface  = 15
 	wkbTIN                = 16
 	wkbTriangle           = 17
 )
 
+// Flags used in the geometry type of PostGIS extended WKB.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
 type UnexpectedGeometryError struct {
 	Geom geom.T
 }
@@ -57,7 +86,185 @@ func (e UnsupportedAxesError) Error() string {
 	return fmt.Sprintf("wkb: unsupported axes %d", e.Axes)
 }
 
-type wkbReader func(io.Reader, binary.ByteOrder, int) (geom.T, error)
+// MixedAxesError reports a member geometry whose axes differ from those of
+// the outermost geometry.
+type MixedAxesError struct {
+	Axes  uint32
+	Outer uint32
+}
+
+func (e MixedAxesError) Error() string {
+	return fmt.Sprintf("wkb: member axes %d differ from outer axes %d", e.Axes, e.Outer)
+}
+
+// LimitError reports input that exceeds one of a Decoder's limits.
+type LimitError struct {
+	Limit string
+	Value int
+	Max   int
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("wkb: %d exceeds %s of %d", e.Value, e.Limit, e.Max)
+}
+
+// TruncatedError reports input that ends before the geometry does, or
+// whose counts need more bytes than remain. Offset is the length of the
+// input.
+type TruncatedError struct {
+	Offset int
+}
+
+func (e TruncatedError) Error() string {
+	return fmt.Sprintf("wkb: input truncated at offset %d", e.Offset)
+}
+
+// TrailingDataError reports bytes after the end of the geometry.
+type TrailingDataError struct {
+	Offset int
+}
+
+func (e TrailingDataError) Error() string {
+	return fmt.Sprintf("wkb: trailing data at offset %d", e.Offset)
+}
+
+// A Decoder decodes WKB within limits, so that hostile input cannot make
+// it allocate far more memory than the input's own size. A zero limit
+// means no limit. Counts are also checked against the remaining input
+// when its length is known: when decoding a []byte, or reading from an
+// io.Reader with a Len method such as *bytes.Reader. Otherwise memory is
+// allocated as the data arrives.
+type Decoder struct {
+	// MaxCoordinates limits the total number of points.
+	MaxCoordinates int
+	// MaxDepth limits how deeply geometries nest. A Point has depth 1 and
+	// a MultiPoint depth 2.
+	MaxDepth int
+	// MaxElements limits each count of points, rings or member
+	// geometries.
+	MaxElements int
+}
+
+// DefaultDecoder is used by Read and Decode.
+var DefaultDecoder = Decoder{MaxDepth: 32}
+
+// Minimum encoded sizes, used to check counts against the remaining input.
+const (
+	countSize    = 4
+	geometrySize = 9
+)
+
+// Without a known input length, element slices start no larger than this
+// and grow as elements are read.
+const maxPrealloc = 1024
+
+// decoder reads either from data, a []byte holding the whole input, or
+// from r, through scratch.
+type decoder struct {
+	Decoder
+	data        []byte
+	r           io.Reader
+	scratch     [32]byte
+	offset      int
+	remaining   int // -1 if unknown
+	depth       int
+	coordinates int
+	axes        uint32 // of the outermost geometry
+}
+
+// next consumes the next n bytes, which must be no more than 32 when
+// reading from r. The result is valid until the next call.
+func (d *decoder) next(n int) ([]byte, error) {
+	if d.r == nil {
+		if n > d.remaining {
+			d.offset, d.remaining = len(d.data), 0
+			return nil, TruncatedError{len(d.data)}
+		}
+		b := d.data[d.offset : d.offset+n]
+		d.offset += n
+		d.remaining -= n
+		return b, nil
+	}
+
+	b := d.scratch[:n]
+	k, err := io.ReadFull(d.r, b)
+	d.offset += k
+	if d.remaining >= 0 {
+		d.remaining -= k
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, TruncatedError{d.offset}
+	}
+	return b, err
+}
+
+func (d *decoder) uint32(byteOrder binary.ByteOrder) (uint32, error) {
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return byteOrder.Uint32(b), nil
+}
+
+// count reads an element count, checking it against MaxElements and
+// against the remaining input given the minimum size of each element.
+func (d *decoder) count(byteOrder binary.ByteOrder, size int) (int, error) {
+	n, err := d.uint32(byteOrder)
+	if err != nil {
+		return 0, err
+	}
+	if d.MaxElements > 0 && uint64(n) > uint64(d.MaxElements) {
+		return 0, LimitError{"MaxElements", int(n), d.MaxElements}
+	}
+	if d.remaining >= 0 && uint64(n)*uint64(size) > uint64(d.remaining) {
+		return 0, TruncatedError{d.offset + d.remaining}
+	}
+	return int(n), nil
+}
+
+// capacity returns the capacity to allocate for n elements.
+func (d *decoder) capacity(n int) int {
+	if d.remaining < 0 && n > maxPrealloc {
+		return maxPrealloc
+	}
+	return n
+}
+
+// readPoint fills point, checking it against MaxCoordinates.
+func (d *decoder) readPoint(byteOrder binary.ByteOrder, point geom.Point) error {
+	d.coordinates++
+	if d.MaxCoordinates > 0 && d.coordinates > d.MaxCoordinates {
+		return LimitError{"MaxCoordinates", d.coordinates, d.MaxCoordinates}
+	}
+	b, err := d.next(8 * len(point))
+	if err != nil {
+		return err
+	}
+	for i := range point {
+		point[i] = math.Float64frombits(byteOrder.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
+// readPoints reads n points, whose components share backing arrays.
+func (d *decoder) readPoints(byteOrder binary.ByteOrder, dimension, n int) ([]geom.Point, error) {
+	points := make([]geom.Point, 0, d.capacity(n))
+	var components []float64
+	for i := 0; i < n; i++ {
+		if len(components) == 0 {
+			components = make([]float64, dimension*d.capacity(n-i))
+		}
+		point := geom.Point(components[:dimension:dimension])
+		components = components[dimension:]
+		if err := d.readPoint(byteOrder, point); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+type wkbReader func(*decoder, binary.ByteOrder, int) (geom.T, error)
 
 var wkbReaders map[uint32]wkbReader
 
@@ -70,14 +277,80 @@ func init() {
 	wkbReaders[wkbMultiLineString] = multiLineStringReader
 	wkbReaders[wkbMultiPolygon] = multiPolygonReader
 	wkbReaders[wkbGeometryCollection] = geometryCollectionReader
+	wkbReaders[wkbCircularString] = circularStringReader
+	wkbReaders[wkbCompoundCurve] = compoundCurveReader
+	wkbReaders[wkbCurvePolygon] = curvePolygonReader
+	wkbReaders[wkbMultiCurve] = multiCurveReader
+	wkbReaders[wkbMultiSurface] = multiSurfaceReader
+	wkbReaders[wkbPolyhedralSurface] = polyhedralSurfaceReader
+	wkbReaders[wkbTIN] = tinReader
+	wkbReaders[wkbTriangle] = triangleReader
 }
 
+// Read accepts both the ISO convention, where Z and M are signalled by
+// adding multiples of 1000 to the geometry type, and PostGIS extended WKB,
+// where they are signalled by flags. A geometry with M values is returned
+// as a geom.LayoutGeometry, so that it can be re-encoded without losing its
+// measures. If the input carries an SRID the result is a
+// geom.SRIDGeometry. Members of a collection must have the axes of the
+// outermost geometry, or a MixedAxesError is returned. Read reads no
+// further than the end of the geometry, and uses DefaultDecoder's limits.
 func Read(r io.Reader) (geom.T, error) {
+	return DefaultDecoder.Read(r)
+}
+
+// Read decodes one geometry from r as the package-level Read does, within
+// dec's limits.
+func (dec Decoder) Read(r io.Reader) (geom.T, error) {
+	d := &decoder{Decoder: dec, r: r, remaining: -1}
+	if l, ok := r.(interface{ Len() int }); ok {
+		d.remaining = l.Len()
+	}
+	return d.geometry()
+}
+
+// Decode decodes buf, which must hold exactly one geometry, within dec's
+// limits. The input is read in place, without copying.
+func (dec Decoder) Decode(buf []byte) (geom.T, error) {
+	d := &decoder{Decoder: dec, data: buf, remaining: len(buf)}
+	g, err := d.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if d.remaining != 0 {
+		return nil, TrailingDataError{d.offset}
+	}
+	return g, nil
+}
 
-	var wkbByteOrder uint8
-	if err := binary.Read(r, binary.LittleEndian, &wkbByteOrder); err != nil {
+// geometry reads a geometry, wrapping it in a geom.LayoutGeometry if it
+// has M values and in a geom.SRIDGeometry if it has an SRID.
+func (d *decoder) geometry() (geom.T, error) {
+	g, srid, hasSRID, err := d.read()
+	if err != nil {
 		return nil, err
 	}
+	if d.axes&geom.M != 0 {
+		g = geom.NewLayoutGeometry(g, geom.Layout(d.axes))
+	}
+	if hasSRID {
+		return geom.NewSRIDGeometry(g, srid), nil
+	}
+	return g, nil
+}
+
+func (d *decoder) read() (geom.T, uint32, bool, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.MaxDepth > 0 && d.depth > d.MaxDepth {
+		return nil, 0, false, LimitError{"MaxDepth", d.depth, d.MaxDepth}
+	}
+
+	b, err := d.next(1)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	wkbByteOrder := b[0]
 	var byteOrder binary.ByteOrder
 	switch wkbByteOrder {
 	case wkbXDR:
@@ -85,54 +358,166 @@ func Read(r io.Reader) (geom.T, error) {
 	case wkbNDR:
 		byteOrder = binary.LittleEndian
 	default:
-		return nil, fmt.Errorf("invalid byte order %d", wkbByteOrder)
+		return nil, 0, false, fmt.Errorf("invalid byte order %d", wkbByteOrder)
 	}
 
-	var wkbGeometryType uint32
-	if err := binary.Read(r, byteOrder, &wkbGeometryType); err != nil {
-		return nil, err
+	wkbGeometryType, err := d.uint32(byteOrder)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	flags := wkbGeometryType & (ewkbZ | ewkbM | ewkbSRID)
+	wkbGeometryType &^= flags
+
+	var srid uint32
+	hasSRID := flags&ewkbSRID != 0
+	if hasSRID {
+		if srid, err = d.uint32(byteOrder); err != nil {
+			return nil, 0, false, err
+		}
 	}
 
 	axes := wkbGeometryType / 1000
-	dimension := dimensionsInAxes(axes)
+	baseType := wkbGeometryType - (axes * 1000)
+	if flags&(ewkbZ|ewkbM) != 0 {
+		if axes != geom.TwoD {
+			return nil, 0, false, fmt.Errorf("conflicting axes in geometry type %d", wkbGeometryType|flags)
+		}
+		if flags&ewkbZ != 0 {
+			axes |= geom.Z
+		}
+		if flags&ewkbM != 0 {
+			axes |= geom.M
+		}
+	}
+
+	dimension := geom.Layout(axes).Stride()
 	if dimension == 0 {
-		return nil, UnsupportedAxesError{axes}
+		return nil, 0, false, UnsupportedAxesError{axes}
+	}
+	if d.depth == 1 {
+		d.axes = axes
+	} else if axes != d.axes {
+		return nil, 0, false, MixedAxesError{axes, d.axes}
 	}
 
-	baseType := wkbGeometryType - (axes * 1000)
 	reader, ok := wkbReaders[baseType]
 	if !ok {
-		return nil, fmt.Errorf("unsupported geometry type %d", wkbGeometryType)
+		return nil, 0, false, fmt.Errorf("unsupported geometry type %d", wkbGeometryType)
 	}
 
-	return reader(r, byteOrder, dimension)
+	g, err := reader(d, byteOrder, dimension)
+	return g, srid, hasSRID, err
 }
 
+// Decode decodes buf, which must hold exactly one geometry, using
+// DefaultDecoder's limits. See Read.
 func Decode(buf []byte) (geom.T, error) {
-	return Read(bytes.NewBuffer(buf))
+	return DefaultDecoder.Decode(buf)
 }
 
-func writeMany(w io.Writer, byteOrder binary.ByteOrder, data ...interface{}) error {
-	for _, datum := range data {
-		if err := binary.Write(w, byteOrder, datum); err != nil {
-			return err
-		}
+// Append appends the ISO WKB encoding of g to dst. The SRIDs of
+// geom.SRIDGeometries in g are dropped. If axes is geom.LayoutAxes, g is encoded
+// with the axes of its geom.Layout.
+func Append(dst []byte, g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	order, err := appendByteOrder(byteOrder)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if axes, err = layout(g, axes); err != nil {
+		return nil, err
+	}
+	return appendGeometry(dst, order, axes, g, false, nil)
+}
+
+// AppendEWKB appends the PostGIS extended WKB encoding of g to dst. The
+// SRID of g, as returned by geom.SRIDOf, is written on the outermost
+// geometry, so members of a collection may carry it too. If axes is geom.LayoutAxes, g
+// is encoded with the axes of its geom.Layout.
+func AppendEWKB(dst []byte, g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	order, err := appendByteOrder(byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if axes, err = layout(g, axes); err != nil {
+		return nil, err
+	}
+	srid, ok, err := geom.SRIDOf(g)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		return appendGeometry(dst, order, axes, g, true, &srid)
+	}
+	return appendGeometry(dst, order, axes, g, true, nil)
 }
 
+// Write encodes g as ISO WKB. The SRID of a geom.SRIDGeometry is dropped.
 func Write(w io.Writer, byteOrder binary.ByteOrder, axes uint32, g geom.T) error {
-	var wkbByteOrder uint8
+	data, err := Encode(g, byteOrder, axes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteEWKB encodes g as PostGIS extended WKB. The SRID of a
+// geom.SRIDGeometry is included.
+func WriteEWKB(w io.Writer, byteOrder binary.ByteOrder, axes uint32, g geom.T) error {
+	data, err := EncodeEWKB(g, byteOrder, axes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// layout replaces geom.LayoutAxes with the axes of the layout of g.
+// Otherwise it checks that the points of g agree in dimension and that
+// every geom.Layout attached to g matches axes.
+func layout(g geom.T, axes uint32) (uint32, error) {
+	if axes == geom.LayoutAxes {
+		l, err := geom.LayoutOf(g)
+		if err != nil {
+			return 0, err
+		}
+		return uint32(l), nil
+	}
+	if err := geom.ValidateLayout(g); err != nil {
+		return 0, err
+	}
+	if l := geom.Layout(axes); l.Stride() != 0 {
+		if err := geom.MatchLayout(g, l); err != nil {
+			return 0, err
+		}
+	}
+	return axes, nil
+}
+
+func appendByteOrder(byteOrder binary.ByteOrder) (binary.AppendByteOrder, error) {
 	switch byteOrder {
 	case XDR:
-		wkbByteOrder = wkbXDR
+		return binary.BigEndian, nil
 	case NDR:
-		wkbByteOrder = wkbNDR
+		return binary.LittleEndian, nil
 	default:
-		return fmt.Errorf("unsupported byte order %v", byteOrder)
+		return nil, fmt.Errorf("unsupported byte order %v", byteOrder)
 	}
-	if err := binary.Write(w, byteOrder, wkbByteOrder); err != nil {
-		return err
+}
+
+func appendGeometry(dst []byte, order binary.AppendByteOrder, axes uint32, g geom.T, extended bool, srid *uint32) ([]byte, error) {
+	switch t := g.(type) {
+	case geom.SRIDGeometry:
+		// Only the outermost geometry carries an SRID.
+		return appendGeometry(dst, order, axes, t.T, extended, srid)
+	case geom.LayoutGeometry:
+		// layout has checked that the Layout matches axes.
+		return appendGeometry(dst, order, axes, t.T, extended, srid)
+	}
+
+	var wkbByteOrder uint8 = wkbNDR
+	if order == binary.BigEndian {
+		wkbByteOrder = wkbXDR
 	}
 
 	var wkbGeometryType uint32
@@ -151,57 +536,166 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, axes uint32, g geom.T) error
 		wkbGeometryType = wkbMultiPolygon
 	case geom.GeometryCollection:
 		wkbGeometryType = wkbGeometryCollection
+	case geom.CircularString:
+		wkbGeometryType = wkbCircularString
+	case geom.CompoundCurve:
+		wkbGeometryType = wkbCompoundCurve
+	case geom.CurvePolygon:
+		wkbGeometryType = wkbCurvePolygon
+	case geom.MultiCurve:
+		wkbGeometryType = wkbMultiCurve
+	case geom.MultiSurface:
+		wkbGeometryType = wkbMultiSurface
+	case geom.PolyhedralSurface:
+		wkbGeometryType = wkbPolyhedralSurface
+	case geom.TIN:
+		wkbGeometryType = wkbTIN
+	case geom.Triangle:
+		wkbGeometryType = wkbTriangle
 	default:
-		return &UnsupportedGeometryError{reflect.TypeOf(g)}
-	}
-	wkbGeometryType += (axes * 1000)
-	if err := binary.Write(w, byteOrder, wkbGeometryType); err != nil {
-		return err
+		return nil, &UnsupportedGeometryError{reflect.TypeOf(g)}
 	}
 
-	dimension := dimensionsInAxes(axes)
+	dimension := geom.Layout(axes).Stride()
 	if dimension == 0 {
-		return UnsupportedAxesError{axes}
+		return nil, UnsupportedAxesError{axes}
 	}
 
-	switch g.(type) {
+	if extended {
+		if axes&geom.Z != 0 {
+			wkbGeometryType |= ewkbZ
+		}
+		if axes&geom.M != 0 {
+			wkbGeometryType |= ewkbM
+		}
+		if srid != nil {
+			wkbGeometryType |= ewkbSRID
+		}
+	} else {
+		wkbGeometryType += (axes * 1000)
+	}
+	dst = append(dst, wkbByteOrder)
+	dst = order.AppendUint32(dst, wkbGeometryType)
+	if srid != nil {
+		dst = order.AppendUint32(dst, *srid)
+	}
+
+	switch g := g.(type) {
 	case geom.Point:
-		return writePoint(w, byteOrder, dimension, g.(geom.Point))
+		return appendPoint(dst, order, dimension, g), nil
 	case geom.LineString:
-		return writeLineString(w, byteOrder, dimension, g.(geom.LineString))
+		return appendLineString(dst, order, dimension, g), nil
 	case geom.Polygon:
-		return writePolygon(w, byteOrder, dimension, g.(geom.Polygon))
+		return appendPolygon(dst, order, dimension, g), nil
 	case geom.MultiPoint:
-		return writeMultiPoint(w, byteOrder, axes, g.(geom.MultiPoint))
+		return appendMultiPoint(dst, order, axes, extended, g)
 	case geom.MultiLineString:
-		return writeMultiLineString(w, byteOrder, axes, g.(geom.MultiLineString))
+		return appendMultiLineString(dst, order, axes, extended, g)
 	case geom.MultiPolygon:
-		return writeMultiPolygon(w, byteOrder, axes, g.(geom.MultiPolygon))
+		return appendMultiPolygon(dst, order, axes, extended, g)
 	case geom.GeometryCollection:
-		return writeGeometryCollection(w, byteOrder, axes, g.(geom.GeometryCollection))
+		return appendGeometryCollection(dst, order, axes, extended, g)
+	case geom.CircularString:
+		return appendCircularString(dst, order, dimension, g), nil
+	case geom.CompoundCurve:
+		return appendCompoundCurve(dst, order, axes, extended, g)
+	case geom.CurvePolygon:
+		return appendCurvePolygon(dst, order, axes, extended, g)
+	case geom.MultiCurve:
+		return appendMultiCurve(dst, order, axes, extended, g)
+	case geom.MultiSurface:
+		return appendMultiSurface(dst, order, axes, extended, g)
+	case geom.PolyhedralSurface:
+		return appendPolyhedralSurface(dst, order, axes, extended, g)
+	case geom.TIN:
+		return appendTIN(dst, order, axes, extended, g)
+	case geom.Triangle:
+		return appendTriangle(dst, order, dimension, g)
 	default:
-		return &UnsupportedGeometryError{reflect.TypeOf(g)}
+		return nil, &UnsupportedGeometryError{reflect.TypeOf(g)}
 	}
 }
 
+// encodedSize returns an upper bound on the length of the encoding of g,
+// so that it can be encoded with a single allocation.
+func encodedSize(g geom.T, dimension int) int {
+	switch g := g.(type) {
+	case geom.Point:
+		return 5 + 8*dimension
+	case geom.LineString:
+		return 9 + 8*dimension*len(g)
+	case geom.Polygon:
+		n := 9
+		for _, ring := range g {
+			n += 4 + 8*dimension*len(ring)
+		}
+		return n
+	case geom.MultiPoint:
+		return 9 + (5+8*dimension)*len(g)
+	case geom.MultiLineString:
+		n := 9
+		for _, lineString := range g {
+			n += 9 + 8*dimension*len(lineString)
+		}
+		return n
+	case geom.MultiPolygon:
+		n := 9
+		for _, polygon := range g {
+			n += encodedSize(polygon, dimension)
+		}
+		return n
+	case geom.GeometryCollection:
+		n := 9
+		for _, member := range g {
+			n += encodedSize(member, dimension)
+		}
+		return n
+	case geom.CircularString:
+		return encodedSize(geom.LineString(g), dimension)
+	case geom.CompoundCurve:
+		return encodedSize(geom.GeometryCollection(g), dimension)
+	case geom.CurvePolygon:
+		return encodedSize(geom.GeometryCollection(g), dimension)
+	case geom.MultiCurve:
+		return encodedSize(geom.GeometryCollection(g), dimension)
+	case geom.MultiSurface:
+		return encodedSize(geom.GeometryCollection(g), dimension)
+	case geom.Triangle:
+		return encodedSize(geom.Polygon(g), dimension)
+	case geom.TIN:
+		n := 9
+		for _, triangle := range g {
+			n += encodedSize(triangle, dimension)
+		}
+		return n
+	case geom.PolyhedralSurface:
+		return encodedSize(geom.MultiPolygon(g), dimension)
+	case geom.SRIDGeometry:
+		return 4 + encodedSize(g.T, dimension)
+	case geom.LayoutGeometry:
+		return encodedSize(g.T, dimension)
+	}
+	return 0
+}
+
 func Encode(g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
-	w := bytes.NewBuffer(nil)
-	if err := Write(w, byteOrder, axes, g); err != nil {
+	axes, err := layout(g, axes)
+	if err != nil {
 		return nil, err
 	}
-	return w.Bytes(), nil
+	return Append(make([]byte, 0, encodedSize(g, geom.Layout(axes).Stride())), g, byteOrder, axes)
 }
 
-func dimensionsInAxes(axes uint32) int {
-	dimension := 0
-	switch axes {
-	case geom.TwoD:
-		dimension = 2
-	case geom.Z, geom.M:
-		dimension = 3
-	case geom.ZM:
-		dimension = 4
-	}
+// EncodeLayout encodes g as ISO WKB with the axes of its geom.Layout. It is
+// Encode with geom.LayoutAxes.
+func EncodeLayout(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+	return Encode(g, byteOrder, geom.LayoutAxes)
+}
 
-	return dimension
+func EncodeEWKB(g geom.T, byteOrder binary.ByteOrder, axes uint32) ([]byte, error) {
+	axes, err := layout(g, axes)
+	if err != nil {
+		return nil, err
+	}
+	return AppendEWKB(make([]byte, 0, encodedSize(g, geom.Layout(axes).Stride())), g, byteOrder, axes)
 }
@@ -1,9 +1,14 @@
 package wkb
 
 import (
-	"github.com/foobaz/geom"
+	"bytes"
+	"encoding/binary"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/foobaz/geom"
 )
 
 func TestWKB(t *testing.T) {
@@ -114,9 +119,15 @@ func TestWKB(t *testing.T) {
 
 	for _, tc := range testCases {
 
+		// geometries with M values decode with their layout attached
+		want := tc.g
+		if tc.axes&geom.M != 0 {
+			want = geom.NewLayoutGeometry(tc.g, geom.Layout(tc.axes))
+		}
+
 		// test XDR decoding
-		if got, err := Decode(tc.xdr); err != nil || !reflect.DeepEqual(got, tc.g) {
-			t.Errorf("Decode(%#v) == %#v, %s, want %#v, nil", tc.xdr, got, err, tc.g)
+		if got, err := Decode(tc.xdr); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(%#v) == %#v, %s, want %#v, nil", tc.xdr, got, err, want)
 		}
 
 		// test XDR encoding
@@ -125,8 +136,8 @@ func TestWKB(t *testing.T) {
 		}
 
 		// test NDR decoding
-		if got, err := Decode(tc.ndr); err != nil || !reflect.DeepEqual(got, tc.g) {
-			t.Errorf("Decode(%#v) == %#v, %s, want %#v, nil", tc.ndr, got, err, tc.g)
+		if got, err := Decode(tc.ndr); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(%#v) == %#v, %s, want %#v, nil", tc.ndr, got, err, want)
 		}
 
 		// test NDR encoding
@@ -137,3 +148,358 @@ func TestWKB(t *testing.T) {
 	}
 
 }
+
+func TestEWKB(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		xdr  []byte
+		axes uint32
+	}{
+		{
+			g:    geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM),
+			xdr:  []byte("\x00@\x00\x00\x01?\xf0\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00@\x08\x00\x00\x00\x00\x00\x00"),
+			axes: geom.M,
+		},
+		{
+			g:    geom.NewSRIDGeometry(geom.Point{1, 2}, 4326),
+			xdr:  []byte("\x00\x20\x00\x00\x01\x00\x00\x10\xe6?\xf0\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00"),
+			axes: geom.TwoD,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got, err := Decode(tc.xdr); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%#v) == %#v, %s, want %#v, nil", tc.xdr, got, err, tc.g)
+		}
+		if got, err := EncodeEWKB(tc.g, XDR, tc.axes); err != nil || !reflect.DeepEqual(got, tc.xdr) {
+			t.Errorf("EncodeEWKB(%#v, %#v) == %#v, %#v, want %#v, nil", tc.g, XDR, got, err, tc.xdr)
+		}
+	}
+
+	// Write drops the SRID
+	g := geom.NewSRIDGeometry(geom.Point{1, 2}, 4326)
+	want := []byte("\x00\x00\x00\x00\x01?\xf0\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00")
+	if got, err := Encode(g, XDR, geom.TwoD); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode(%#v, %#v) == %#v, %#v, want %#v, nil", g, XDR, got, err, want)
+	}
+
+	// SRIDs of members are written on the outermost geometry.
+	collection := geom.GeometryCollection{geom.NewSRIDGeometry(geom.Point{1, 2}, 4326), geom.Point{3, 4}}
+	wantCollection := geom.NewSRIDGeometry(geom.GeometryCollection{geom.Point{1, 2}, geom.Point{3, 4}}, 4326)
+	for _, g := range []geom.T{collection, geom.NewSRIDGeometry(collection, 4326)} {
+		data, err := EncodeEWKB(g, NDR, geom.TwoD)
+		if err != nil {
+			t.Errorf("EncodeEWKB(%#v) == %v", g, err)
+		} else if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, wantCollection) {
+			t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", data, got, err, wantCollection)
+		}
+	}
+	if got, err := Encode(collection, NDR, geom.TwoD); err != nil || len(got) != 51 {
+		t.Errorf("Encode(%#v) == %#v, %v", collection, got, err)
+	}
+	mixed := geom.NewSRIDGeometry(collection, 3857)
+	if _, err := EncodeEWKB(mixed, NDR, geom.TwoD); !reflect.DeepEqual(err, geom.SRIDError{SRID: 3857, Other: 4326}) {
+		t.Errorf("EncodeEWKB(%#v) == %#v", mixed, err)
+	}
+}
+
+func TestSurface(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		axes uint32
+	}{
+		{geom.Triangle{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}, geom.TwoD},
+		{geom.TIN{{{{0, 0, 0}, {1, 0, 0}, {0, 1, 1}, {0, 0, 0}}}, {{{1, 0, 0}, {1, 1, 1}, {0, 1, 1}, {1, 0, 0}}}}, geom.Z},
+		{geom.NewLayoutGeometry(geom.PolyhedralSurface{{{{0, 0, 0, 1}, {0, 1, 0, 1}, {1, 1, 0, 1}, {0, 0, 0, 1}}}}, geom.XYZM), geom.ZM},
+		{geom.NewSRIDGeometry(geom.TIN{}, 4326), geom.TwoD},
+	}
+	for _, tc := range testCases {
+		data, err := EncodeEWKB(tc.g, NDR, tc.axes)
+		if err != nil {
+			t.Errorf("EncodeEWKB(%#v) == %v", tc.g, err)
+			continue
+		}
+		if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", data, got, err, tc.g)
+		}
+	}
+
+	// ISO type codes: 1017 is a Triangle Z, 16 a TIN.
+	triangle := []byte("\x00\x00\x00\x03\xf9\x00\x00\x00\x01\x00\x00\x00\x00")
+	if got, err := Decode(triangle); err != nil || !reflect.DeepEqual(got, geom.Triangle{{}}) {
+		t.Errorf("Decode(%#v) == %#v, %v", triangle, got, err)
+	}
+	tin := []byte("\x00\x00\x00\x00\x10\x00\x00\x00\x01\x00\x00\x00\x00\x03\x00\x00\x00\x00")
+	if _, err := Decode(tin); !reflect.DeepEqual(err, &UnexpectedGeometryError{geom.Polygon{}}) {
+		t.Errorf("Decode(TIN of Polygon) == %#v", err)
+	}
+
+	open := geom.Triangle{{{0, 0}, {1, 0}, {0, 1}, {1, 1}}}
+	if _, err := Encode(open, NDR, geom.TwoD); !reflect.DeepEqual(err, geom.TriangleError{}) {
+		t.Errorf("Encode(%#v) == _, %#v, want geom.TriangleError{}", open, err)
+	}
+	// a Triangle with a ring of three points
+	short := []byte("\x00\x00\x00\x00\x11\x00\x00\x00\x01\x00\x00\x00\x03" + strings.Repeat("\x00", 48))
+	if _, err := Decode(short); !reflect.DeepEqual(err, geom.TriangleError{}) {
+		t.Errorf("Decode(%#v) == _, %#v, want geom.TriangleError{}", short, err)
+	}
+}
+
+func TestCurve(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		axes uint32
+		code uint32
+	}{
+		{geom.CircularString{{0, 0}, {1, 1}, {2, 0}}, geom.TwoD, 8},
+		{geom.CompoundCurve{geom.CircularString{{0, 0, 1}, {1, 1, 1}, {2, 0, 1}}, geom.LineString{{2, 0, 1}, {0, 0, 1}}}, geom.Z, 1009},
+		{geom.NewLayoutGeometry(geom.CurvePolygon{geom.CircularString{{0, 0, 0}, {2, 0, 1}, {0, 0, 0}}, geom.LineString{{1, 0, 0}, {1, 1, 0}, {1, 0, 0}}}, geom.XYM), geom.M, 2010},
+		{geom.NewLayoutGeometry(geom.MultiCurve{geom.LineString{{0, 0, 0, 0}, {1, 1, 1, 1}}, geom.CompoundCurve{}}, geom.XYZM), geom.ZM, 3011},
+		{geom.MultiSurface{geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, geom.CurvePolygon{}}, geom.TwoD, 12},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.g, XDR, tc.axes)
+		if err != nil {
+			t.Errorf("Encode(%#v) == %v", tc.g, err)
+			continue
+		}
+		if code := binary.BigEndian.Uint32(data[1:]); code != tc.code {
+			t.Errorf("Encode(%#v) has type %d, want %d", tc.g, code, tc.code)
+		}
+		if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", data, got, err, tc.g)
+		}
+		g := geom.NewSRIDGeometry(tc.g, 2154)
+		if data, err = EncodeEWKB(g, NDR, tc.axes); err != nil {
+			t.Errorf("EncodeEWKB(%#v) == %v", g, err)
+		} else if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", data, got, err, g)
+		}
+	}
+
+	// A CompoundCurve of a Point.
+	compoundCurve := []byte("\x00\x00\x00\x00\x09\x00\x00\x00\x01\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	if _, err := Decode(compoundCurve); !reflect.DeepEqual(err, &UnexpectedGeometryError{geom.Point{0, 0}}) {
+		t.Errorf("Decode(CompoundCurve of Point) == %#v", err)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		want geom.T
+		code uint32
+	}{
+		{geom.Point{1, 2}, geom.Point{1, 2}, 1},
+		{geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.LineString{{1, 2, 3}, {4, 5, 6}}, 1002},
+		{geom.NewLayoutGeometry(geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.XYM), geom.NewLayoutGeometry(geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.XYM), 2002},
+		{geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.MultiPoint{{1, 2, 3, 4}}, geom.XYZM), 4326), geom.NewLayoutGeometry(geom.MultiPoint{{1, 2, 3, 4}}, geom.XYZM), 3004},
+	}
+	for _, tc := range testCases {
+		data, err := Encode(tc.g, XDR, geom.LayoutAxes)
+		if err != nil {
+			t.Errorf("Encode(%#v, LayoutAxes) == %v", tc.g, err)
+			continue
+		}
+		if code := binary.BigEndian.Uint32(data[1:]); code != tc.code {
+			t.Errorf("Encode(%#v, LayoutAxes) has type %d, want %d", tc.g, code, tc.code)
+		}
+		if got, err := EncodeLayout(tc.g, XDR); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("EncodeLayout(%#v) == %#v, %v, want %#v, nil", tc.g, got, err, data)
+		}
+		if got, err := Decode(data); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", data, got, err, tc.want)
+		}
+	}
+
+	g := geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM), 4326)
+	if _, err := EncodeEWKB(g, NDR, geom.Z); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XYZ}) {
+		t.Errorf("EncodeEWKB(%#v, Z) == _, %#v", g, err)
+	}
+	collection := geom.GeometryCollection{geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)}
+	data, err := Encode(collection, NDR, geom.M)
+	if err != nil || binary.LittleEndian.Uint32(data[1:]) != 2007 || binary.LittleEndian.Uint32(data[10:]) != 2001 {
+		t.Errorf("Encode(%#v, M) == %x, %v", collection, data, err)
+	}
+	if _, err := Encode(collection, NDR, geom.TwoD); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XY}) {
+		t.Errorf("Encode(%#v, TwoD) == _, %#v", collection, err)
+	}
+
+	mixed := geom.LineString{{1, 2}, {3, 4, 5}}
+	for _, axes := range []uint32{geom.LayoutAxes, geom.TwoD, geom.Z} {
+		if _, err := Encode(mixed, NDR, axes); !reflect.DeepEqual(err, geom.DimensionError{Dimension: 2, ElementCount: 3}) {
+			t.Errorf("Encode(%#v, %d) == %#v", mixed, axes, err)
+		}
+	}
+
+	// M values survive a decode and re-encode.
+	for _, data := range [][]byte{
+		[]byte("\x01\xd1\x07\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x08@"),
+		[]byte("\x01\x01\x00\x00\x60\xe6\x10\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x08@"),
+	} {
+		g, err := Decode(data)
+		if err != nil {
+			t.Errorf("Decode(%#v) == %v", data, err)
+			continue
+		}
+		want := data
+		if _, ok := g.(geom.SRIDGeometry); ok {
+			want = []byte("\x01\xd1\x07\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x08@")
+		}
+		if got, err := EncodeLayout(g, NDR); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("EncodeLayout(%#v) == %#v, %v, want %#v, nil", g, got, err, want)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	dst := []byte("prefix")
+	g := geom.MultiPoint{{1, 2}, {3, 4}}
+	want := "prefix\x00\x00\x00\x03\xec\x00\x00\x00\x02\x00\x00\x00\x03\xe9?\xf0\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x00\x7f\xf8\x00\x00\x00\x00\x00\x01\x00\x00\x00\x03\xe9@\x08\x00\x00\x00\x00\x00\x00@\x10\x00\x00\x00\x00\x00\x00\x7f\xf8\x00\x00\x00\x00\x00\x01"
+	if got, err := Append(dst, g, XDR, geom.Z); err != nil || string(got) != want {
+		t.Errorf("Append(%q, %#v) == %q, %v, want %q, nil", dst, g, got, err, want)
+	}
+	if _, err := Append(dst, geom.MultiPoint{{1, 2}, {3}}, XDR, geom.TwoD); !reflect.DeepEqual(err, geom.DimensionError{Dimension: 2, ElementCount: 1}) {
+		t.Errorf("Append(ragged MultiPoint) == %#v", err)
+	}
+	if _, err := Append(dst, geom.Feature{}, XDR, geom.TwoD); !reflect.DeepEqual(err, &UnsupportedGeometryError{reflect.TypeOf(geom.Feature{})}) {
+		t.Errorf("Append(Feature) == %#v", err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	lineString := []byte("\x01\x02\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x08@\x00\x00\x00\x00\x00\x00\x10@")
+	multiPoint := []byte("\x01\x04\x00\x00\x00\x02\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x08@\x00\x00\x00\x00\x00\x00\x10@")
+	var testCases = []struct {
+		dec  Decoder
+		data []byte
+		err  error
+	}{
+		{DefaultDecoder, []byte{}, TruncatedError{0}},
+		{DefaultDecoder, []byte("\x01\x01\x00"), TruncatedError{3}},
+		{DefaultDecoder, lineString[:30], TruncatedError{30}},
+		// A count of 2^32-1 points in a 9 byte payload.
+		{DefaultDecoder, []byte("\x01\x02\x00\x00\x00\xff\xff\xff\xff"), TruncatedError{9}},
+		{DefaultDecoder, []byte("\x01\x07\x00\x00\x00\xff\xff\xff\x7f"), TruncatedError{9}},
+		{DefaultDecoder, append(lineString, 0), TrailingDataError{len(lineString)}},
+		{Decoder{MaxElements: 1}, lineString, LimitError{"MaxElements", 2, 1}},
+		{Decoder{MaxCoordinates: 3}, multiPoint, nil},
+		{Decoder{MaxCoordinates: 1}, multiPoint, LimitError{"MaxCoordinates", 2, 1}},
+		{Decoder{MaxDepth: 2}, multiPoint, nil},
+		{Decoder{MaxDepth: 1}, multiPoint, LimitError{"MaxDepth", 2, 1}},
+		// A two-dimensional collection holding a PostGIS PointM.
+		{DefaultDecoder, []byte("\x01\x07\x00\x00\x00\x01\x00\x00\x00\x01\x01\x00\x00\x40\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@\x00\x00\x00\x00\x00\x00\x08@"), MixedAxesError{geom.M, geom.TwoD}},
+		// An XYZ MultiPoint holding a two-dimensional point.
+		{DefaultDecoder, []byte("\x01\xec\x03\x00\x00\x01\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0?\x00\x00\x00\x00\x00\x00\x00@"), MixedAxesError{geom.TwoD, geom.Z}},
+	}
+	for _, tc := range testCases {
+		if _, err := tc.dec.Decode(tc.data); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("%#v.Decode(%#v) == %#v, want %#v", tc.dec, tc.data, err, tc.err)
+		}
+	}
+
+	// Without a known length, a large count fails at the end of the input.
+	r := io.MultiReader(bytes.NewReader([]byte("\x01\x02\x00\x00\x00\xff\xff\xff\xff")))
+	if _, err := Read(r); !reflect.DeepEqual(err, TruncatedError{9}) {
+		t.Errorf("Read(large count) == %#v", err)
+	}
+	// Read stops at the end of the geometry.
+	br := bytes.NewReader(append(lineString, 0))
+	if _, err := Read(br); err != nil || br.Len() != 1 {
+		t.Errorf("Read(trailing data) == %#v", err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, g := range []geom.T{
+		geom.Point{1, 2},
+		geom.LineString{{1, 2, 3}, {4, 5, 6}},
+		geom.Polygon{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}},
+		geom.MultiPolygon{{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}}},
+		geom.GeometryCollection{geom.MultiPoint{{1, 2}}, geom.MultiLineString{{{1, 2}, {3, 4}}}},
+	} {
+		data, err := Encode(g, NDR, geom.TwoD)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		data, err = EncodeEWKB(geom.NewSRIDGeometry(g, 4326), XDR, geom.ZM)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := Decode(data)
+		if err != nil {
+			return
+		}
+		// Anything decoded can be encoded again.
+		if _, err := EncodeEWKB(g, NDR, geom.LayoutAxes); err != nil {
+			t.Errorf("EncodeEWKB(Decode(%#v)) == %v", data, err)
+		}
+	})
+}
+
+// benchmarkGeometry is a polygon with 1000 points in each of three rings.
+func benchmarkGeometry() geom.T {
+	polygon := make(geom.Polygon, 3)
+	for i := range polygon {
+		ring := make(geom.Ring, 1000)
+		for j := range ring {
+			ring[j] = geom.Point{float64(i), float64(j)}
+		}
+		polygon[i] = ring
+	}
+	return geom.MultiPolygon{polygon}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	g := benchmarkGeometry()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Encode(g, NDR, geom.TwoD); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	g := benchmarkGeometry()
+	var dst []byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if dst, err = Append(dst[:0], g, NDR, geom.TwoD); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data, err := Encode(benchmarkGeometry(), NDR, geom.TwoD)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	data, err := Encode(benchmarkGeometry(), NDR, geom.TwoD)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Read(io.MultiReader(bytes.NewReader(data))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendCircularStringWKT(dst []byte, circularString geom.CircularString, name []byte, dimension int) []byte {
+	dst = append(dst, []byte("CIRCULARSTRING")...)
+	dst = append(dst, name...)
+	if len(circularString) == 0 {
+		return appendEmpty(dst)
+	}
+	return appendPointsText(dst, circularString, dimension)
+}
+
+func (d *decoder) circularString() (geom.T, error) {
+	points, err := d.pointsText()
+	if err != nil {
+		return nil, err
+	}
+	return geom.CircularString(points), nil
+}
@@ -0,0 +1,89 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+// appendMembersWKT writes the members of a curved geometry. LineStrings
+// and Polygons are written as bare text and other geometries with their
+// names.
+func appendMembersWKT(dst []byte, members []geom.T, name []byte, dimension int) ([]byte, error) {
+	if len(members) == 0 {
+		return appendEmpty(dst), nil
+	}
+	dst = append(dst, '(')
+	for i, g := range members {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		switch g := g.(type) {
+		case geom.LineString:
+			dst = appendPointsText(dst, g, dimension)
+		case geom.Polygon:
+			var err error
+			if dst, err = appendPointssText(dst, g, dimension); err != nil {
+				return nil, err
+			}
+		default:
+			var err error
+			if dst, err = appendWKT(dst, g, name, dimension); err != nil {
+				return nil, err
+			}
+		}
+	}
+	dst = append(dst, ')')
+	return dst, nil
+}
+
+func appendCompoundCurveWKT(dst []byte, compoundCurve geom.CompoundCurve, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("COMPOUNDCURVE")...)
+	dst = append(dst, name...)
+	return appendMembersWKT(dst, compoundCurve, name, dimension)
+}
+
+// members parses the members of a curved geometry of type name. A bare
+// member is parsed by text, and a named member must be of a type accepted
+// by accept.
+func (d *decoder) members(name string, text func() (geom.T, error), accept func(geom.T) bool) ([]geom.T, error) {
+	members := []geom.T{}
+	err := d.list(func() error {
+		start := d.pos
+		bare := d.peek() == '(' || d.empty()
+		d.pos = start
+		if bare {
+			g, err := text()
+			members = append(members, g)
+			return err
+		}
+
+		d.skipSpace()
+		start = d.pos
+		g, err := d.geometry()
+		if err != nil {
+			return err
+		}
+		if !accept(g) {
+			return SyntaxError{start, "invalid " + name + " member"}
+		}
+		members = append(members, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (d *decoder) compoundCurve() (geom.T, error) {
+	members, err := d.members("COMPOUNDCURVE", d.lineString, func(g geom.T) bool {
+		switch g.(type) {
+		case geom.LineString, geom.CircularString:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return geom.CompoundCurve(members), nil
+}
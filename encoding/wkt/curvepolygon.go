@@ -0,0 +1,29 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendCurvePolygonWKT(dst []byte, curvePolygon geom.CurvePolygon, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("CURVEPOLYGON")...)
+	dst = append(dst, name...)
+	return appendMembersWKT(dst, curvePolygon, name, dimension)
+}
+
+// isCurve returns whether g is a LineString, CircularString or
+// CompoundCurve.
+func isCurve(g geom.T) bool {
+	switch g.(type) {
+	case geom.LineString, geom.CircularString, geom.CompoundCurve:
+		return true
+	}
+	return false
+}
+
+func (d *decoder) curvePolygon() (geom.T, error) {
+	members, err := d.members("CURVEPOLYGON", d.lineString, isCurve)
+	if err != nil {
+		return nil, err
+	}
+	return geom.CurvePolygon(members), nil
+}
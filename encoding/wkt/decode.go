@@ -0,0 +1,318 @@
+package wkt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/foobaz/geom"
+)
+
+type wktParser func(*decoder) (geom.T, error)
+
+var wktParsers map[string]wktParser
+
+func init() {
+	wktParsers = make(map[string]wktParser)
+	wktParsers["POINT"] = (*decoder).point
+	wktParsers["LINESTRING"] = (*decoder).lineString
+	wktParsers["POLYGON"] = (*decoder).polygon
+	wktParsers["MULTIPOINT"] = (*decoder).multiPoint
+	wktParsers["MULTILINESTRING"] = (*decoder).multiLineString
+	wktParsers["MULTIPOLYGON"] = (*decoder).multiPolygon
+	wktParsers["GEOMETRYCOLLECTION"] = (*decoder).geometryCollection
+	wktParsers["CIRCULARSTRING"] = (*decoder).circularString
+	wktParsers["COMPOUNDCURVE"] = (*decoder).compoundCurve
+	wktParsers["CURVEPOLYGON"] = (*decoder).curvePolygon
+	wktParsers["MULTICURVE"] = (*decoder).multiCurve
+	wktParsers["MULTISURFACE"] = (*decoder).multiSurface
+	wktParsers["TRIANGLE"] = (*decoder).triangle
+	wktParsers["TIN"] = (*decoder).tin
+	wktParsers["POLYHEDRALSURFACE"] = (*decoder).polyhedralSurface
+}
+
+// Decode parses a single WKT geometry. Axis suffixes (Z, M, ZM) may be
+// attached to the type name or separated by whitespace. Without a suffix,
+// the dimension is taken from the first coordinate. A geometry with a Z
+// suffix or with M values is returned as a geom.LayoutGeometry, so that it
+// can be re-encoded without losing its axes, even when it is empty. PostGIS extended WKT with a SRID=<srid>;
+// prefix is decoded to a geom.SRIDGeometry.
+func Decode(data []byte) (geom.T, error) {
+	d := &decoder{data: data}
+	srid, hasSRID, err := d.srid()
+	if err != nil {
+		return nil, err
+	}
+	g, err := d.geometry()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case d.axes == "Z":
+		g = geom.NewLayoutGeometry(g, geom.XYZ)
+	case d.axes == "M":
+		g = geom.NewLayoutGeometry(g, geom.XYM)
+	case d.axes == "ZM" || d.dimension == 4:
+		g = geom.NewLayoutGeometry(g, geom.XYZM)
+	}
+	if hasSRID {
+		g = geom.NewSRIDGeometry(g, srid)
+	}
+
+	d.skipSpace()
+	if d.pos != len(d.data) {
+		return nil, SyntaxError{d.pos, "unexpected data after geometry"}
+	}
+
+	return g, nil
+}
+
+type decoder struct {
+	data      []byte
+	pos       int
+	dimension int
+	axes      string // the Z, M or ZM suffix, if any
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isLetter(c byte) bool {
+	return ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z')
+}
+
+func isNumberByte(c byte) bool {
+	return ('0' <= c && c <= '9') || c == '.' || c == '+' || c == '-' || c == 'e' || c == 'E'
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) && isSpace(d.data[d.pos]) {
+		d.pos++
+	}
+}
+
+// peek returns the next non-space byte without consuming it, or 0 at the
+// end of the input.
+func (d *decoder) peek() byte {
+	d.skipSpace()
+	if d.pos == len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+func (d *decoder) expect(c byte) error {
+	if d.peek() != c {
+		return SyntaxError{d.pos, "expected '" + string(c) + "'"}
+	}
+	d.pos++
+	return nil
+}
+
+// word consumes a run of letters and returns it in upper case.
+func (d *decoder) word() string {
+	d.skipSpace()
+	start := d.pos
+	for d.pos < len(d.data) && isLetter(d.data[d.pos]) {
+		d.pos++
+	}
+	return strings.ToUpper(string(d.data[start:d.pos]))
+}
+
+// empty consumes the EMPTY keyword if it comes next.
+func (d *decoder) empty() bool {
+	start := d.pos
+	if d.word() == "EMPTY" {
+		return true
+	}
+	d.pos = start
+	return false
+}
+
+func (d *decoder) number() (float64, error) {
+	d.skipSpace()
+	start := d.pos
+	for d.pos < len(d.data) && isNumberByte(d.data[d.pos]) {
+		d.pos++
+	}
+	if start == d.pos {
+		return 0, SyntaxError{start, "expected number"}
+	}
+	f, err := strconv.ParseFloat(string(d.data[start:d.pos]), 64)
+	if err != nil {
+		return 0, SyntaxError{start, "invalid number " + strconv.Quote(string(d.data[start:d.pos]))}
+	}
+	return f, nil
+}
+
+// srid consumes an optional SRID=<srid>; prefix.
+func (d *decoder) srid() (uint32, bool, error) {
+	start := d.pos
+	if d.word() != "SRID" {
+		d.pos = start
+		return 0, false, nil
+	}
+	if err := d.expect('='); err != nil {
+		return 0, false, err
+	}
+	d.skipSpace()
+	numberStart := d.pos
+	for d.pos < len(d.data) && '0' <= d.data[d.pos] && d.data[d.pos] <= '9' {
+		d.pos++
+	}
+	srid, err := strconv.ParseUint(string(d.data[numberStart:d.pos]), 10, 32)
+	if err != nil {
+		return 0, false, SyntaxError{numberStart, "invalid SRID"}
+	}
+	if err := d.expect(';'); err != nil {
+		return 0, false, err
+	}
+	return uint32(srid), true, nil
+}
+
+func (d *decoder) setDimension(offset, dimension int) error {
+	if d.dimension == 0 {
+		d.dimension = dimension
+	} else if d.dimension != dimension {
+		return DimensionError{offset, d.dimension, dimension}
+	}
+	return nil
+}
+
+// setAxes records an axis suffix, which must agree with any seen before.
+func (d *decoder) setAxes(offset int, axes string) error {
+	dimension := 3
+	if axes == "ZM" {
+		dimension = 4
+	}
+	if err := d.setDimension(offset, dimension); err != nil {
+		return err
+	}
+	if d.axes == "" {
+		d.axes = axes
+	} else if d.axes != axes {
+		return SyntaxError{offset, "conflicting axes " + d.axes + " and " + axes}
+	}
+	return nil
+}
+
+func splitAxes(name string) (string, string) {
+	if _, ok := wktParsers[name]; ok {
+		return name, ""
+	}
+	if strings.HasSuffix(name, "ZM") {
+		return name[:len(name)-2], "ZM"
+	}
+	if strings.HasSuffix(name, "Z") || strings.HasSuffix(name, "M") {
+		return name[:len(name)-1], name[len(name)-1:]
+	}
+	return name, ""
+}
+
+func (d *decoder) geometry() (geom.T, error) {
+	d.skipSpace()
+	start := d.pos
+	name, axes := splitAxes(d.word())
+	if name == "" {
+		return nil, SyntaxError{start, "expected geometry type"}
+	}
+	parser, ok := wktParsers[name]
+	if !ok {
+		return nil, UnknownGeometryError{start, name}
+	}
+
+	if axes == "" {
+		suffixStart := d.pos
+		switch word := d.word(); word {
+		case "Z", "M", "ZM":
+			axes = word
+		default:
+			d.pos = suffixStart
+		}
+	}
+	if axes != "" {
+		if err := d.setAxes(start, axes); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser(d)
+}
+
+func (d *decoder) coords() (geom.Point, error) {
+	d.skipSpace()
+	start := d.pos
+	point := make(geom.Point, 0, 4)
+	for {
+		c := d.peek()
+		if c == ',' || c == ')' || c == 0 {
+			break
+		}
+		f, err := d.number()
+		if err != nil {
+			return nil, err
+		}
+		point = append(point, f)
+	}
+
+	n := len(point)
+	if n < 2 || n > 4 {
+		return nil, DimensionError{start, d.dimension, n}
+	}
+	if err := d.setDimension(start, n); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func (d *decoder) pointsText() ([]geom.Point, error) {
+	points := []geom.Point{}
+	err := d.list(func() error {
+		point, err := d.coords()
+		points = append(points, point)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func (d *decoder) pointssText() (geom.Polygon, error) {
+	pointss := geom.Polygon{}
+	err := d.list(func() error {
+		d.skipSpace()
+		start := d.pos
+		points, err := d.pointsText()
+		if err == nil && len(points) == 0 {
+			return SyntaxError{start, "empty ring"}
+		}
+		pointss = append(pointss, points)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pointss, nil
+}
+
+// list parses a parenthesized, comma-separated list, calling item for each
+// element. It accepts EMPTY in place of the list.
+func (d *decoder) list(item func() error) error {
+	if d.empty() {
+		return nil
+	}
+	if err := d.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if d.peek() != ',' {
+			break
+		}
+		d.pos++
+	}
+	return d.expect(')')
+}
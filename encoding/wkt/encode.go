@@ -1,12 +1,71 @@
 package wkt
 
 import (
-	"github.com/foobaz/geom"
 	"reflect"
+	"strconv"
+
+	"github.com/foobaz/geom"
 )
 
-// axes must be geom.TwoD, geom.Z, geom.M, or geom.ZM
+// axes must be geom.TwoD, geom.Z, geom.M, geom.ZM, or geom.LayoutAxes to
+// use the geom.Layout of t. The SRIDs of geom.SRIDGeometries in t are
+// dropped.
 func Encode(t geom.T, axes int) ([]byte, error) {
+	axes, err := layout(t, axes)
+	if err != nil {
+		return nil, err
+	}
+	return encode(nil, t, axes)
+}
+
+// EncodeLayout encodes t with the axes of its geom.Layout. It is Encode
+// with geom.LayoutAxes.
+func EncodeLayout(t geom.T) ([]byte, error) {
+	return Encode(t, geom.LayoutAxes)
+}
+
+// EncodeEWKT encodes t as PostGIS extended WKT. The SRID of t, as
+// returned by geom.SRIDOf, is written as a SRID=<srid>; prefix.
+func EncodeEWKT(t geom.T, axes int) ([]byte, error) {
+	axes, err := layout(t, axes)
+	if err != nil {
+		return nil, err
+	}
+	var dst []byte
+	srid, ok, err := geom.SRIDOf(t)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		dst = append(dst, []byte("SRID=")...)
+		dst = strconv.AppendUint(dst, uint64(srid), 10)
+		dst = append(dst, ';')
+	}
+	return encode(dst, t, axes)
+}
+
+// layout replaces geom.LayoutAxes with the axes of the layout of t.
+// Otherwise it checks that the points of t agree in dimension and that
+// every geom.Layout attached to t matches axes.
+func layout(t geom.T, axes int) (int, error) {
+	if axes == geom.LayoutAxes {
+		l, err := geom.LayoutOf(t)
+		if err != nil {
+			return 0, err
+		}
+		return int(l), nil
+	}
+	if err := geom.ValidateLayout(t); err != nil {
+		return 0, err
+	}
+	if l := geom.Layout(axes); l.Stride() != 0 {
+		if err := geom.MatchLayout(t, l); err != nil {
+			return 0, err
+		}
+	}
+	return axes, nil
+}
+
+func encode(dst []byte, t geom.T, axes int) ([]byte, error) {
 	name := []byte{}
 	dimension := 0
 	switch axes {
@@ -26,18 +85,55 @@ func Encode(t geom.T, axes int) ([]byte, error) {
 		return nil, UnsupportedAxesError{axes}
 	}
 
+	return appendWKT(dst, t, name, dimension)
+}
+
+// A nil geometry, such as an empty result from geomop.Construct, is
+// encoded as GEOMETRYCOLLECTION EMPTY.
+func appendWKT(dst []byte, t geom.T, name []byte, dimension int) ([]byte, error) {
 	switch g := t.(type) {
+	case nil:
+		return appendGeometryCollectionWKT(dst, nil, name, dimension)
+	case geom.SRIDGeometry:
+		return appendWKT(dst, g.T, name, dimension)
+	case geom.LayoutGeometry:
+		// layout has checked that the Layout matches the axes.
+		return appendWKT(dst, g.T, name, dimension)
 	case geom.Point:
-		return appendPointWKT(nil, g, name, dimension), nil
+		return appendPointWKT(dst, g, name, dimension), nil
 	case geom.LineString:
-		return appendLineStringWKT(nil, g, name, dimension), nil
+		return appendLineStringWKT(dst, g, name, dimension), nil
 	case geom.MultiLineString:
-		return appendMultiLineStringWKT(nil, g, name, dimension), nil
+		return appendMultiLineStringWKT(dst, g, name, dimension), nil
 	case geom.Polygon:
-		return appendPolygonWKT(nil, g, name, dimension), nil
+		return appendPolygonWKT(dst, g, name, dimension)
 	case geom.MultiPolygon:
-		return appendMultiPolygonWKT(nil, g, name, dimension), nil
+		return appendMultiPolygonWKT(dst, g, name, dimension)
+	case geom.MultiPoint:
+		return appendMultiPointWKT(dst, g, name, dimension), nil
+	case geom.GeometryCollection:
+		return appendGeometryCollectionWKT(dst, g, name, dimension)
+	case geom.CircularString:
+		return appendCircularStringWKT(dst, g, name, dimension), nil
+	case geom.CompoundCurve:
+		return appendCompoundCurveWKT(dst, g, name, dimension)
+	case geom.CurvePolygon:
+		return appendCurvePolygonWKT(dst, g, name, dimension)
+	case geom.MultiCurve:
+		return appendMultiCurveWKT(dst, g, name, dimension)
+	case geom.MultiSurface:
+		return appendMultiSurfaceWKT(dst, g, name, dimension)
+	case geom.Triangle:
+		return appendTriangleWKT(dst, g, name, dimension)
+	case geom.TIN:
+		return appendTINWKT(dst, g, name, dimension)
+	case geom.PolyhedralSurface:
+		return appendPolyhedralSurfaceWKT(dst, g, name, dimension)
 	default:
 		return nil, &UnsupportedGeometryError{reflect.TypeOf(g)}
 	}
 }
+
+func appendEmpty(dst []byte) []byte {
+	return append(dst, []byte(" EMPTY")...)
+}
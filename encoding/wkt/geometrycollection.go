@@ -0,0 +1,42 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendGeometryCollectionWKT(dst []byte, geometryCollection geom.GeometryCollection, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("GEOMETRYCOLLECTION")...)
+	dst = append(dst, name...)
+	if len(geometryCollection) == 0 {
+		return appendEmpty(dst), nil
+	}
+	dst = append(dst, '(')
+	for i, g := range geometryCollection {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		dst, err = appendWKT(dst, g, name, dimension)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dst = append(dst, ')')
+	return dst, nil
+}
+
+func (d *decoder) geometryCollection() (geom.T, error) {
+	geometryCollection := geom.GeometryCollection{}
+	err := d.list(func() error {
+		g, err := d.geometry()
+		if err != nil {
+			return err
+		}
+		geometryCollection = append(geometryCollection, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return geometryCollection, nil
+}
@@ -7,8 +7,16 @@ import (
 func appendLineStringWKT(dst []byte, lineString geom.LineString, name []byte, dimension int) []byte {
 	dst = append(dst, []byte("LINESTRING")...)
 	dst = append(dst, name...)
-	dst = append(dst, '(')
-	dst = appendPointsCoords(dst, lineString, dimension)
-	dst = append(dst, ')')
-	return dst
+	if len(lineString) == 0 {
+		return appendEmpty(dst)
+	}
+	return appendPointsText(dst, lineString, dimension)
+}
+
+func (d *decoder) lineString() (geom.T, error) {
+	points, err := d.pointsText()
+	if err != nil {
+		return nil, err
+	}
+	return geom.LineString(points), nil
 }
@@ -0,0 +1,19 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendMultiCurveWKT(dst []byte, multiCurve geom.MultiCurve, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("MULTICURVE")...)
+	dst = append(dst, name...)
+	return appendMembersWKT(dst, multiCurve, name, dimension)
+}
+
+func (d *decoder) multiCurve() (geom.T, error) {
+	members, err := d.members("MULTICURVE", d.lineString, isCurve)
+	if err != nil {
+		return nil, err
+	}
+	return geom.MultiCurve(members), nil
+}
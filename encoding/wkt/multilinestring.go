@@ -7,17 +7,29 @@ import (
 func appendMultiLineStringWKT(dst []byte, multiLineString geom.MultiLineString, name []byte, dimension int) []byte {
 	dst = append(dst, []byte("MULTILINESTRING")...)
 	dst = append(dst, name...)
-	dst = append(dst, '(')
+	if len(multiLineString) == 0 {
+		return appendEmpty(dst)
+	}
 	dst = append(dst, '(')
 	for i, ls := range multiLineString {
-		dst = appendPointsCoords(dst, ls, dimension)
-		if i != len(multiLineString)-1 {
-			dst = append(dst, ')')
+		if i != 0 {
 			dst = append(dst, ',')
-			dst = append(dst, '(')
 		}
+		dst = appendPointsText(dst, ls, dimension)
 	}
 	dst = append(dst, ')')
-	dst = append(dst, ')')
 	return dst
 }
+
+func (d *decoder) multiLineString() (geom.T, error) {
+	multiLineString := geom.MultiLineString{}
+	err := d.list(func() error {
+		points, err := d.pointsText()
+		multiLineString = append(multiLineString, points)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return multiLineString, nil
+}
@@ -0,0 +1,54 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendMultiPointWKT(dst []byte, multiPoint geom.MultiPoint, name []byte, dimension int) []byte {
+	dst = append(dst, []byte("MULTIPOINT")...)
+	dst = append(dst, name...)
+	if len(multiPoint) == 0 {
+		return appendEmpty(dst)
+	}
+	dst = append(dst, '(')
+	for i, point := range multiPoint {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		if len(point) == 0 {
+			dst = append(dst, []byte("EMPTY")...)
+			continue
+		}
+		dst = append(dst, '(')
+		dst = appendPointCoords(dst, point, dimension)
+		dst = append(dst, ')')
+	}
+	dst = append(dst, ')')
+	return dst
+}
+
+// multiPoint accepts both MULTIPOINT(1 2,3 4) and MULTIPOINT((1 2),(3 4)).
+func (d *decoder) multiPoint() (geom.T, error) {
+	multiPoint := geom.MultiPoint{}
+	err := d.list(func() error {
+		if d.peek() != '(' {
+			if d.empty() {
+				multiPoint = append(multiPoint, geom.Point{})
+				return nil
+			}
+			point, err := d.coords()
+			multiPoint = append(multiPoint, point)
+			return err
+		}
+		g, err := d.point()
+		if err != nil {
+			return err
+		}
+		multiPoint = append(multiPoint, g.(geom.Point))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return multiPoint, nil
+}
@@ -4,20 +4,35 @@ import (
 	"github.com/foobaz/geom"
 )
 
-func appendMultiPolygonWKT(dst []byte, multiPolygon geom.MultiPolygon, name []byte, dimension int) []byte {
+func appendMultiPolygonWKT(dst []byte, multiPolygon geom.MultiPolygon, name []byte, dimension int) ([]byte, error) {
 	dst = append(dst, []byte("MULTIPOLYGON")...)
 	dst = append(dst, name...)
-	dst = append(dst, '(')
+	if len(multiPolygon) == 0 {
+		return appendEmpty(dst), nil
+	}
 	dst = append(dst, '(')
 	for i, pg := range multiPolygon {
-		dst = appendPointssCoords(dst, pg, dimension)
-		if i != len(multiPolygon)-1 {
-			dst = append(dst, ')')
+		if i != 0 {
 			dst = append(dst, ',')
-			dst = append(dst, '(')
+		}
+		var err error
+		if dst, err = appendPointssText(dst, pg, dimension); err != nil {
+			return nil, err
 		}
 	}
 	dst = append(dst, ')')
-	dst = append(dst, ')')
-	return dst
+	return dst, nil
+}
+
+func (d *decoder) multiPolygon() (geom.T, error) {
+	multiPolygon := geom.MultiPolygon{}
+	err := d.list(func() error {
+		pointss, err := d.pointssText()
+		multiPolygon = append(multiPolygon, pointss)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return multiPolygon, nil
 }
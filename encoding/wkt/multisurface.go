@@ -0,0 +1,25 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendMultiSurfaceWKT(dst []byte, multiSurface geom.MultiSurface, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("MULTISURFACE")...)
+	dst = append(dst, name...)
+	return appendMembersWKT(dst, multiSurface, name, dimension)
+}
+
+func (d *decoder) multiSurface() (geom.T, error) {
+	members, err := d.members("MULTISURFACE", d.polygon, func(g geom.T) bool {
+		switch g.(type) {
+		case geom.Polygon, geom.CurvePolygon:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return geom.MultiSurface(members), nil
+}
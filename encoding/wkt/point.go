@@ -36,24 +36,79 @@ func appendPointsCoords(dst []byte, points []geom.Point, dimension int) []byte {
 	return dst
 }
 
-func appendPointssCoords(dst []byte, pointss geom.Polygon, dimension int) []byte {
+// appendPointsText writes a parenthesized list of points, or EMPTY.
+func appendPointsText(dst []byte, points []geom.Point, dimension int) []byte {
+	if len(points) == 0 {
+		return append(dst, []byte("EMPTY")...)
+	}
+	dst = append(dst, '(')
+	dst = appendPointsCoords(dst, points, dimension)
+	dst = append(dst, ')')
+	return dst
+}
+
+// emptyRings returns whether every ring of pointss is empty, and an
+// EmptyRingError if only some of them are.
+func emptyRings(pointss geom.Polygon) (bool, error) {
+	n := 0
+	for _, points := range pointss {
+		if len(points) == 0 {
+			n++
+		}
+	}
+	if n != 0 && n != len(pointss) {
+		return false, EmptyRingError{}
+	}
+	return n == len(pointss), nil
+}
+
+// appendPointssText writes a parenthesized list of rings, or EMPTY if
+// there are none or all of them are empty.
+func appendPointssText(dst []byte, pointss geom.Polygon, dimension int) ([]byte, error) {
+	empty, err := emptyRings(pointss)
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		return append(dst, []byte("EMPTY")...), nil
+	}
+	dst = append(dst, '(')
 	for i, points := range pointss {
 		if i != 0 {
 			dst = append(dst, ',')
 		}
-		dst = append(dst, '(')
-		dst = appendPointsCoords(dst, points, dimension)
-		dst = append(dst, ')')
+		dst = appendPointsText(dst, points, dimension)
 	}
-	return dst
+	dst = append(dst, ')')
+	return dst, nil
 }
 
 func appendPointWKT(dst []byte, point geom.Point, name []byte, dimension int) []byte {
 	dst = append(dst, []byte("POINT")...)
 	dst = append(dst, name...)
+	if len(point) == 0 {
+		return appendEmpty(dst)
+	}
 	dst = append(dst, '(')
 	dst = appendPointCoords(dst, point, dimension)
 	dst = append(dst, ')')
 
 	return dst
 }
+
+func (d *decoder) point() (geom.T, error) {
+	if d.empty() {
+		return geom.Point{}, nil
+	}
+	if err := d.expect('('); err != nil {
+		return nil, err
+	}
+	point, err := d.coords()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.expect(')'); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
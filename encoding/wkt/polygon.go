@@ -4,12 +4,17 @@ import (
 	"github.com/foobaz/geom"
 )
 
-func appendPolygonWKT(dst []byte, polygon geom.Polygon, name []byte, dimension int) []byte {
+func appendPolygonWKT(dst []byte, polygon geom.Polygon, name []byte, dimension int) ([]byte, error) {
 	dst = append(dst, []byte("POLYGON")...)
 	dst = append(dst, name...)
-	dst = append(dst, '(')
-	dst = appendPointssCoords(dst, polygon, dimension)
-	dst = append(dst, ')')
+	if empty, err := emptyRings(polygon); err != nil {
+		return nil, err
+	} else if empty {
+		return appendEmpty(dst), nil
+	}
+	return appendPointssText(dst, polygon, dimension)
+}
 
-	return dst
+func (d *decoder) polygon() (geom.T, error) {
+	return d.pointssText()
 }
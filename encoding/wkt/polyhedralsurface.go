@@ -0,0 +1,38 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendPolyhedralSurfaceWKT(dst []byte, polyhedralSurface geom.PolyhedralSurface, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("POLYHEDRALSURFACE")...)
+	dst = append(dst, name...)
+	if len(polyhedralSurface) == 0 {
+		return appendEmpty(dst), nil
+	}
+	dst = append(dst, '(')
+	for i, polygon := range polyhedralSurface {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = appendPointssText(dst, polygon, dimension); err != nil {
+			return nil, err
+		}
+	}
+	dst = append(dst, ')')
+	return dst, nil
+}
+
+func (d *decoder) polyhedralSurface() (geom.T, error) {
+	polyhedralSurface := geom.PolyhedralSurface{}
+	err := d.list(func() error {
+		pointss, err := d.pointssText()
+		polyhedralSurface = append(polyhedralSurface, pointss)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return polyhedralSurface, nil
+}
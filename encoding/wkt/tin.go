@@ -0,0 +1,38 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendTINWKT(dst []byte, tin geom.TIN, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("TIN")...)
+	dst = append(dst, name...)
+	if len(tin) == 0 {
+		return appendEmpty(dst), nil
+	}
+	dst = append(dst, '(')
+	for i, triangle := range tin {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = appendTriangleText(dst, triangle, dimension); err != nil {
+			return nil, err
+		}
+	}
+	dst = append(dst, ')')
+	return dst, nil
+}
+
+func (d *decoder) tin() (geom.T, error) {
+	tin := geom.TIN{}
+	err := d.list(func() error {
+		triangle, err := d.triangleText()
+		tin = append(tin, triangle)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tin, nil
+}
@@ -0,0 +1,44 @@
+package wkt
+
+import (
+	"github.com/foobaz/geom"
+)
+
+func appendTriangleWKT(dst []byte, triangle geom.Triangle, name []byte, dimension int) ([]byte, error) {
+	dst = append(dst, []byte("TRIANGLE")...)
+	dst = append(dst, name...)
+	if err := geom.ValidateTriangle(triangle); err != nil {
+		return nil, err
+	}
+	if len(triangle) == 0 || len(triangle[0]) == 0 {
+		return appendEmpty(dst), nil
+	}
+	return appendPointssText(dst, geom.Polygon(triangle), dimension)
+}
+
+func appendTriangleText(dst []byte, triangle geom.Triangle, dimension int) ([]byte, error) {
+	if err := geom.ValidateTriangle(triangle); err != nil {
+		return nil, err
+	}
+	return appendPointssText(dst, geom.Polygon(triangle), dimension)
+}
+
+func (d *decoder) triangle() (geom.T, error) {
+	return d.triangleText()
+}
+
+// triangleText reads the rings of a triangle, which must be empty or a
+// single closed ring of four points.
+func (d *decoder) triangleText() (geom.Triangle, error) {
+	d.skipSpace()
+	start := d.pos
+	pointss, err := d.pointssText()
+	if err != nil {
+		return nil, err
+	}
+	triangle := geom.Triangle(pointss)
+	if err := geom.ValidateTriangle(triangle); err != nil {
+		return nil, SyntaxError{start, "invalid triangle"}
+	}
+	return triangle, nil
+}
@@ -1,3 +1,20 @@
+// Package wkt encodes and decodes Well-Known Text, and PostGIS extended WKT
+// with a SRID=<srid>; prefix.
+//
+// The encoders take the axes to write: geom.TwoD, geom.Z, geom.M or
+// geom.ZM, or geom.LayoutAxes to write the axes of the geometry's own
+// geom.Layout, as returned by geom.LayoutOf. A Layout attached with
+// geom.NewLayoutGeometry is authoritative: any axes other than
+// geom.LayoutAxes must match it, or a geom.LayoutMismatchError is
+// returned. Whatever the axes, the points of the geometry must all have
+// the same number of components, or a geom.DimensionError is returned.
+// EncodeLayout needs no axes.
+//
+// Callers that pass fixed axes migrate by attaching a Layout to geometries
+// with M values and calling EncodeLayout, or passing geom.LayoutAxes to
+// EncodeEWKT, so that M values are never written as Z. Decode attaches
+// the Layout of input with a Z suffix or with M values, so decoded
+// geometries round trip through EncodeLayout.
 package wkt
 
 import (
@@ -20,3 +37,46 @@ type UnsupportedAxesError struct {
 func (e UnsupportedAxesError) Error() string {
 	return fmt.Sprintf("wkt: unsupported axes %d", e.Axes)
 }
+
+// EmptyRingError reports a polygon with both empty and non-empty rings,
+// which WKT cannot express.
+type EmptyRingError struct{}
+
+func (e EmptyRingError) Error() string {
+	return "wkt: polygon has an empty ring"
+}
+
+// SyntaxError reports malformed WKT. Offset is the byte offset in the
+// input where the problem was found.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("wkt: syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+type UnknownGeometryError struct {
+	Offset int
+	Name   string
+}
+
+func (e UnknownGeometryError) Error() string {
+	return fmt.Sprintf("wkt: unknown geometry type %q at offset %d", e.Name, e.Offset)
+}
+
+// DimensionError reports a coordinate whose number of components does not
+// match the geometry's axes. Want is 0 when no dimension was established.
+type DimensionError struct {
+	Offset int
+	Want   int
+	Got    int
+}
+
+func (e DimensionError) Error() string {
+	if e.Want == 0 {
+		return fmt.Sprintf("wkt: invalid coordinate with %d components at offset %d", e.Got, e.Offset)
+	}
+	return fmt.Sprintf("wkt: expected %d components, got %d at offset %d", e.Want, e.Got, e.Offset)
+}
@@ -73,10 +73,341 @@ func TestWKT(t *testing.T) {
 			[]byte(`POLYGONZM((1 2 3 4,5 6 7 8,9 10 11 12,1 2 3 4))`),
 			geom.ZM,
 		},
+		{
+			geom.MultiPoint{{1, 2}, {3, 4}},
+			[]byte(`MULTIPOINT((1 2),(3 4))`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiPoint{{1, 2, 3}, {}},
+			[]byte(`MULTIPOINTZ((1 2 3),EMPTY)`),
+			geom.Z,
+		},
+		{
+			geom.MultiLineString{{{1, 2}, {3, 4}}, {}},
+			[]byte(`MULTILINESTRING((1 2,3 4),EMPTY)`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiPolygon{{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}}, {}},
+			[]byte(`MULTIPOLYGON(((1 2,3 4,5 6,1 2)),EMPTY)`),
+			geom.TwoD,
+		},
+		{
+			geom.GeometryCollection{geom.Point{1, 2}, geom.GeometryCollection{geom.LineString{{1, 2}, {3, 4}}}},
+			[]byte(`GEOMETRYCOLLECTION(POINT(1 2),GEOMETRYCOLLECTION(LINESTRING(1 2,3 4)))`),
+			geom.TwoD,
+		},
+		{
+			geom.GeometryCollection{geom.Point{1, 2, 3}},
+			[]byte(`GEOMETRYCOLLECTIONM(POINTM(1 2 3))`),
+			geom.M,
+		},
+		{
+			geom.Point{},
+			[]byte(`POINT EMPTY`),
+			geom.TwoD,
+		},
+		{
+			geom.LineString{},
+			[]byte(`LINESTRINGZ EMPTY`),
+			geom.Z,
+		},
+		{
+			geom.Polygon{},
+			[]byte(`POLYGON EMPTY`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiPoint{},
+			[]byte(`MULTIPOINT EMPTY`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiLineString{},
+			[]byte(`MULTILINESTRING EMPTY`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiPolygon{},
+			[]byte(`MULTIPOLYGONZM EMPTY`),
+			geom.ZM,
+		},
+		{
+			geom.GeometryCollection{},
+			[]byte(`GEOMETRYCOLLECTION EMPTY`),
+			geom.TwoD,
+		},
+		{
+			nil,
+			[]byte(`GEOMETRYCOLLECTION EMPTY`),
+			geom.TwoD,
+		},
+		{
+			geom.Triangle{{{0, 0, 0}, {1, 0, 0}, {0, 1, 1}, {0, 0, 0}}},
+			[]byte(`TRIANGLEZ((0 0 0,1 0 0,0 1 1,0 0 0))`),
+			geom.Z,
+		},
+		{
+			geom.TIN{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}, {{{1, 0}, {1, 1}, {0, 1}, {1, 0}}}},
+			[]byte(`TIN(((0 0,1 0,0 1,0 0)),((1 0,1 1,0 1,1 0)))`),
+			geom.TwoD,
+		},
+		{
+			geom.PolyhedralSurface{{{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 0}}}, {{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}, {0, 0, 0}}}},
+			[]byte(`POLYHEDRALSURFACEZ(((0 0 0,0 1 0,1 1 0,0 0 0)),((0 0 0,1 0 0,0 0 1,0 0 0)))`),
+			geom.Z,
+		},
+		{
+			geom.TIN{},
+			[]byte(`TINM EMPTY`),
+			geom.M,
+		},
+		{
+			geom.CircularString{{0, 0}, {1, 1}, {2, 0}},
+			[]byte(`CIRCULARSTRING(0 0,1 1,2 0)`),
+			geom.TwoD,
+		},
+		{
+			geom.CompoundCurve{geom.CircularString{{0, 0, 1}, {1, 1, 1}, {2, 0, 1}}, geom.LineString{{2, 0, 1}, {0, 0, 1}}},
+			[]byte(`COMPOUNDCURVEZ(CIRCULARSTRINGZ(0 0 1,1 1 1,2 0 1),(2 0 1,0 0 1))`),
+			geom.Z,
+		},
+		{
+			geom.CurvePolygon{geom.CircularString{{-2, 0}, {2, 0}, {-2, 0}}, geom.LineString{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+			[]byte(`CURVEPOLYGON(CIRCULARSTRING(-2 0,2 0,-2 0),(0 0,1 0,0 1,0 0))`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiCurve{geom.LineString{}, geom.CompoundCurve{}},
+			[]byte(`MULTICURVE(EMPTY,COMPOUNDCURVE EMPTY)`),
+			geom.TwoD,
+		},
+		{
+			geom.MultiSurface{geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, geom.CurvePolygon{geom.CircularString{{5, 5}, {7, 5}, {5, 5}}}},
+			[]byte(`MULTISURFACE(((0 0,1 0,1 1,0 0)),CURVEPOLYGON(CIRCULARSTRING(5 5,7 5,5 5)))`),
+			geom.TwoD,
+		},
 	}
 	for _, tc := range testCases {
 		if got, err := Encode(tc.g, tc.axes); err != nil || !reflect.DeepEqual(got, tc.wkt) {
 			t.Errorf("Encode(%#v, %d) == %#v, %#v, want %#v, nil", tc.g, tc.axes, string(got), err, string(tc.wkt))
 		}
+		if tc.g == nil {
+			continue
+		}
+		// geometries with an axis suffix decode with their layout attached
+		want := tc.g
+		if tc.axes != geom.TwoD {
+			want = geom.NewLayoutGeometry(tc.g, geom.Layout(tc.axes))
+		}
+		if got, err := Decode(tc.wkt); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", tc.wkt, got, err, want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var testCases = []struct {
+		wkt []byte
+		g   geom.T
+	}{
+		{[]byte(`POINT(1 2)`), geom.Point{1, 2}},
+		{[]byte(`POINT (1 2)`), geom.Point{1, 2}},
+		{[]byte(`point ( -1.5e3 2 )`), geom.Point{-1500, 2}},
+		{[]byte(`POINT(1 2 3)`), geom.Point{1, 2, 3}},
+		{[]byte(`POINTZ(1 2 3)`), geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYZ)},
+		{[]byte(`POINT Z (1 2 3)`), geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYZ)},
+		{[]byte(`POINT M(1 2 3)`), geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM)},
+		{[]byte(`POINT ZM (1 2 3 4)`), geom.NewLayoutGeometry(geom.Point{1, 2, 3, 4}, geom.XYZM)},
+		{[]byte(`POINT(1 2 3 4)`), geom.NewLayoutGeometry(geom.Point{1, 2, 3, 4}, geom.XYZM)},
+		{[]byte(`POINT EMPTY`), geom.Point{}},
+		{[]byte(`LINESTRING (1 2, 3 4)`), geom.LineString{{1, 2}, {3, 4}}},
+		{[]byte(`LINESTRINGM(1 2 3,4 5 6)`), geom.NewLayoutGeometry(geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.XYM)},
+		{[]byte(`LINESTRING EMPTY`), geom.LineString{}},
+		{[]byte(`POLYGON((1 2,3 4,5 6,1 2))`), geom.Polygon{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}}},
+		{[]byte(`POLYGON Z EMPTY`), geom.NewLayoutGeometry(geom.Polygon{}, geom.XYZ)},
+		{[]byte(`MULTIPOINT(1 2,3 4)`), geom.MultiPoint{{1, 2}, {3, 4}}},
+		{[]byte(`MULTIPOINT ((1 2), (3 4))`), geom.MultiPoint{{1, 2}, {3, 4}}},
+		{[]byte(`MULTILINESTRING((1 2,3 4),EMPTY)`), geom.MultiLineString{{{1, 2}, {3, 4}}, {}}},
+		{[]byte(`MULTIPOLYGON(((1 2,3 4,5 6,1 2)),((7 8,9 10,11 12,7 8)))`), geom.MultiPolygon{{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}}, {{{7, 8}, {9, 10}, {11, 12}, {7, 8}}}}},
+		{[]byte(`GEOMETRYCOLLECTION(POINT(1 2),GEOMETRYCOLLECTION(LINESTRING(1 2,3 4)))`), geom.GeometryCollection{geom.Point{1, 2}, geom.GeometryCollection{geom.LineString{{1, 2}, {3, 4}}}}},
+		{[]byte(`GEOMETRYCOLLECTION Z (POINT Z (1 2 3))`), geom.NewLayoutGeometry(geom.GeometryCollection{geom.Point{1, 2, 3}}, geom.XYZ)},
+		{[]byte(`GEOMETRYCOLLECTION(POINT M (1 2 3))`), geom.NewLayoutGeometry(geom.GeometryCollection{geom.Point{1, 2, 3}}, geom.XYM)},
+		{[]byte(`GEOMETRYCOLLECTION EMPTY`), geom.GeometryCollection{}},
+		{[]byte(`TRIANGLE ((0 0,1 0,0 1,0 0))`), geom.Triangle{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}},
+		{[]byte(`TIN Z (((0 0 0,0 0 1,0 1 0,0 0 0)))`), geom.NewLayoutGeometry(geom.TIN{{{{0, 0, 0}, {0, 0, 1}, {0, 1, 0}, {0, 0, 0}}}}, geom.XYZ)},
+		{[]byte(`POLYHEDRALSURFACE EMPTY`), geom.PolyhedralSurface{}},
+		{[]byte(`CIRCULARSTRING Z EMPTY`), geom.NewLayoutGeometry(geom.CircularString{}, geom.XYZ)},
+		{[]byte(`COMPOUNDCURVE ( CIRCULARSTRING (0 0, 1 1, 2 0), LINESTRING (2 0, 0 0))`), geom.CompoundCurve{geom.CircularString{{0, 0}, {1, 1}, {2, 0}}, geom.LineString{{2, 0}, {0, 0}}}},
+		{[]byte(`CURVEPOLYGON Z (COMPOUNDCURVE Z (CIRCULARSTRING Z (0 0 0,1 1 0,2 0 0),(2 0 0,0 0 0)))`), geom.NewLayoutGeometry(geom.CurvePolygon{geom.CompoundCurve{geom.CircularString{{0, 0, 0}, {1, 1, 0}, {2, 0, 0}}, geom.LineString{{2, 0, 0}, {0, 0, 0}}}}, geom.XYZ)},
+		{[]byte(`MULTICURVE((0 0,1 1),CIRCULARSTRING(0 0,1 1,2 0))`), geom.MultiCurve{geom.LineString{{0, 0}, {1, 1}}, geom.CircularString{{0, 0}, {1, 1}, {2, 0}}}},
+		{[]byte(`MULTISURFACE(EMPTY,POLYGON((0 0,1 0,1 1,0 0)))`), geom.MultiSurface{geom.Polygon{}, geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+	}
+	for _, tc := range testCases {
+		if got, err := Decode(tc.wkt); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", tc.wkt, got, err, tc.g)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	var testCases = []struct {
+		wkt []byte
+		err error
+	}{
+		{[]byte(``), SyntaxError{0, "expected geometry type"}},
+		{[]byte(`CIRCLE(1 2)`), UnknownGeometryError{0, "CIRCLE"}},
+		{[]byte(`POINT(1 2`), SyntaxError{9, "expected ')'"}},
+		{[]byte(`POINT(1)`), DimensionError{6, 0, 1}},
+		{[]byte(`POINT Z (1 2)`), DimensionError{9, 3, 2}},
+		{[]byte(`LINESTRING(1 2,3 4 5)`), DimensionError{15, 2, 3}},
+		{[]byte(`POINT(1 x)`), SyntaxError{8, "expected number"}},
+		{[]byte(`POINT(nan inf)`), SyntaxError{6, "expected number"}},
+		{[]byte(`POINT(1e5x 2)`), SyntaxError{9, "expected number"}},
+		{[]byte(`POINT(1e 2)`), SyntaxError{6, `invalid number "1e"`}},
+		{[]byte(`POINT(1 2) POINT(3 4)`), SyntaxError{11, "unexpected data after geometry"}},
+		{[]byte(`GEOMETRYCOLLECTION Z (POINT ZM (1 2 3 4))`), DimensionError{22, 3, 4}},
+		{[]byte(`GEOMETRYCOLLECTION Z (POINT M (1 2 3))`), SyntaxError{22, "conflicting axes Z and M"}},
+		{[]byte(`POLYGON(EMPTY)`), SyntaxError{8, "empty ring"}},
+		{[]byte(`MULTIPOLYGON(((0 0,1 0,1 1,0 0),EMPTY))`), SyntaxError{32, "empty ring"}},
+		{[]byte(`COMPOUNDCURVE((0 0,1 1), POINT(1 1))`), SyntaxError{25, "invalid COMPOUNDCURVE member"}},
+		{[]byte(`MULTISURFACE(LINESTRING(0 0,1 1))`), SyntaxError{13, "invalid MULTISURFACE member"}},
+		{[]byte(`TRIANGLE((0 0,1 0,0 1))`), SyntaxError{8, "invalid triangle"}},
+		{[]byte(`TIN(((0 0,1 0,0 1,0 0)), ((0 0,1 0,0 1,1 1)))`), SyntaxError{25, "invalid triangle"}},
+	}
+	for _, tc := range testCases {
+		if got, err := Decode(tc.wkt); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Decode(%s) == %#v, %#v, want nil, %#v", tc.wkt, got, err, tc.err)
+		}
+	}
+}
+
+func TestEncodeEmptyRing(t *testing.T) {
+	var testCases = []struct {
+		g   geom.T
+		wkt string
+		err error
+	}{
+		{geom.Polygon{{}}, `POLYGON EMPTY`, nil},
+		{geom.MultiPolygon{{{}, {}}}, `MULTIPOLYGON(EMPTY)`, nil},
+		{geom.Triangle{{}}, `TRIANGLE EMPTY`, nil},
+		{geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {}}, ``, EmptyRingError{}},
+		{geom.Polygon{{}, {{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, ``, EmptyRingError{}},
+		{geom.TIN{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}, {}}}, ``, geom.TriangleError{}},
+		{geom.Triangle{{{0, 0}, {1, 0}, {0, 1}}}, ``, geom.TriangleError{}},
+		{geom.Triangle{{{0, 0}, {1, 0}, {0, 1}, {1, 1}}}, ``, geom.TriangleError{}},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g, geom.TwoD); string(got) != tc.wkt || !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Encode(%#v) == %s, %#v, want %s, %#v", tc.g, got, err, tc.wkt, tc.err)
+		}
+	}
+}
+
+func TestEWKT(t *testing.T) {
+	var testCases = []struct {
+		g    geom.T
+		ewkt []byte
+		axes int
+	}{
+		{
+			geom.NewSRIDGeometry(geom.Point{1, 2}, 4326),
+			[]byte(`SRID=4326;POINT(1 2)`),
+			geom.TwoD,
+		},
+		{
+			geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.XYM), 3857),
+			[]byte(`SRID=3857;LINESTRINGM(1 2 3,4 5 6)`),
+			geom.M,
+		},
+		{
+			geom.Point{1, 2},
+			[]byte(`POINT(1 2)`),
+			geom.TwoD,
+		},
+	}
+	for _, tc := range testCases {
+		if got, err := EncodeEWKT(tc.g, tc.axes); err != nil || !reflect.DeepEqual(got, tc.ewkt) {
+			t.Errorf("EncodeEWKT(%#v, %d) == %#v, %#v, want %#v, nil", tc.g, tc.axes, string(got), err, string(tc.ewkt))
+		}
+		if got, err := Decode(tc.ewkt); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", tc.ewkt, got, err, tc.g)
+		}
+	}
+
+	if _, err := Decode([]byte(`SRID=x;POINT(1 2)`)); !reflect.DeepEqual(err, SyntaxError{5, "invalid SRID"}) {
+		t.Errorf("Decode(SRID=x;POINT(1 2)) error == %#v, want SyntaxError", err)
+	}
+
+	collection := geom.GeometryCollection{geom.NewSRIDGeometry(geom.Point{1, 2}, 4326), geom.Point{3, 4}}
+	if got, err := EncodeEWKT(collection, geom.TwoD); err != nil || string(got) != `SRID=4326;GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))` {
+		t.Errorf("EncodeEWKT(%#v) == %s, %v", collection, got, err)
+	}
+	if got, err := Encode(collection, geom.TwoD); err != nil || string(got) != `GEOMETRYCOLLECTION(POINT(1 2),POINT(3 4))` {
+		t.Errorf("Encode(%#v) == %s, %v", collection, got, err)
+	}
+	mixed := geom.NewSRIDGeometry(collection, 3857)
+	if _, err := EncodeEWKT(mixed, geom.TwoD); !reflect.DeepEqual(err, geom.SRIDError{SRID: 3857, Other: 4326}) {
+		t.Errorf("EncodeEWKT(%#v) == %#v", mixed, err)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	var testCases = []struct {
+		g   geom.T
+		wkt string
+	}{
+		{geom.Point{1, 2}, `POINT(1 2)`},
+		{geom.LineString{{1, 2, 3}, {4, 5, 6}}, `LINESTRINGZ(1 2 3,4 5 6)`},
+		{geom.NewLayoutGeometry(geom.LineString{{1, 2, 3}, {4, 5, 6}}, geom.XYM), `LINESTRINGM(1 2 3,4 5 6)`},
+		{geom.NewSRIDGeometry(geom.NewLayoutGeometry(geom.Point{1, 2, 3, 4}, geom.XYZM), 4326), `POINTZM(1 2 3 4)`},
+		{geom.NewLayoutGeometry(geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326), geom.XYM), `POINTM(1 2 3)`},
+	}
+	for _, tc := range testCases {
+		if got, err := Encode(tc.g, geom.LayoutAxes); err != nil || string(got) != tc.wkt {
+			t.Errorf("Encode(%#v, LayoutAxes) == %s, %v, want %s, nil", tc.g, got, err, tc.wkt)
+		}
+		if got, err := EncodeLayout(tc.g); err != nil || string(got) != tc.wkt {
+			t.Errorf("EncodeLayout(%#v) == %s, %v, want %s, nil", tc.g, got, err, tc.wkt)
+		}
+	}
+
+	g := geom.NewLayoutGeometry(geom.NewSRIDGeometry(geom.Point{1, 2, 3}, 4326), geom.XYM)
+	if got, err := EncodeEWKT(g, geom.LayoutAxes); err != nil || string(got) != `SRID=4326;POINTM(1 2 3)` {
+		t.Errorf("EncodeEWKT(%#v, LayoutAxes) == %s, %v", g, got, err)
+	}
+	// An explicit axes argument must match the layout.
+	if got, err := Encode(g, geom.M); err != nil || string(got) != `POINTM(1 2 3)` {
+		t.Errorf("Encode(%#v, M) == %s, %v", g, got, err)
+	}
+	for _, axes := range []int{geom.TwoD, geom.Z, geom.ZM} {
+		want := geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.Layout(axes)}
+		if _, err := Encode(g, axes); !reflect.DeepEqual(err, want) {
+			t.Errorf("Encode(%#v, %d) == _, %#v, want %#v", g, axes, err, want)
+		}
+	}
+	collection := geom.GeometryCollection{geom.NewLayoutGeometry(geom.Point{1, 2, 3}, geom.XYM), geom.Point{4, 5, 6}}
+	if got, err := Encode(collection, geom.LayoutAxes); err != nil || string(got) != `GEOMETRYCOLLECTIONM(POINTM(1 2 3),POINTM(4 5 6))` {
+		t.Errorf("Encode(%#v, LayoutAxes) == %s, %v", collection, got, err)
+	}
+	if _, err := Encode(collection, geom.Z); !reflect.DeepEqual(err, geom.LayoutMismatchError{Layout: geom.XYM, Other: geom.XYZ}) {
+		t.Errorf("Encode(%#v, Z) == _, %#v", collection, err)
+	}
+	mixed := geom.LineString{{1, 2}, {3, 4, 5}}
+	for _, axes := range []int{geom.LayoutAxes, geom.TwoD, geom.Z} {
+		if got, err := Encode(mixed, axes); !reflect.DeepEqual(err, geom.DimensionError{Dimension: 2, ElementCount: 3}) {
+			t.Errorf("Encode(%#v, %d) == %s, %#v", mixed, axes, got, err)
+		}
+	}
+
+	// M values survive a decode and re-encode.
+	for _, wkt := range []string{`POINTM(1 2 3)`, `MULTIPOINTZM((1 2 3 4))`, `SRID=4326;LINESTRINGM(1 2 3,4 5 6)`, `POINTZ EMPTY`} {
+		g, err := Decode([]byte(wkt))
+		if err != nil {
+			t.Errorf("Decode(%s) == %v", wkt, err)
+		} else if got, err := EncodeEWKT(g, geom.LayoutAxes); err != nil || string(got) != wkt {
+			t.Errorf("EncodeEWKT(%#v, LayoutAxes) == %s, %v, want %s, nil", g, got, err, wkt)
+		}
 	}
 }
@@ -5,11 +5,22 @@ import ()
 // Create a Feature to serialize GeoJSON with additional arbitrary properties.
 // Properties may be any JSON-serializable value. Encoding a Feature to
 // another format, like WKT, will only encode the geometry, not the properties.
+// ID and BBox are optional and correspond to the GeoJSON id and bbox members.
 type Feature struct {
 	T
 	Properties interface{}
+	ID         interface{}
+	BBox       Bounds
 }
 
 func NewFeature(t T, properties interface{}) Feature {
-	return Feature{t, properties}
+	return Feature{T: t, Properties: properties}
+}
+
+// A Feature may have a null geometry, which has no extent.
+func (f Feature) Bounds(b Bounds) Bounds {
+	if f.T == nil {
+		return b
+	}
+	return f.T.Bounds(b)
 }
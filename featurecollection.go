@@ -2,9 +2,11 @@ package geom
 
 import ()
 
+// BBox is optional and corresponds to the GeoJSON bbox member.
 type FeatureCollection struct {
 	Features   []T
 	Properties interface{}
+	BBox       Bounds
 }
 
 func (f FeatureCollection) Bounds(b Bounds) Bounds {
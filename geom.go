@@ -7,6 +7,26 @@ const (
 	ZM
 )
 
+// LayoutAxes may be passed as the axes of the wkb, hex and wkt encoders to
+// encode a geometry with its own Layout, as returned by LayoutOf. Their
+// EncodeLayout functions do so without taking axes.
+const LayoutAxes = 0x100
+
 type T interface {
 	Bounds(Bounds) Bounds
 }
+
+// Unwrap returns t without any SRIDGeometry or LayoutGeometry wrapping it,
+// for encoders of formats that carry neither.
+func Unwrap(t T) T {
+	for {
+		switch g := t.(type) {
+		case SRIDGeometry:
+			t = g.T
+		case LayoutGeometry:
+			t = g.T
+		default:
+			return t
+		}
+	}
+}
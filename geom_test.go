@@ -1,6 +1,7 @@
 package geom
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -63,6 +64,54 @@ func TestBounds(t *testing.T) {
 			MultiLineString{{{1, 2, 3, 4}, {5, 6, 7, 8}}, {{9, 10, 11, 12}, {13, 14, 15, 16}}},
 			Bounds{Point{1, 2}, Point{13, 14}},
 		},
+		{
+			Triangle{{{1, 2, 3}, {4, 5, 6}, {7, 0, 9}, {1, 2, 3}}},
+			Bounds{Point{1, 0}, Point{7, 5}},
+		},
+		{
+			TIN{{{{0, 0, 0}, {1, 0, 1}, {0, 1, 1}, {0, 0, 0}}}, {{{1, 0, 1}, {2, 2, 0}, {0, 1, 1}, {1, 0, 1}}}},
+			Bounds{Point{0, 0}, Point{2, 2}},
+		},
+		{
+			PolyhedralSurface{{{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 0}}}, {{{0, 0, 0}, {3, 0, 1}, {0, 0, 1}, {0, 0, 0}}}},
+			Bounds{Point{0, 0}, Point{3, 1}},
+		},
+		{
+			CircularString{{4, 3}, {-3, 4}, {-4, -3}},
+			Bounds{Point{-5, -3}, Point{4, 5}},
+		},
+		{
+			CircularString{{4, 3}, {3, -4}, {-4, -3}},
+			Bounds{Point{-4, -5}, Point{5, 3}},
+		},
+		{
+			CircularString{{0, 0, 1}, {2, 0, 2}, {0, 0, 3}},
+			Bounds{Point{0, -1}, Point{2, 1}},
+		},
+		{
+			CircularString{{0, 0}, {1, 1}, {2, 2}},
+			Bounds{Point{0, 0}, Point{2, 2}},
+		},
+		{
+			CompoundCurve{CircularString{{0, 0}, {1, 1}, {2, 0}}, LineString{{2, 0}, {2, -3}}},
+			Bounds{Point{0, -3}, Point{2, 1}},
+		},
+		{
+			CurvePolygon{CircularString{{-2, 0}, {2, 0}, {-2, 0}}, LineString{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+			Bounds{Point{-2, -2}, Point{2, 2}},
+		},
+		{
+			MultiCurve{LineString{{5, 5}, {6, 6}}, CircularString{{0, 0}, {1, -1}, {2, 0}}},
+			Bounds{Point{0, -1}, Point{6, 6}},
+		},
+		{
+			MultiSurface{Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}, CurvePolygon{CircularString{{0, 0}, {2, 0}, {0, 0}}}},
+			Bounds{Point{0, -1}, Point{6, 6}},
+		},
+		{
+			GeometryCollection{Point{}, Point{1, 2}},
+			Bounds{Point{1, 2}, Point{1, 2}},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -77,4 +126,176 @@ func TestBoundsEmpty(t *testing.T) {
 	if got := NewBounds().Empty(); got != true {
 		t.Errorf("NewBounds.Empty() == %#v, want true", got)
 	}
+	if got := (Point{}).Bounds(NewBounds()).Empty(); got != true {
+		t.Errorf("Point{}.Bounds(NewBounds()).Empty() == %#v, want true", got)
+	}
+}
+
+func TestLinearize(t *testing.T) {
+	circularString := CircularString{{0, 0, 0}, {1, 1, 1}, {2, 0, 2}}
+	for _, tolerance := range []float64{0.1, 0.01, 1e-6} {
+		lineString := circularString.Linearize(tolerance)
+		if !lineString[0].Equal(circularString[0]) || !lineString[len(lineString)-1].Equal(circularString[2]) {
+			t.Errorf("%#v.Linearize(%v) == %#v, want same end points", circularString, tolerance, lineString)
+		}
+		for i, p := range lineString {
+			if r := math.Hypot(p[X]-1, p[Y]); math.Abs(r-1) > 1e-9 || (p[X] < 1) != (p[2] < 1) {
+				t.Errorf("%#v.Linearize(%v)[%d] == %#v, want a point on the arc", circularString, tolerance, i, p)
+			}
+			if i == 0 {
+				continue
+			}
+			q := lineString[i-1]
+			if d := 1 - math.Hypot((p[X]+q[X])/2-1, (p[Y]+q[Y])/2); d > tolerance {
+				t.Errorf("%#v.Linearize(%v) strays %v from the arc", circularString, tolerance, d)
+			}
+		}
+	}
+	if n := len(circularString.Linearize(0)); n != maxArcSegments+1 {
+		t.Errorf("%#v.Linearize(0) has %d points, want %d", circularString, n, maxArcSegments+1)
+	}
+
+	var testCases = []struct {
+		g    T
+		want T
+	}{
+		{
+			CompoundCurve{LineString{{-1, 0}, {0, 0}}, CircularString{{0, 0}, {1, 1}, {2, 0}}},
+			LineString{{-1, 0}, {0, 0}, {1, 1}, {2, 0}},
+		},
+		{
+			CurvePolygon{CompoundCurve{CircularString{{0, 0}, {1, 1}, {2, 0}}, LineString{{2, 0}, {0, 0}}}},
+			Polygon{{{0, 0}, {1, 1}, {2, 0}, {0, 0}}},
+		},
+		{
+			MultiCurve{CircularString{{0, 0}, {1, 1}, {2, 0}}, LineString{{5, 5}, {6, 6}}},
+			MultiLineString{{{0, 0}, {1, 1}, {2, 0}}, {{5, 5}, {6, 6}}},
+		},
+		{
+			MultiSurface{Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}, CurvePolygon{CircularString{{0, 0}, {1, 1}, {2, 0}, {1, -1}, {0, 0}}}},
+			MultiPolygon{{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}, {{{0, 0}, {1, 1}, {2, 0}, {1, -1}, {0, 0}}}},
+		},
+		{
+			MultiCurve{Point{1, 2}, LineString{{5, 5}, {6, 6}}},
+			MultiLineString{{{5, 5}, {6, 6}}},
+		},
+		{
+			CurvePolygon{LineString{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, Point{1, 2}},
+			Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		},
+		{
+			MultiSurface{Point{1, 2}, Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}},
+			MultiPolygon{{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}},
+		},
+		{
+			NewSRIDGeometry(GeometryCollection{Point{1, 2}, CircularString{{0, 0}, {1, 1}, {2, 0}}}, 4326),
+			NewSRIDGeometry(GeometryCollection{Point{1, 2}, LineString{{0, 0}, {1, 1}, {2, 0}}}, 4326),
+		},
+	}
+	for _, tc := range testCases {
+		if got := Linearize(tc.g, 10); !Similar(got, tc.want, 1e-9) {
+			t.Errorf("Linearize(%#v, 10) == %#v, want %#v", tc.g, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTriangle(t *testing.T) {
+	var testCases = []struct {
+		triangle Triangle
+		err      error
+	}{
+		{Triangle{}, nil},
+		{Triangle{{}}, nil},
+		{Triangle{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}, nil},
+		{Triangle{{{0, 0}, {1, 0}, {0, 0}}}, TriangleError{}},
+		{Triangle{{{0, 0}, {1, 0}, {0, 1}, {1, 1}}}, TriangleError{}},
+		{Triangle{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}, {}}, TriangleError{}},
+	}
+	for _, tc := range testCases {
+		if err := ValidateTriangle(tc.triangle); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("ValidateTriangle(%#v) == %#v, want %#v", tc.triangle, err, tc.err)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	var testCases = []struct {
+		g      T
+		layout Layout
+		err    error
+	}{
+		{Point{1, 2}, XY, nil},
+		{LineString{}, XY, nil},
+		{LineString{{1, 2, 3}, {4, 5, 6}}, XYZ, nil},
+		{MultiPolygon{{{{0, 0, 0, 0}, {1, 0, 0, 0}, {1, 1, 0, 0}, {0, 0, 0, 0}}}}, XYZM, nil},
+		{NewLayoutGeometry(LineString{{1, 2, 3}, {4, 5, 6}}, XYM), XYM, nil},
+		{NewSRIDGeometry(NewLayoutGeometry(Point{}, XYZM), 4326), XYZM, nil},
+		{GeometryCollection{Point{}, CircularString{{0, 0}, {1, 1}, {2, 0}}}, XY, nil},
+		{LineString{{1, 2}, {3, 4, 5}}, XY, DimensionError{2, 3}},
+		{Feature{T: MultiCurve{CompoundCurve{LineString{{1, 2, 3}}}, LineString{{4, 5}}}}, XYZ, DimensionError{3, 2}},
+		{NewLayoutGeometry(Point{1, 2}, XYM), XYM, DimensionError{3, 2}},
+		{Point{1}, XY, DimensionError{0, 1}},
+		{NewLayoutGeometry(Point{1, 2}, Layout(4)), Layout(4), LayoutError{Layout(4)}},
+		{GeometryCollection{NewLayoutGeometry(Point{1, 2, 3}, XYM), Point{4, 5, 6}}, XYM, nil},
+		{GeometryCollection{Point{1, 2}, NewLayoutGeometry(Point{1, 2, 3}, XYM)}, XYM, DimensionError{3, 2}},
+		{GeometryCollection{NewLayoutGeometry(Point{1, 2, 3}, XYM), NewLayoutGeometry(Point{4, 5, 6}, XYZ)}, XYM, LayoutMismatchError{XYZ, XYM}},
+	}
+	for _, tc := range testCases {
+		if got, err := LayoutOf(tc.g); got != tc.layout || !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("LayoutOf(%#v) == %v, %#v, want %v, %#v", tc.g, got, err, tc.layout, tc.err)
+		}
+		if err := ValidateLayout(tc.g); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("ValidateLayout(%#v) == %#v, want %#v", tc.g, err, tc.err)
+		}
+	}
+
+	lg := NewLayoutGeometry(Point{1, 2, 3}, XYM)
+	if err := MatchLayout(GeometryCollection{Point{1, 2}, lg}, XYM); err != nil {
+		t.Errorf("MatchLayout(%#v, XYM) == %#v, want nil", lg, err)
+	}
+	if err := MatchLayout(NewSRIDGeometry(GeometryCollection{lg}, 4326), XYZ); !reflect.DeepEqual(err, LayoutMismatchError{XYM, XYZ}) {
+		t.Errorf("MatchLayout(%#v, XYZ) == %#v, want LayoutMismatchError{XYM, XYZ}", lg, err)
+	}
+
+	if XYM.Stride() != 3 || XYM.String() != "XYM" || Layout(M) != XYM {
+		t.Errorf("XYM == %v with stride %d", XYM, XYM.Stride())
+	}
+}
+
+func TestSRIDOf(t *testing.T) {
+	var testCases = []struct {
+		g    T
+		srid uint32
+		ok   bool
+		err  error
+	}{
+		{Point{1, 2}, 0, false, nil},
+		{NewSRIDGeometry(Point{1, 2}, 4326), 4326, true, nil},
+		{GeometryCollection{Point{1, 2}, NewSRIDGeometry(Point{3, 4}, 2154)}, 2154, true, nil},
+		{NewSRIDGeometry(MultiCurve{NewSRIDGeometry(LineString{}, 4326)}, 4326), 4326, true, nil},
+		{GeometryCollection{NewSRIDGeometry(Point{1, 2}, 4326), NewSRIDGeometry(Point{3, 4}, 3857)}, 4326, true, SRIDError{4326, 3857}},
+	}
+	for _, tc := range testCases {
+		if srid, ok, err := SRIDOf(tc.g); srid != tc.srid || ok != tc.ok || !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("SRIDOf(%#v) == %d, %v, %#v, want %d, %v, %#v", tc.g, srid, ok, err, tc.srid, tc.ok, tc.err)
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	var testCases = []struct {
+		g    T
+		want T
+	}{
+		{Point{1, 2}, Point{1, 2}},
+		{NewSRIDGeometry(Point{1, 2}, 4326), Point{1, 2}},
+		{NewSRIDGeometry(NewLayoutGeometry(Point{1, 2, 3}, XYM), 4326), Point{1, 2, 3}},
+		{GeometryCollection{NewSRIDGeometry(Point{1, 2}, 4326)}, GeometryCollection{NewSRIDGeometry(Point{1, 2}, 4326)}},
+		{nil, nil},
+	}
+	for _, tc := range testCases {
+		if got := Unwrap(tc.g); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unwrap(%#v) == %#v, want %#v", tc.g, got, tc.want)
+		}
+	}
 }
@@ -0,0 +1,42 @@
+// Package planar holds the ring helpers shared by the encodings that need
+// to orient rings or assign holes to their exteriors.
+package planar
+
+import (
+	"github.com/foobaz/geom"
+)
+
+// SignedArea returns the area of ring, positive if it runs
+// counter-clockwise with Y up. The ring may be closed or not.
+func SignedArea(ring []geom.Point) float64 {
+	a := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		a += ring[i][geom.X]*ring[j][geom.Y] - ring[j][geom.X]*ring[i][geom.Y]
+	}
+	return a / 2
+}
+
+// Reversed returns a copy of ring with its points in reverse order.
+func Reversed(ring geom.Ring) geom.Ring {
+	r := make(geom.Ring, len(ring))
+	for i, p := range ring {
+		r[len(ring)-1-i] = p
+	}
+	return r
+}
+
+// RingContains returns whether p lies inside ring, by the even-odd rule.
+func RingContains(ring geom.Ring, p geom.Point) bool {
+	inside := false
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		if (a[geom.Y] > p[geom.Y]) != (b[geom.Y] > p[geom.Y]) {
+			x := a[geom.X] + (p[geom.Y]-a[geom.Y])/(b[geom.Y]-a[geom.Y])*(b[geom.X]-a[geom.X])
+			if p[geom.X] < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
@@ -0,0 +1,37 @@
+package planar
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/foobaz/geom"
+)
+
+func TestPlanar(t *testing.T) {
+	square := geom.Ring{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}
+	if got := SignedArea(square); got != 4 {
+		t.Errorf("SignedArea(%#v) == %v, want 4", square, got)
+	}
+	if got := SignedArea(square[:4]); got != 4 {
+		t.Errorf("SignedArea(%#v) == %v, want 4", square[:4], got)
+	}
+	reversed := Reversed(square)
+	if want := (geom.Ring{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}}); !reflect.DeepEqual(reversed, want) {
+		t.Errorf("Reversed(%#v) == %#v, want %#v", square, reversed, want)
+	}
+	if got := SignedArea(reversed); got != -4 {
+		t.Errorf("SignedArea(%#v) == %v, want -4", reversed, got)
+	}
+	for _, tc := range []struct {
+		p    geom.Point
+		want bool
+	}{
+		{geom.Point{1, 1}, true},
+		{geom.Point{3, 1}, false},
+		{geom.Point{-1, 1}, false},
+	} {
+		if got := RingContains(square, tc.p); got != tc.want {
+			t.Errorf("RingContains(%#v, %#v) == %v, want %v", square, tc.p, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package geom
+
+import (
+	"fmt"
+)
+
+// A Layout describes the components of every point in a geometry. Its
+// values are those of the axes constants, so a Layout converts to the axes
+// argument of an encoder and back.
+type Layout int
+
+const (
+	XY   Layout = TwoD
+	XYZ  Layout = Z
+	XYM  Layout = M
+	XYZM Layout = ZM
+)
+
+// Stride returns the number of components of a point with layout l, or 0
+// if l is not a valid Layout.
+func (l Layout) Stride() int {
+	switch l {
+	case XY:
+		return 2
+	case XYZ, XYM:
+		return 3
+	case XYZM:
+		return 4
+	}
+	return 0
+}
+
+func (l Layout) String() string {
+	switch l {
+	case XY:
+		return "XY"
+	case XYZ:
+		return "XYZ"
+	case XYM:
+		return "XYM"
+	case XYZM:
+		return "XYZM"
+	}
+	return fmt.Sprintf("Layout(%d)", int(l))
+}
+
+// LayoutError reports a Layout attached to a geometry that is not one of
+// XY, XYZ, XYM or XYZM.
+type LayoutError struct {
+	Layout Layout
+}
+
+func (e LayoutError) Error() string {
+	return "geom: invalid layout " + e.Layout.String()
+}
+
+// LayoutMismatchError reports a Layout attached to a geometry that differs
+// from Other: the Layout attached to another member of the same geometry,
+// or the axes it is being encoded with.
+type LayoutMismatchError struct {
+	Layout Layout
+	Other  Layout
+}
+
+func (e LayoutMismatchError) Error() string {
+	return "geom: layout " + e.Layout.String() + " does not match " + e.Other.String()
+}
+
+// DimensionError reports a point whose number of components does not
+// match the rest of its geometry, its Layout, or the axes it is encoded
+// with. Dimension is 0 when no Layout has ElementCount components. The
+// encoding packages report such points with this type too.
+type DimensionError struct {
+	Dimension    int
+	ElementCount int
+}
+
+func (e DimensionError) Error() string {
+	if e.Dimension == 0 {
+		return fmt.Sprintf("geom: invalid point with %d elements", e.ElementCount)
+	}
+	return fmt.Sprintf("geom: need %d elements in point, got %d", e.Dimension, e.ElementCount)
+}
+
+// LayoutOf returns the layout of t. A Layout attached with
+// NewLayoutGeometry, to t or to members of a collection, is returned as is;
+// all the attached Layouts must agree, or a LayoutMismatchError is
+// returned. Otherwise the layout is inferred from the points of t: XY for
+// two components, XYZ for three and XYZM for four, as three components are
+// taken to be a Z. Empty geometries are XY. A DimensionError is returned
+// if the points do not all match the layout.
+func LayoutOf(t T) (Layout, error) {
+	layout, stride := XY, 0
+	var err error
+	eachLayout(t, func(l Layout) bool {
+		if l.Stride() == 0 {
+			layout, err = l, LayoutError{l}
+			return false
+		}
+		if stride != 0 && l != layout {
+			err = LayoutMismatchError{l, layout}
+			return false
+		}
+		layout, stride = l, l.Stride()
+		return true
+	})
+	if err != nil {
+		return layout, err
+	}
+
+	eachPoint(t, func(point Point) bool {
+		if len(point) == 0 {
+			return true
+		}
+		if stride == 0 {
+			switch len(point) {
+			case 2:
+				layout = XY
+			case 3:
+				layout = XYZ
+			case 4:
+				layout = XYZM
+			default:
+				err = DimensionError{0, len(point)}
+				return false
+			}
+			stride = len(point)
+		}
+		if len(point) != stride {
+			err = DimensionError{stride, len(point)}
+			return false
+		}
+		return true
+	})
+	return layout, err
+}
+
+// ValidateLayout returns a DimensionError if the points of t do not all
+// have the same number of components, or do not match the Layout attached
+// to t.
+func ValidateLayout(t T) error {
+	_, err := LayoutOf(t)
+	return err
+}
+
+// MatchLayout returns a LayoutMismatchError if a Layout attached to t, or
+// to any member of it, is not layout.
+func MatchLayout(t T, layout Layout) error {
+	var err error
+	eachLayout(t, func(l Layout) bool {
+		if l != layout {
+			err = LayoutMismatchError{l, layout}
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// eachLayout calls f with each Layout attached to t or its members,
+// outermost first, until f returns false. It returns false if f did.
+func eachLayout(t T, f func(Layout) bool) bool {
+	var members []T
+	switch g := t.(type) {
+	case LayoutGeometry:
+		return f(g.Layout) && eachLayout(g.T, f)
+	case SRIDGeometry:
+		return eachLayout(g.T, f)
+	case Feature:
+		return eachLayout(g.T, f)
+	case FeatureCollection:
+		members = g.Features
+	case GeometryCollection:
+		members = g
+	case CompoundCurve:
+		members = g
+	case CurvePolygon:
+		members = g
+	case MultiCurve:
+		members = g
+	case MultiSurface:
+		members = g
+	}
+	for _, member := range members {
+		if !eachLayout(member, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// eachPoint calls f with each point of t, in order, until f returns false.
+// It returns false if f did.
+func eachPoint(t T, f func(Point) bool) bool {
+	switch g := t.(type) {
+	case Point:
+		return f(g)
+	case LineString:
+		return eachPointIn(g, f)
+	case Polygon:
+		return eachPointInRings(g, f)
+	case MultiPoint:
+		return eachPointIn(g, f)
+	case MultiLineString:
+		for _, lineString := range g {
+			if !eachPointIn(lineString, f) {
+				return false
+			}
+		}
+	case MultiPolygon:
+		for _, polygon := range g {
+			if !eachPointInRings(polygon, f) {
+				return false
+			}
+		}
+	case CircularString:
+		return eachPointIn(g, f)
+	case Triangle:
+		return eachPointInRings(Polygon(g), f)
+	case TIN:
+		for _, triangle := range g {
+			if !eachPointInRings(Polygon(triangle), f) {
+				return false
+			}
+		}
+	case PolyhedralSurface:
+		for _, polygon := range g {
+			if !eachPointInRings(polygon, f) {
+				return false
+			}
+		}
+	case GeometryCollection:
+		return eachPointInAll(g, f)
+	case CompoundCurve:
+		return eachPointInAll(g, f)
+	case CurvePolygon:
+		return eachPointInAll(g, f)
+	case MultiCurve:
+		return eachPointInAll(g, f)
+	case MultiSurface:
+		return eachPointInAll(g, f)
+	case Feature:
+		return eachPoint(g.T, f)
+	case FeatureCollection:
+		return eachPointInAll(g.Features, f)
+	case SRIDGeometry:
+		return eachPoint(g.T, f)
+	case LayoutGeometry:
+		return eachPoint(g.T, f)
+	}
+	return true
+}
+
+func eachPointIn(points []Point, f func(Point) bool) bool {
+	for _, point := range points {
+		if !f(point) {
+			return false
+		}
+	}
+	return true
+}
+
+func eachPointInRings(rings Polygon, f func(Point) bool) bool {
+	for _, ring := range rings {
+		if !eachPointIn(ring, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func eachPointInAll(geometries []T, f func(Point) bool) bool {
+	for _, t := range geometries {
+		if !eachPoint(t, f) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package geom
+
+// LayoutGeometry attaches a Layout to a geometry, so that encoders can tell
+// XYM points from XYZ points. The wkb, hex and wkt encoders accept a
+// LayoutGeometry anywhere in a geometry: with LayoutAxes they use its
+// Layout, and any other axes must match it or a LayoutMismatchError is
+// returned. The kml encoder drops the M values of XYM and XYZM geometries.
+type LayoutGeometry struct {
+	T
+	Layout Layout
+}
+
+func NewLayoutGeometry(t T, layout Layout) LayoutGeometry {
+	return LayoutGeometry{t, layout}
+}
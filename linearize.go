@@ -0,0 +1,31 @@
+package geom
+
+// Linearize returns t with every curved geometry in it replaced by its
+// linear equivalent: CircularStrings and CompoundCurves become
+// LineStrings, CurvePolygons Polygons, MultiCurves MultiLineStrings and
+// MultiSurfaces MultiPolygons. No chord strays further than tolerance from
+// the arc it replaces, and the tolerance should be positive, as for
+// CircularString.Linearize. Other geometries are returned unchanged.
+func Linearize(t T, tolerance float64) T {
+	switch g := t.(type) {
+	case CircularString:
+		return g.Linearize(tolerance)
+	case CompoundCurve:
+		return g.Linearize(tolerance)
+	case CurvePolygon:
+		return g.Linearize(tolerance)
+	case MultiCurve:
+		return g.Linearize(tolerance)
+	case MultiSurface:
+		return g.Linearize(tolerance)
+	case GeometryCollection:
+		geometries := make(GeometryCollection, len(g))
+		for i, member := range g {
+			geometries[i] = Linearize(member, tolerance)
+		}
+		return geometries
+	case SRIDGeometry:
+		return NewSRIDGeometry(Linearize(g.T, tolerance), g.SRID)
+	}
+	return t
+}
@@ -0,0 +1,26 @@
+package geom
+
+// A MultiCurve is a collection of LineStrings, CircularStrings and
+// CompoundCurves.
+type MultiCurve []T
+
+func (multiCurve MultiCurve) Bounds(b Bounds) Bounds {
+	for _, t := range multiCurve {
+		b = t.Bounds(b)
+	}
+
+	return b
+}
+
+// Linearize returns a MultiLineString with each member of multiCurve
+// linearized. Members other than LineStrings, CircularStrings and
+// CompoundCurves are skipped.
+func (multiCurve MultiCurve) Linearize(tolerance float64) MultiLineString {
+	multiLineString := make(MultiLineString, 0, len(multiCurve))
+	for _, t := range multiCurve {
+		if lineString, ok := linearizeCurve(t, tolerance); ok {
+			multiLineString = append(multiLineString, lineString)
+		}
+	}
+	return multiLineString
+}
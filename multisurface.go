@@ -0,0 +1,27 @@
+package geom
+
+// A MultiSurface is a collection of Polygons and CurvePolygons.
+type MultiSurface []T
+
+func (multiSurface MultiSurface) Bounds(b Bounds) Bounds {
+	for _, t := range multiSurface {
+		b = t.Bounds(b)
+	}
+
+	return b
+}
+
+// Linearize returns a MultiPolygon with each CurvePolygon in multiSurface
+// linearized. Members other than Polygons and CurvePolygons are skipped.
+func (multiSurface MultiSurface) Linearize(tolerance float64) MultiPolygon {
+	multiPolygon := make(MultiPolygon, 0, len(multiSurface))
+	for _, t := range multiSurface {
+		switch g := t.(type) {
+		case Polygon:
+			multiPolygon = append(multiPolygon, g)
+		case CurvePolygon:
+			multiPolygon = append(multiPolygon, g.Linearize(tolerance))
+		}
+	}
+	return multiPolygon
+}
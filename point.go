@@ -21,7 +21,12 @@ func NewNPoint(s ...float64) Point {
 	return Point(s)
 }
 
+// Bounds returns b extended by point. An empty point leaves b as it is, so
+// that the bounds of an empty geometry are empty.
 func (point Point) Bounds(b Bounds) Bounds {
+	if len(point) == 0 {
+		return b
+	}
 	return b.ExtendPoint(point)
 }
 
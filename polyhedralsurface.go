@@ -0,0 +1,13 @@
+package geom
+
+// A PolyhedralSurface is a surface of Polygons that meet at their edges,
+// such as the shell of a building.
+type PolyhedralSurface []Polygon
+
+func (polyhedralSurface PolyhedralSurface) Bounds(b Bounds) Bounds {
+	for _, polygon := range polyhedralSurface {
+		b = polygon.Bounds(b)
+	}
+
+	return b
+}
@@ -51,6 +51,20 @@ func pointssSimilar(p1ss, p2ss Polygon, e float64) bool {
 	return true
 }
 
+func geometriesSimilar(t1s, t2s []T, e float64) bool {
+	if len(t1s) != len(t2s) {
+		return false
+	}
+
+	for i := range t1s {
+		if !Similar(t1s[i], t2s[i], e) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func Similar(t1, t2 T, e float64) bool {
 	if reflect.TypeOf(t1) != reflect.TypeOf(t2) {
 		return false
@@ -64,6 +78,79 @@ func Similar(t1, t2 T, e float64) bool {
 		return pointssSimilar(t1.(Polygon), t2.(Polygon), e)
 	case MultiPoint:
 		return pointsSimilar(t1.(MultiPoint), t2.(MultiPoint), e)
+	case MultiLineString:
+		m1, m2 := t1.(MultiLineString), t2.(MultiLineString)
+		if len(m1) != len(m2) {
+			return false
+		}
+		for i := range m1 {
+			if !pointsSimilar(m1[i], m2[i], e) {
+				return false
+			}
+		}
+		return true
+	case MultiPolygon:
+		m1, m2 := t1.(MultiPolygon), t2.(MultiPolygon)
+		if len(m1) != len(m2) {
+			return false
+		}
+		for i := range m1 {
+			if !pointssSimilar(m1[i], m2[i], e) {
+				return false
+			}
+		}
+		return true
+	case Triangle:
+		return pointssSimilar(Polygon(t1.(Triangle)), Polygon(t2.(Triangle)), e)
+	case TIN:
+		m1, m2 := t1.(TIN), t2.(TIN)
+		if len(m1) != len(m2) {
+			return false
+		}
+		for i := range m1 {
+			if !pointssSimilar(Polygon(m1[i]), Polygon(m2[i]), e) {
+				return false
+			}
+		}
+		return true
+	case PolyhedralSurface:
+		m1, m2 := t1.(PolyhedralSurface), t2.(PolyhedralSurface)
+		if len(m1) != len(m2) {
+			return false
+		}
+		for i := range m1 {
+			if !pointssSimilar(m1[i], m2[i], e) {
+				return false
+			}
+		}
+		return true
+	case CircularString:
+		return pointsSimilar(t1.(CircularString), t2.(CircularString), e)
+	case CompoundCurve:
+		return geometriesSimilar(t1.(CompoundCurve), t2.(CompoundCurve), e)
+	case CurvePolygon:
+		return geometriesSimilar(t1.(CurvePolygon), t2.(CurvePolygon), e)
+	case MultiCurve:
+		return geometriesSimilar(t1.(MultiCurve), t2.(MultiCurve), e)
+	case MultiSurface:
+		return geometriesSimilar(t1.(MultiSurface), t2.(MultiSurface), e)
+	case SRIDGeometry:
+		s1, s2 := t1.(SRIDGeometry), t2.(SRIDGeometry)
+		return s1.SRID == s2.SRID && Similar(s1.T, s2.T, e)
+	case LayoutGeometry:
+		l1, l2 := t1.(LayoutGeometry), t2.(LayoutGeometry)
+		return l1.Layout == l2.Layout && Similar(l1.T, l2.T, e)
+	case GeometryCollection:
+		c1, c2 := t1.(GeometryCollection), t2.(GeometryCollection)
+		if len(c1) != len(c2) {
+			return false
+		}
+		for i := range c1 {
+			if !Similar(c1[i], c2[i], e) {
+				return false
+			}
+		}
+		return true
 	default:
 		return false
 	}
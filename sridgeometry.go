@@ -0,0 +1,70 @@
+package geom
+
+import (
+	"fmt"
+)
+
+// SRIDGeometry attaches a spatial reference system identifier to a geometry,
+// as carried by PostGIS extended WKB and WKT. Encoding it to a format
+// without SRIDs will only encode the geometry.
+type SRIDGeometry struct {
+	T
+	SRID uint32
+}
+
+func NewSRIDGeometry(t T, srid uint32) SRIDGeometry {
+	return SRIDGeometry{t, srid}
+}
+
+// SRIDError reports a geometry whose members carry different SRIDs.
+type SRIDError struct {
+	SRID  uint32
+	Other uint32
+}
+
+func (e SRIDError) Error() string {
+	return fmt.Sprintf("geom: mixed SRIDs %d and %d", e.SRID, e.Other)
+}
+
+// SRIDOf returns the SRID of t and whether it has one. The SRID may be
+// attached to t itself or to members of a GeometryCollection, CompoundCurve,
+// CurvePolygon, MultiCurve or MultiSurface, in which case they must all
+// agree. An SRIDError is returned if they do not.
+func SRIDOf(t T) (uint32, bool, error) {
+	var srid uint32
+	found := false
+	var err error
+	var walk func(T) bool
+	walk = func(t T) bool {
+		var members []T
+		switch g := t.(type) {
+		case SRIDGeometry:
+			if found && g.SRID != srid {
+				err = SRIDError{srid, g.SRID}
+				return false
+			}
+			srid, found = g.SRID, true
+			return walk(g.T)
+		case LayoutGeometry:
+			return walk(g.T)
+		case GeometryCollection:
+			members = g
+		case CompoundCurve:
+			members = g
+		case CurvePolygon:
+			members = g
+		case MultiCurve:
+			members = g
+		case MultiSurface:
+			members = g
+		}
+		for _, member := range members {
+			if !walk(member) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(t)
+	return srid, found, err
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/foobaz/geom"
 	"github.com/foobaz/geom/encoding/hex"
 	"github.com/foobaz/geom/encoding/wkb"
+	"github.com/foobaz/geom/encoding/wkt"
 )
 
 func TestHexEncode(t *testing.T) {
@@ -40,3 +41,11 @@ func TestWKBEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestWKTDecode(t *testing.T) {
+	for _, c := range cases {
+		if got, err := wkt.Decode([]byte(c.wkt)); err != nil || !geom.Similar(got, c.g, 1e-9) {
+			t.Errorf("wkt.Decode(%#v) == %#v, %#v, want %#v, nil", c.wkt, got, err, c.g)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package geom
+
+// A TIN is a triangulated irregular network, a surface of Triangles that
+// meet at their edges, such as a terrain model.
+type TIN []Triangle
+
+func (tin TIN) Bounds(b Bounds) Bounds {
+	for _, triangle := range tin {
+		b = triangle.Bounds(b)
+	}
+
+	return b
+}
@@ -0,0 +1,30 @@
+package geom
+
+// A Triangle is a Polygon with a single ring of four points, the last the
+// same as the first.
+type Triangle Polygon
+
+func (triangle Triangle) Bounds(b Bounds) Bounds {
+	return b.ExtendPointss(Polygon(triangle))
+}
+
+// TriangleError reports a Triangle that is neither empty nor a single ring
+// of four points, the last the same as the first.
+type TriangleError struct{}
+
+func (e TriangleError) Error() string {
+	return "geom: triangle must be a closed ring of four points"
+}
+
+// ValidateTriangle returns a TriangleError if triangle is neither empty nor
+// a single closed ring of four points. A triangle whose only ring has no
+// points is empty.
+func ValidateTriangle(triangle Triangle) error {
+	if len(triangle) == 0 || len(triangle) == 1 && len(triangle[0]) == 0 {
+		return nil
+	}
+	if len(triangle) != 1 || len(triangle[0]) != 4 || !triangle[0][0].Equal(triangle[0][3]) {
+		return TriangleError{}
+	}
+	return nil
+}